@@ -3,17 +3,25 @@ package main
 import (
 	"celestial/internal/config"
 	"celestial/internal/gm"
+	"celestial/internal/input"
 	"celestial/internal/mission"
 	"celestial/internal/network"
+	"celestial/internal/recording"
 	"celestial/internal/simulation"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 )
 
 func main() {
+	resume := flag.Bool("resume", false, "Resume from the latest persisted snapshot")
+	flag.Parse()
+
 	log.Println("Celestial Bridge Simulator - Starting")
 
 	cfg, err := config.LoadConfig("configs/server.yaml")
@@ -21,6 +29,14 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	if args := flag.Args(); len(args) > 0 && args[0] == "replay" {
+		if len(args) < 2 {
+			log.Fatalf("Usage: celestial replay <recording>")
+		}
+		runReplay(cfg, args[1])
+		return
+	}
+
 	shipClasses, err := config.LoadShipClasses("configs/ships")
 	if err != nil {
 		log.Fatalf("Failed to load ship classes: %v", err)
@@ -32,19 +48,71 @@ func main() {
 	}
 
 	sim := simulation.NewSimulator(cfg.TickRate, shipClasses)
-	go sim.Start()
+	if cfg.MaxCatchUpTicks > 0 {
+		sim.SetMaxCatchUpTicks(cfg.MaxCatchUpTicks)
+	}
+
+	var snapshotStore *simulation.SnapshotStore
+	if cfg.SnapshotDir != "" {
+		snapshotStore, err = simulation.NewSnapshotStore(cfg.SnapshotDir)
+		if err != nil {
+			log.Fatalf("Failed to open snapshot store: %v", err)
+		}
+		sim.SetSnapshotStore(snapshotStore)
+	}
+	sim.SetSnapshotRetention(simulation.SnapshotRetention{
+		MaxCount: cfg.SnapshotKeep,
+		MaxAge:   float64(cfg.SnapshotMaxAge),
+	})
 
 	missionEngine := mission.NewEngine(sim)
 	if err := missionEngine.LoadMissions("missions"); err != nil {
 		log.Fatalf("Failed to load missions: %v", err)
 	}
+	sim.SetMissionStateProvider(missionEngine)
+
+	if *resume {
+		if snapshotStore == nil {
+			log.Fatalf("Cannot resume: snapshot_dir is not configured")
+		}
+		snapshot, err := snapshotStore.Latest()
+		if err != nil {
+			log.Fatalf("Failed to load latest snapshot: %v", err)
+		}
+		if err := sim.RestoreFromSnapshot(snapshot); err != nil {
+			log.Fatalf("Failed to resume session: %v", err)
+		}
+		log.Printf("Resumed session at time %.2f", snapshot.Time)
+	}
 
-	gmController := gm.NewController(sim, missionEngine)
+	var recorder *recording.Recorder
+	if cfg.RecordingDir != "" {
+		recorder, err = openRecorder(cfg)
+		if err != nil {
+			log.Fatalf("Failed to start recording: %v", err)
+		}
+		recorder.Attach(sim)
+	}
+
+	go sim.Start()
+
+	gmController := gm.NewController(sim, missionEngine, time.Duration(cfg.SnapshotInterval)*time.Second)
+
+	actionRouter := input.NewActionRouter(sim)
+	if recorder != nil {
+		actionRouter.AddObserver(func(action *input.Action) {
+			recorder.RecordAction(sim.GetTime(), action.Role, action.System, action.Action, action.Value)
+		})
+	}
 
 	wsServer := network.NewWebSocketServer(cfg.WebSocketPort, sim, gmController)
+	wsServer.SetActionRouter(actionRouter)
+	if recorder != nil {
+		wsServer.SetRecorder(recorder)
+	}
 	go wsServer.Start()
 
-	tcpServer := network.NewTCPServer(cfg.TCPPort, sim, panelMappings)
+	tcpServer := network.NewTCPServer(cfg.TCPPort, sim, panelMappings, actionRouter)
 	go tcpServer.Start()
 
 	log.Printf("WebSocket server listening on :%d", cfg.WebSocketPort)
@@ -59,6 +127,40 @@ func main() {
 	sim.Stop()
 	wsServer.Stop()
 	tcpServer.Stop()
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			log.Printf("Failed to close recording: %v", err)
+		}
+	}
 	time.Sleep(100 * time.Millisecond)
 	log.Println("Shutdown complete")
 }
+
+func openRecorder(cfg *config.ServerConfig) (*recording.Recorder, error) {
+	if err := os.MkdirAll(cfg.RecordingDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating recording directory: %w", err)
+	}
+
+	name := fmt.Sprintf("session_%s.rec.gz", time.Now().Format("20060102-150405"))
+	return recording.NewRecorder(filepath.Join(cfg.RecordingDir, name), cfg.TickRate, float64(cfg.RecordingKeyframeInterval))
+}
+
+func runReplay(cfg *config.ServerConfig, path string) {
+	player, err := recording.OpenPlayer(path)
+	if err != nil {
+		log.Fatalf("Failed to load recording: %v", err)
+	}
+
+	log.Printf("Replaying %s (%.1fs to %.1fs)", path, player.Start(), player.Duration())
+
+	wsServer := network.NewReplayServer(cfg.WebSocketPort, player)
+	go wsServer.Start()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	log.Println("Shutting down...")
+	wsServer.Stop()
+	log.Println("Shutdown complete")
+}
@@ -1,7 +1,9 @@
 package ai
 
 import (
+	"celestial/internal/events"
 	"celestial/internal/ship"
+	"fmt"
 	"log"
 	"math"
 	"math/rand"
@@ -15,6 +17,13 @@ type Controller struct {
 	TacticalMode    string
 }
 
+// World is the view of the simulation available to AI controllers.
+type World interface {
+	Ship(id string) *ship.Ship
+	NearestShip(center ship.Vector3, maxRange float64, filter func(sh *ship.Ship) bool) *ship.Ship
+	Publish(eventType string, data map[string]interface{})
+}
+
 func NewController() *Controller {
 	return &Controller{
 		State:           "patrol",
@@ -24,43 +33,52 @@ func NewController() *Controller {
 	}
 }
 
-func (c *Controller) Update(dt float64, sh *ship.Ship, allShips map[string]*ship.Ship) {
+func (c *Controller) Clone() *Controller {
+	clone := *c
+	return &clone
+}
+
+func (c *Controller) Update(dt float64, sh *ship.Ship, world World) {
 	switch c.State {
 	case "patrol":
-		c.updatePatrol(dt, sh, allShips)
+		c.updatePatrol(dt, sh, world)
 	case "combat":
-		c.updateCombat(dt, sh, allShips)
+		c.updateCombat(dt, sh, world)
 	case "evade":
-		c.updateEvade(dt, sh, allShips)
+		c.updateEvade(dt, sh, world)
 	case "retreat":
-		c.updateRetreat(dt, sh, allShips)
+		c.updateRetreat(dt, sh, world)
 	}
 
-	c.evaluateState(sh, allShips)
+	c.evaluateState(sh, world)
 }
 
-func (c *Controller) updatePatrol(dt float64, sh *ship.Ship, allShips map[string]*ship.Ship) {
-	sh.ApplyThrust(0, 0, sh.MaxSpeed*0.3)
+func (c *Controller) updatePatrol(dt float64, sh *ship.Ship, world World) {
+	sh.SetThrottle(0.3)
 
 	yawRate := 0.1 * c.Difficulty
 	sh.ApplyRotation(0, yawRate, 0)
 
-	threat := c.findNearestThreat(sh, allShips)
+	sensorRange := sh.DetectionRange()
+	if sensorRange <= 0 {
+		return
+	}
+	threat := c.findNearestThreat(sh, world, sensorRange)
 	if threat != nil {
 		dist := distance(sh.Position, threat.Position)
-		if dist < 5000.0 {
-			c.State = "combat"
+		if dist < sensorRange {
 			c.TargetID = threat.ID
+			c.setState(sh, world, "combat")
 			log.Printf("AI ship %s entering combat with %s", sh.ID, threat.ID)
 		}
 	}
 }
 
-func (c *Controller) updateCombat(dt float64, sh *ship.Ship, allShips map[string]*ship.Ship) {
-	target := allShips[c.TargetID]
+func (c *Controller) updateCombat(dt float64, sh *ship.Ship, world World) {
+	target := world.Ship(c.TargetID)
 	if target == nil {
-		c.State = "patrol"
 		c.TargetID = ""
+		c.setState(sh, world, "patrol")
 		return
 	}
 
@@ -83,11 +101,11 @@ func (c *Controller) updateCombat(dt float64, sh *ship.Ship, allShips map[string
 
 	optimalRange := 1000.0
 	if dist > optimalRange*1.5 {
-		sh.ApplyThrust(0, 0, sh.MaxSpeed*0.8)
+		sh.SetThrottle(0.8)
 	} else if dist < optimalRange*0.5 {
-		sh.ApplyThrust(0, 0, -sh.MaxSpeed*0.5)
+		sh.SetThrottle(-0.5)
 	} else {
-		sh.ApplyThrust(0, 0, sh.MaxSpeed*0.3)
+		sh.SetThrottle(0.3)
 	}
 
 	if dot > 0.95 && dist < 2000.0 {
@@ -99,11 +117,11 @@ func (c *Controller) updateCombat(dt float64, sh *ship.Ship, allShips map[string
 	}
 }
 
-func (c *Controller) updateEvade(dt float64, sh *ship.Ship, allShips map[string]*ship.Ship) {
-	target := allShips[c.TargetID]
+func (c *Controller) updateEvade(dt float64, sh *ship.Ship, world World) {
+	target := world.Ship(c.TargetID)
 	if target == nil {
-		c.State = "patrol"
 		c.TargetID = ""
+		c.setState(sh, world, "patrol")
 		return
 	}
 
@@ -114,38 +132,51 @@ func (c *Controller) updateEvade(dt float64, sh *ship.Ship, allShips map[string]
 	}
 	away = normalize(away)
 
-	sh.ApplyThrust(0, 0, sh.MaxSpeed)
+	sh.SetThrottle(1)
 	sh.ApplyRotation(away.Y*0.5, away.X*0.5, rand.Float64()*0.2-0.1)
 
 	if rand.Float64() < 0.3 {
-		c.State = "combat"
+		c.setState(sh, world, "combat")
 	}
 }
 
-func (c *Controller) updateRetreat(dt float64, sh *ship.Ship, allShips map[string]*ship.Ship) {
-	sh.ApplyThrust(0, 0, sh.MaxSpeed)
+func (c *Controller) updateRetreat(dt float64, sh *ship.Ship, world World) {
+	sh.SetThrottle(1)
 
 	dist := 10000.0
 	if c.TargetID != "" {
-		if target := allShips[c.TargetID]; target != nil {
+		if target := world.Ship(c.TargetID); target != nil {
 			dist = distance(sh.Position, target.Position)
 		}
 	}
 
 	if dist > 8000.0 {
-		c.State = "patrol"
 		c.TargetID = ""
+		c.setState(sh, world, "patrol")
 		log.Printf("AI ship %s ending retreat", sh.ID)
 	}
 }
 
-func (c *Controller) evaluateState(sh *ship.Ship, allShips map[string]*ship.Ship) {
+// setState moves the controller to a new state and publishes the change.
+func (c *Controller) setState(sh *ship.Ship, world World, state string) {
+	if c.State == state {
+		return
+	}
+	c.State = state
+	world.Publish(events.AIStateChanged, map[string]interface{}{
+		"ship_id":   sh.ID,
+		"state":     state,
+		"target_id": c.TargetID,
+	})
+}
+
+func (c *Controller) evaluateState(sh *ship.Ship, world World) {
 	hullHealth := c.calculateHullHealth(sh)
 	shieldHealth := c.calculateShieldHealth(sh)
 
 	if hullHealth < 0.3 || shieldHealth < 0.2 {
 		if c.State != "retreat" {
-			c.State = "retreat"
+			c.setState(sh, world, "retreat")
 			log.Printf("AI ship %s retreating (hull: %.1f%%, shields: %.1f%%)", sh.ID, hullHealth*100, shieldHealth*100)
 		}
 		return
@@ -153,7 +184,7 @@ func (c *Controller) evaluateState(sh *ship.Ship, allShips map[string]*ship.Ship
 
 	if hullHealth < 0.6 && shieldHealth < 0.5 {
 		if c.State == "combat" && rand.Float64() < 0.3 {
-			c.State = "evade"
+			c.setState(sh, world, "evade")
 			log.Printf("AI ship %s evading", sh.ID)
 		}
 	}
@@ -163,7 +194,11 @@ func (c *Controller) attemptFire(sh *ship.Ship, target *ship.Ship) {
 	for id, weapon := range sh.Weapons {
 		if weapon.Type == "phaser" && weapon.Health > 0 && weapon.Cooldown <= 0 {
 			if sh.FireWeapon(id, target.ID) {
-				target.TakeDamage(weapon.Damage*c.Difficulty, "forward")
+				target.TakeHit(ship.Hit{
+					Amount:    weapon.Damage * c.Difficulty,
+					Point:     sh.Position,
+					Frequency: weapon.Frequency,
+				})
 				log.Printf("AI ship %s fired %s at %s for %.1f damage", sh.ID, id, target.ID, weapon.Damage*c.Difficulty)
 				return
 			}
@@ -185,27 +220,10 @@ func (c *Controller) attemptMissilefire(sh *ship.Ship, target *ship.Ship) {
 	}
 }
 
-func (c *Controller) findNearestThreat(sh *ship.Ship, allShips map[string]*ship.Ship) *ship.Ship {
-	var nearest *ship.Ship
-	minDist := math.MaxFloat64
-
-	for _, other := range allShips {
-		if other.ID == sh.ID {
-			continue
-		}
-
-		if sh.IsPlayer == other.IsPlayer {
-			continue
-		}
-
-		dist := distance(sh.Position, other.Position)
-		if dist < minDist {
-			minDist = dist
-			nearest = other
-		}
-	}
-
-	return nearest
+func (c *Controller) findNearestThreat(sh *ship.Ship, world World, maxRange float64) *ship.Ship {
+	return world.NearestShip(sh.Position, maxRange, func(other *ship.Ship) bool {
+		return other.ID != sh.ID && sh.IsPlayer != other.IsPlayer
+	})
 }
 
 func (c *Controller) calculateHullHealth(sh *ship.Ship) float64 {
@@ -234,6 +252,26 @@ func (c *Controller) calculateShieldHealth(sh *ship.Ship) float64 {
 	return total / max
 }
 
+// SetOrders puts the controller in a state, engaging or fleeing targetID.
+// The controller still retreats on its own when badly damaged.
+func (c *Controller) SetOrders(state, targetID string) error {
+	switch state {
+	case "patrol":
+		targetID = ""
+	case "combat", "evade":
+		if targetID == "" {
+			return fmt.Errorf("%s orders need a target", state)
+		}
+	case "retreat":
+	default:
+		return fmt.Errorf("unknown AI state: %s", state)
+	}
+	c.State = state
+	c.TargetID = targetID
+	log.Printf("AI ordered to %s %s", state, targetID)
+	return nil
+}
+
 func (c *Controller) SetDifficulty(diff float64) {
 	c.Difficulty = diff
 	log.Printf("AI difficulty set to %.2f", diff)
@@ -262,7 +300,7 @@ func distance(a, b ship.Vector3) float64 {
 func normalize(v ship.Vector3) ship.Vector3 {
 	mag := math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
 	if mag < 0.0001 {
-		return ship.Vector3{0, 0, 1}
+		return ship.Vector3{X: 0, Y: 0, Z: 1}
 	}
 	return ship.Vector3{
 		X: v.X / mag,
@@ -9,10 +9,16 @@ import (
 )
 
 type ServerConfig struct {
-	TickRate         int `yaml:"tick_rate"`
-	WebSocketPort    int `yaml:"websocket_port"`
-	TCPPort          int `yaml:"tcp_port"`
-	SnapshotInterval int `yaml:"snapshot_interval"`
+	TickRate                  int    `yaml:"tick_rate"`
+	MaxCatchUpTicks           int    `yaml:"max_catch_up_ticks"`
+	WebSocketPort             int    `yaml:"websocket_port"`
+	TCPPort                   int    `yaml:"tcp_port"`
+	SnapshotInterval          int    `yaml:"snapshot_interval"`
+	SnapshotDir               string `yaml:"snapshot_dir"`
+	SnapshotKeep              int    `yaml:"snapshot_keep"`
+	SnapshotMaxAge            int    `yaml:"snapshot_max_age"`
+	RecordingDir              string `yaml:"recording_dir"`
+	RecordingKeyframeInterval int    `yaml:"recording_keyframe_interval"`
 }
 
 func LoadConfig(path string) (*ServerConfig, error) {
@@ -30,18 +36,31 @@ func LoadConfig(path string) (*ServerConfig, error) {
 }
 
 type ShipClass struct {
-	ID           string            `yaml:"id"`
-	Name         string            `yaml:"name"`
-	Mass         float64           `yaml:"mass"`
-	MaxSpeed     float64           `yaml:"max_speed"`
-	Acceleration float64           `yaml:"acceleration"`
-	TurnRate     float64           `yaml:"turn_rate"`
-	Engines      []EngineConfig    `yaml:"engines"`
-	Weapons      []WeaponConfig    `yaml:"weapons"`
-	Shields      ShieldConfig      `yaml:"shields"`
-	Hull         HullConfig        `yaml:"hull"`
-	Subsystems   []SubsystemConfig `yaml:"subsystems"`
-	LaunchBays   []LaunchBayConfig `yaml:"launch_bays"`
+	ID           string              `yaml:"id"`
+	Name         string              `yaml:"name"`
+	Faction      string              `yaml:"faction"`
+	Mass         float64             `yaml:"mass"`
+	MaxSpeed     float64             `yaml:"max_speed"`
+	Acceleration float64             `yaml:"acceleration"`
+	TurnRate     float64             `yaml:"turn_rate"`
+	Radius       float64             `yaml:"radius"`
+	SensorRange  float64             `yaml:"sensor_range"`
+	Engines      []EngineConfig      `yaml:"engines"`
+	Weapons      []WeaponConfig      `yaml:"weapons"`
+	Shields      ShieldConfig        `yaml:"shields"`
+	Hull         HullConfig          `yaml:"hull"`
+	Subsystems   []SubsystemConfig   `yaml:"subsystems"`
+	LaunchBays   []LaunchBayConfig   `yaml:"launch_bays"`
+	DockingPorts []DockingPortConfig `yaml:"docking_ports"`
+	Power        PowerConfig         `yaml:"power"`
+	Coolant      int                 `yaml:"coolant"`
+	Compartments []CompartmentConfig `yaml:"compartments"`
+	Doors        []DoorConfig        `yaml:"doors"`
+	Stations     map[string]string   `yaml:"stations"`
+	Suppression  SuppressionConfig   `yaml:"suppression"`
+	DamageTeams  int                 `yaml:"damage_teams"`
+	Crew         map[string]int      `yaml:"crew"`
+	Destruction  DestructionConfig   `yaml:"destruction"`
 }
 
 type EngineConfig struct {
@@ -56,11 +75,23 @@ type WeaponConfig struct {
 	ID           string  `yaml:"id"`
 	Type         string  `yaml:"type"`
 	Damage       float64 `yaml:"damage"`
+	Frequency    float64 `yaml:"frequency"`
 	Range        float64 `yaml:"range"`
 	CooldownTime float64 `yaml:"cooldown_time"`
 	Health       float64 `yaml:"health"`
 	PowerDraw    float64 `yaml:"power_draw"`
 	AmmoCapacity int     `yaml:"ammo_capacity"`
+
+	Facing          string  `yaml:"facing"`
+	Speed           float64 `yaml:"speed"`
+	TurnRate        float64 `yaml:"turn_rate"`
+	Fuel            float64 `yaml:"fuel"`
+	ArmingDistance  float64 `yaml:"arming_distance"`
+	ProximityRadius float64 `yaml:"proximity_radius"`
+	NavConstant     float64 `yaml:"nav_constant"`
+	Hitpoints       float64 `yaml:"hitpoints"`
+	Effectiveness   float64 `yaml:"effectiveness"`
+	Duration        float64 `yaml:"duration"`
 }
 
 type ShieldConfig struct {
@@ -99,6 +130,119 @@ type LaunchBayConfig struct {
 	Health   float64 `yaml:"health"`
 }
 
+type PowerConfig struct {
+	ReactorOutput float64         `yaml:"reactor_output"`
+	Batteries     []BatteryConfig `yaml:"batteries"`
+	Breakers      []BreakerConfig `yaml:"breakers"`
+}
+
+type BatteryConfig struct {
+	ID           string  `yaml:"id"`
+	Capacity     float64 `yaml:"capacity"`
+	MaxCharge    float64 `yaml:"max_charge"`
+	MaxDischarge float64 `yaml:"max_discharge"`
+}
+
+type BreakerConfig struct {
+	ID      string   `yaml:"id"`
+	Rating  float64  `yaml:"rating"`
+	Systems []string `yaml:"systems"`
+}
+
+type DockingPortConfig struct {
+	ID                 string  `yaml:"id"`
+	Facing             string  `yaml:"facing"`
+	ApproachLength     float64 `yaml:"approach_length"`
+	MaxApproachSpeed   float64 `yaml:"max_approach_speed"`
+	AlignmentTolerance float64 `yaml:"alignment_tolerance"`
+}
+
+// CompartmentConfig is a pressurised space behind a hull section. Adjacent
+// compartments share a bulkhead; doors are declared separately.
+type CompartmentConfig struct {
+	ID       string   `yaml:"id"`
+	Section  string   `yaml:"section"`
+	Volume   float64  `yaml:"volume"`
+	Fuel     float64  `yaml:"fuel"`
+	Adjacent []string `yaml:"adjacent"`
+}
+
+// DoorConfig is a door in the bulkhead between two compartments. Doors start
+// open unless Closed is set.
+type DoorConfig struct {
+	ID       string   `yaml:"id"`
+	Connects []string `yaml:"connects"`
+	Closed   bool     `yaml:"closed"`
+}
+
+// SuppressionConfig is the number of charges of each fire suppressant.
+type SuppressionConfig struct {
+	Halon int `yaml:"halon"`
+	Foam  int `yaml:"foam"`
+}
+
+// DestructionConfig decides when a ship is lost. A ship is destroyed when any
+// of its core sections is, when its total hull falls to destroyed_hull of
+// full or when a reactor subsystem is wrecked, and disabled below
+// disabled_hull. Fractions left at zero take the defaults.
+type DestructionConfig struct {
+	CoreSections    []string `yaml:"core_sections"`
+	DestroyedHull   float64  `yaml:"destroyed_hull"`
+	DisabledHull    float64  `yaml:"disabled_hull"`
+	ExplosionDamage float64  `yaml:"explosion_damage"`
+	ExplosionRadius float64  `yaml:"explosion_radius"`
+}
+
+// validateLayout checks that compartments sit behind known hull sections and
+// that adjacency, doors and crew stations only name declared compartments.
+func (c *ShipClass) validateLayout() error {
+	sections := make(map[string]bool, len(c.Hull.Sections))
+	for _, sec := range c.Hull.Sections {
+		sections[sec.ID] = true
+	}
+	compartments := make(map[string]bool, len(c.Compartments))
+	for _, comp := range c.Compartments {
+		compartments[comp.ID] = true
+	}
+
+	for _, comp := range c.Compartments {
+		if !sections[comp.Section] {
+			return fmt.Errorf("compartment %s: unknown hull section %q", comp.ID, comp.Section)
+		}
+		for _, adj := range comp.Adjacent {
+			if !compartments[adj] {
+				return fmt.Errorf("compartment %s: unknown adjacent compartment %q", comp.ID, adj)
+			}
+		}
+	}
+	for _, id := range c.Destruction.CoreSections {
+		if !sections[id] {
+			return fmt.Errorf("destruction: unknown core section %q", id)
+		}
+	}
+	for role, count := range c.Crew {
+		if count < 0 {
+			return fmt.Errorf("crew %s: negative complement %d", role, count)
+		}
+	}
+	for role, station := range c.Stations {
+		if !compartments[station] && (len(c.Compartments) > 0 || !sections[station]) {
+			return fmt.Errorf("station %s: unknown compartment %q", role, station)
+		}
+	}
+	for _, door := range c.Doors {
+		if len(door.Connects) != 2 || door.Connects[0] == door.Connects[1] {
+			return fmt.Errorf("door %s: must connect two compartments", door.ID)
+		}
+		for _, id := range door.Connects {
+			if !compartments[id] {
+				return fmt.Errorf("door %s: unknown compartment %q", door.ID, id)
+			}
+		}
+	}
+	return nil
+}
+
 func LoadShipClasses(dir string) (map[string]*ShipClass, error) {
 	classes := make(map[string]*ShipClass)
 
@@ -122,6 +266,9 @@ func LoadShipClasses(dir string) (map[string]*ShipClass, error) {
 		if err := yaml.Unmarshal(data, &class); err != nil {
 			return nil, fmt.Errorf("parsing ship class %s: %w", entry.Name(), err)
 		}
+		if err := class.validateLayout(); err != nil {
+			return nil, fmt.Errorf("ship class %s: %w", entry.Name(), err)
+		}
 
 		classes[class.ID] = &class
 	}
@@ -0,0 +1,281 @@
+package damage
+
+import (
+	"celestial/internal/events"
+	"celestial/internal/ship"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+const (
+	teamStepTime     = 5.0
+	teamRepairRate   = 5.0
+	teamFireRate     = 0.1
+	teamSealTime     = 10.0
+	teamFireDamage   = 2.0
+	teamVacuumDamage = 10.0
+	teamMinPressure  = 50.0
+)
+
+// Deploy sends a damage control team to a compartment or hull section, and
+// optionally to repair a system there. With no team named, the nearest one
+// standing by goes. It returns the team sent.
+func (dc *DamageController) Deploy(teamID, location, systemType, systemID string) (string, error) {
+	if teamID == "" {
+		teamID = dc.nearestTeam(location)
+		if teamID == "" {
+			return "", fmt.Errorf("no damage control team available")
+		}
+	}
+	team, ok := dc.Ship.Teams[teamID]
+	if !ok {
+		return "", fmt.Errorf("damage control team not found: %s", teamID)
+	}
+	if team.Status == ship.TeamLost {
+		return "", fmt.Errorf("damage control team %s is lost", teamID)
+	}
+
+	target, path, err := dc.destination(team.Compartment, location)
+	if err != nil {
+		return "", err
+	}
+	team.Target = target
+	team.SystemType, team.SystemID = systemType, systemID
+	team.Path = path
+	team.Progress = 0
+	team.Status = ship.TeamMoving
+	log.Printf("Damage control team %s deployed to %s on ship %s", teamID, target, dc.Ship.ID)
+	dc.Ship.Publish(events.TeamDeployed, events.SourceDamage, map[string]interface{}{
+		"team":        teamID,
+		"compartment": target,
+		"system_type": systemType,
+		"system_id":   systemID,
+		"eta":         float64(len(path)) * teamStepTime,
+	})
+	return teamID, nil
+}
+
+// Recall sends a team back to its station.
+func (dc *DamageController) Recall(teamID string) error {
+	team, ok := dc.Ship.Teams[teamID]
+	if !ok {
+		return fmt.Errorf("damage control team not found: %s", teamID)
+	}
+	if team.Status == ship.TeamLost {
+		return fmt.Errorf("damage control team %s is lost", teamID)
+	}
+
+	path, err := dc.Ship.Route(team.Compartment, team.Home)
+	if err != nil {
+		return err
+	}
+	team.Target = ""
+	team.SystemType, team.SystemID = "", ""
+	team.Path = path
+	team.Progress = 0
+	team.Status = ship.TeamMoving
+	log.Printf("Damage control team %s recalled on ship %s", teamID, dc.Ship.ID)
+	return nil
+}
+
+// destination picks the compartment at a location a team should head for:
+// a burning one first, then a breached one, then the closest.
+func (dc *DamageController) destination(from, location string) (string, []string, error) {
+	best, bestRank := "", 0
+	var bestPath []string
+	for _, id := range dc.Ship.CompartmentsAt(location) {
+		path, err := dc.Ship.Route(from, id)
+		if err != nil {
+			continue
+		}
+		comp := dc.Ship.LifeSupport.Compartments[id]
+		rank := len(path)
+		if comp.OnFire {
+			rank -= 2000
+		} else if comp.Breached {
+			rank -= 1000
+		}
+		if best == "" || rank < bestRank {
+			best, bestRank, bestPath = id, rank, path
+		}
+	}
+	if best == "" {
+		return "", nil, fmt.Errorf("no route to %s", location)
+	}
+	return best, bestPath, nil
+}
+
+// nearestTeam returns the team standing by closest to a location.
+func (dc *DamageController) nearestTeam(location string) string {
+	ids := make([]string, 0, len(dc.Ship.Teams))
+	for id := range dc.Ship.Teams {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	best, bestSteps := "", 0
+	for _, id := range ids {
+		team := dc.Ship.Teams[id]
+		if team.Status != ship.TeamStandingBy {
+			continue
+		}
+		if _, path, err := dc.destination(team.Compartment, location); err == nil && (best == "" || len(path) < bestSteps) {
+			best, bestSteps = id, len(path)
+		}
+	}
+	return best
+}
+
+// Update moves the teams along their routes, harms those caught in fire or
+// vacuum, and has those on site work through fire, breach and repairs in
+// that order.
+func (dc *DamageController) Update(dt float64) {
+	ids := make([]string, 0, len(dc.Ship.Teams))
+	for id := range dc.Ship.Teams {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		team := dc.Ship.Teams[id]
+		if team.Status == ship.TeamLost {
+			continue
+		}
+		comp, ok := dc.Ship.LifeSupport.Compartments[team.Compartment]
+		if !ok {
+			continue
+		}
+
+		harm := teamFireDamage * comp.Fire
+		if comp.Pressure < teamMinPressure {
+			harm += teamVacuumDamage * (1 - comp.Pressure/teamMinPressure)
+		}
+		team.Health = math.Max(0, team.Health-harm*dt)
+		if team.Health == 0 {
+			team.Status = ship.TeamLost
+			team.Path = nil
+			team.ETA = 0
+			log.Printf("Damage control team %s lost in %s on ship %s", id, comp.ID, dc.Ship.ID)
+			dc.Ship.Publish(events.TeamLost, events.SourceDamage, map[string]interface{}{"team": id, "compartment": comp.ID})
+			continue
+		}
+
+		if len(team.Path) > 0 {
+			team.Status = ship.TeamMoving
+			team.Progress += dt
+			if team.Progress >= teamStepTime {
+				team.Progress -= teamStepTime
+				team.Compartment = team.Path[0]
+				team.Path = team.Path[1:]
+			}
+			team.ETA = float64(len(team.Path))*teamStepTime - team.Progress
+			if len(team.Path) == 0 {
+				team.Progress = 0
+			}
+			continue
+		}
+
+		dc.work(team, dt)
+	}
+}
+
+// work has a team on site deal with the worst problem in its compartment.
+func (dc *DamageController) work(team *ship.DamageTeam, dt float64) {
+	comp := dc.Ship.LifeSupport.Compartments[team.Compartment]
+	if team.Target == "" {
+		team.Status = ship.TeamStandingBy
+		team.ETA = 0
+		return
+	}
+
+	// Teams repair slower when engineering is short-handed.
+	rate := teamRepairRate * dc.Ship.Effectiveness("engineer")
+
+	switch {
+	case comp.OnFire:
+		team.Status = ship.TeamFirefighting
+		comp.Fire -= teamFireRate * dt
+		team.ETA = math.Max(0, comp.Fire) / teamFireRate
+		if comp.Fire <= 0 {
+			dc.ExtinguishFire(comp.ID)
+		}
+
+	case comp.Breached:
+		if team.Status != ship.TeamSealing {
+			team.Status = ship.TeamSealing
+			team.Progress = 0
+		}
+		team.Progress += dt
+		team.ETA = math.Max(0, teamSealTime-team.Progress)
+		if team.Progress >= teamSealTime {
+			team.Progress = 0
+			dc.SealBreach(comp.ID)
+		}
+
+	case dc.damaged(team.SystemType, team.SystemID):
+		team.Status = ship.TeamRepairing
+		dc.RepairSystem(team.SystemType, team.SystemID, rate*dt)
+		team.ETA = dc.missingHealth(team.SystemType, team.SystemID) / rate
+		if !dc.damaged(team.SystemType, team.SystemID) {
+			log.Printf("Damage control team %s repaired %s %s on ship %s", team.ID, team.SystemType, team.SystemID, dc.Ship.ID)
+			dc.repaired(team, team.SystemType, team.SystemID)
+			team.SystemType, team.SystemID = "", ""
+		}
+
+	case dc.damaged("hull", comp.Section):
+		team.Status = ship.TeamRepairing
+		dc.RepairSystem("hull", comp.Section, rate*dt)
+		team.ETA = dc.missingHealth("hull", comp.Section) / rate
+		if !dc.damaged("hull", comp.Section) {
+			log.Printf("Damage control team %s repaired hull section %s on ship %s", team.ID, comp.Section, dc.Ship.ID)
+			dc.repaired(team, "hull", comp.Section)
+		}
+
+	default:
+		team.Status = ship.TeamStandingBy
+		team.Target = ""
+		team.ETA = 0
+	}
+}
+
+func (dc *DamageController) repaired(team *ship.DamageTeam, systemType, systemID string) {
+	dc.Ship.Publish(events.SystemRepaired, events.SourceDamage, map[string]interface{}{
+		"team":        team.ID,
+		"system_type": systemType,
+		"system_id":   systemID,
+	})
+}
+
+func (dc *DamageController) damaged(systemType, systemID string) bool {
+	return dc.missingHealth(systemType, systemID) > 0
+}
+
+// missingHealth is how much health a system has lost, zero for systems that
+// do not exist.
+func (dc *DamageController) missingHealth(systemType, systemID string) float64 {
+	switch systemType {
+	case "engine":
+		if engine, ok := dc.Ship.Engines[systemID]; ok {
+			return engine.MaxHealth - engine.Health
+		}
+	case "weapon":
+		if weapon, ok := dc.Ship.Weapons[systemID]; ok {
+			return weapon.MaxHealth - weapon.Health
+		}
+	case "shield":
+		if emitter, ok := dc.Ship.Shields.Emitters[systemID]; ok {
+			return emitter.MaxHealth - emitter.Health
+		}
+	case "hull":
+		if section, ok := dc.Ship.Hull.Sections[systemID]; ok {
+			return section.MaxHealth - section.Health
+		}
+	case "subsystem":
+		if subsystem, ok := dc.Ship.Subsystems[systemID]; ok {
+			return subsystem.MaxHealth - subsystem.Health
+		}
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package damage
+
+import (
+	"celestial/internal/config"
+	"celestial/internal/ship"
+	"testing"
+)
+
+func testShip() *ship.Ship {
+	class := &config.ShipClass{
+		ID:   "test_ship",
+		Name: "Test Ship",
+		Mass: 100000,
+		Hull: config.HullConfig{
+			Sections: []config.HullSectionConfig{
+				{ID: "forward", Armor: 200, Health: 500},
+				{ID: "port", Armor: 200, Health: 500},
+				{ID: "aft", Armor: 200, Health: 500},
+			},
+		},
+		Compartments: []config.CompartmentConfig{
+			{ID: "bridge", Section: "forward"},
+			{ID: "corridor", Section: "port"},
+			{ID: "engine_room", Section: "aft"},
+			{ID: "cargo", Section: "aft"},
+		},
+		Doors: []config.DoorConfig{
+			{ID: "bridge_corridor", Connects: []string{"bridge", "corridor"}},
+			{ID: "corridor_engine_room", Connects: []string{"corridor", "engine_room"}, Closed: true},
+			{ID: "engine_room_cargo", Connects: []string{"engine_room", "cargo"}},
+		},
+		Stations:    map[string]string{"damage_control": "bridge"},
+		DamageTeams: 2,
+	}
+	return ship.NewShip("player", "test_ship", "Player", class, true)
+}
+
+func TestDeploy(t *testing.T) {
+	dc := NewDamageController(testShip())
+
+	team, err := dc.Deploy("", "engine_room", "hull", "aft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha := dc.Ship.Teams[team]
+	if team != "alpha" || alpha.Status != ship.TeamMoving || alpha.Target != "engine_room" {
+		t.Fatalf("Expected alpha sent to engine_room, got %s (%s) to %s", team, alpha.Status, alpha.Target)
+	}
+	if len(alpha.Path) != 2 || alpha.Path[0] != "corridor" || alpha.Path[1] != "engine_room" {
+		t.Errorf("Expected a route through the closed door, got %v", alpha.Path)
+	}
+	if alpha.SystemType != "hull" || alpha.SystemID != "aft" {
+		t.Errorf("Expected alpha to carry its repair order, got %s %s", alpha.SystemType, alpha.SystemID)
+	}
+
+	if team, err := dc.Deploy("", "engine_room", "", ""); err != nil || team != "beta" {
+		t.Errorf("Expected beta to go once alpha is busy, got %s (%v)", team, err)
+	}
+	if _, err := dc.Deploy("", "engine_room", "", ""); err == nil {
+		t.Error("Expected an error with no team standing by")
+	}
+	if _, err := dc.Deploy("gamma", "engine_room", "", ""); err == nil {
+		t.Error("Expected an error for an unknown team")
+	}
+	if _, err := dc.Deploy("alpha", "nowhere", "", ""); err == nil {
+		t.Error("Expected an error for an unknown location")
+	}
+	dc.Ship.Teams["beta"].Status = ship.TeamLost
+	if _, err := dc.Deploy("beta", "bridge", "", ""); err == nil {
+		t.Error("Expected an error deploying a lost team")
+	}
+
+	for i := 0; i < 20; i++ {
+		dc.Update(1)
+	}
+	if alpha.Compartment != "engine_room" || len(alpha.Path) != 0 {
+		t.Errorf("Expected alpha to reach engine_room, got %s with %v left", alpha.Compartment, alpha.Path)
+	}
+	if dc.Ship.LifeSupport.Doors["corridor_engine_room"].Open {
+		t.Error("Expected the team to leave the closed door closed")
+	}
+}
+
+func TestDestination(t *testing.T) {
+	dc := NewDamageController(testShip())
+	comps := dc.Ship.LifeSupport.Compartments
+
+	if target, path, err := dc.destination("bridge", "aft"); err != nil || target != "engine_room" || len(path) != 2 {
+		t.Errorf("Expected the closest compartment, got %s via %v (%v)", target, path, err)
+	}
+
+	comps["cargo"].Breached = true
+	if target, _, _ := dc.destination("bridge", "aft"); target != "cargo" {
+		t.Errorf("Expected the breached compartment, got %s", target)
+	}
+
+	if err := dc.Ship.StartFire("engine_room"); err != nil {
+		t.Fatal(err)
+	}
+	if target, _, _ := dc.destination("bridge", "aft"); target != "engine_room" {
+		t.Errorf("Expected the burning compartment before the breached one, got %s", target)
+	}
+
+	if _, _, err := dc.destination("bridge", "starboard"); err == nil {
+		t.Error("Expected an error for a location with no compartments")
+	}
+}
+
+func TestWork(t *testing.T) {
+	dc := NewDamageController(testShip())
+	comp := dc.Ship.LifeSupport.Compartments["cargo"]
+	if err := dc.Ship.StartFire("cargo"); err != nil {
+		t.Fatal(err)
+	}
+	comp.Breached = true
+	dc.Ship.Hull.Sections["aft"].Health = 400
+
+	team := dc.Ship.Teams["alpha"]
+	team.Compartment, team.Target = "cargo", "cargo"
+
+	var statuses []string
+	for i := 0; i < 1000 && team.Target != ""; i++ {
+		dc.work(team, 0.1)
+		if len(statuses) == 0 || statuses[len(statuses)-1] != team.Status {
+			statuses = append(statuses, team.Status)
+		}
+	}
+
+	want := []string{ship.TeamFirefighting, ship.TeamSealing, ship.TeamRepairing, ship.TeamStandingBy}
+	if len(statuses) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, statuses)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, statuses)
+		}
+	}
+	if comp.OnFire || comp.Breached || dc.Ship.Hull.Sections["aft"].Health != 500 {
+		t.Errorf("Expected the fire out, the breach sealed and the hull repaired, got fire %v breach %v hull %f",
+			comp.OnFire, comp.Breached, dc.Ship.Hull.Sections["aft"].Health)
+	}
+}
@@ -0,0 +1,117 @@
+package events
+
+import "sync"
+
+// Event is something notable that happened in the world. Source names the
+// package that published it.
+type Event struct {
+	Type   string                 `json:"type"`
+	Time   float64                `json:"time"`
+	Source string                 `json:"source,omitempty"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+// Publisher is anything events can be published to.
+type Publisher interface {
+	Publish(eventType, source string, data map[string]interface{})
+}
+
+type subscription struct {
+	id    int
+	types map[string]bool
+	fn    func(Event)
+}
+
+// Bus queues published events and delivers them to subscribers when
+// flushed. Publishing never calls a subscriber, so it is safe while holding
+// the simulator or ship locks.
+type Bus struct {
+	mu     sync.Mutex
+	now    float64
+	queue  []Event
+	subs   []*subscription
+	nextID int
+
+	delivering sync.Mutex
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// SetTime sets the simulation time stamped on events published from now on.
+func (b *Bus) SetTime(t float64) {
+	b.mu.Lock()
+	b.now = t
+	b.mu.Unlock()
+}
+
+// Publish queues an event for the next Flush.
+func (b *Bus) Publish(eventType, source string, data map[string]interface{}) {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	b.mu.Lock()
+	b.queue = append(b.queue, Event{Type: eventType, Time: b.now, Source: source, Data: data})
+	b.mu.Unlock()
+}
+
+// Subscribe registers fn to receive events of the given types, or every
+// event if no types are given. It returns a function that cancels the
+// subscription.
+func (b *Bus) Subscribe(fn func(Event), types ...string) func() {
+	sub := &subscription{fn: fn}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	sub.id = b.nextID
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == sub.id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Flush delivers queued events in order, including any that subscribers
+// publish along the way. Subscribers run on the flushing goroutine without
+// any bus lock held. If another goroutine is already flushing, Flush
+// returns at once and leaves the queue to it.
+func (b *Bus) Flush() {
+	if !b.delivering.TryLock() {
+		return
+	}
+	defer b.delivering.Unlock()
+
+	for {
+		b.mu.Lock()
+		queue := b.queue
+		subs := b.subs
+		b.queue = nil
+		b.mu.Unlock()
+
+		if len(queue) == 0 {
+			return
+		}
+		for _, event := range queue {
+			for _, sub := range subs {
+				if sub.types == nil || sub.types[event.Type] {
+					sub.fn(event)
+				}
+			}
+		}
+	}
+}
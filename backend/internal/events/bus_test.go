@@ -0,0 +1,103 @@
+package events
+
+import "testing"
+
+func TestBusDeliversInOrder(t *testing.T) {
+	bus := NewBus()
+	var got []Event
+	bus.Subscribe(func(e Event) {
+		got = append(got, e)
+	})
+
+	bus.SetTime(1.5)
+	bus.Publish(HullBreach, SourceShip, map[string]interface{}{"compartment": "bridge"})
+	bus.Publish(FireStarted, SourceShip, nil)
+	if len(got) != 0 {
+		t.Fatal("Publish should only queue until Flush")
+	}
+
+	bus.Flush()
+	if len(got) != 2 || got[0].Type != HullBreach || got[1].Type != FireStarted {
+		t.Fatalf("Expected hull_breach then fire_started, got %+v", got)
+	}
+	if got[0].Time != 1.5 || got[0].Source != SourceShip || got[0].Data["compartment"] != "bridge" {
+		t.Errorf("Event not stamped as published: %+v", got[0])
+	}
+	if got[1].Data == nil {
+		t.Error("Events published without data should carry an empty map")
+	}
+
+	bus.Flush()
+	if len(got) != 2 {
+		t.Error("Flush should not deliver an event twice")
+	}
+}
+
+func TestBusPublishDuringFlush(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	bus.Subscribe(func(e Event) {
+		got = append(got, e.Type)
+		if e.Type == FireStarted {
+			bus.Publish(TeamDeployed, SourceDamage, nil)
+			// A nested flush leaves delivery to the outer one.
+			bus.Flush()
+		}
+	})
+
+	bus.Publish(FireStarted, SourceShip, nil)
+	bus.Publish(FireOut, SourceShip, nil)
+	bus.Flush()
+
+	want := []string{FireStarted, FireOut, TeamDeployed}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected event %d to be %s, got %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestBusSubscribeAndCancel(t *testing.T) {
+	bus := NewBus()
+	var fires, all int
+	cancel := bus.Subscribe(func(e Event) {
+		fires++
+	}, FireStarted, FireOut)
+	bus.Subscribe(func(e Event) {
+		all++
+	})
+
+	bus.Publish(FireStarted, SourceShip, nil)
+	bus.Publish(HullBreach, SourceShip, nil)
+	bus.Flush()
+	if fires != 1 || all != 2 {
+		t.Errorf("Expected 1 fire event and 2 in total, got %d and %d", fires, all)
+	}
+
+	cancel()
+	cancel()
+	bus.Publish(FireOut, SourceShip, nil)
+	bus.Flush()
+	if fires != 1 || all != 3 {
+		t.Errorf("A cancelled subscriber should get nothing more, got %d and %d", fires, all)
+	}
+}
+
+func TestVisible(t *testing.T) {
+	breach := Event{Type: HullBreach}
+	if !Visible(breach, "engineer") || !Visible(breach, "captain") {
+		t.Error("Engineering and the captain should see hull breaches")
+	}
+	if Visible(breach, "weapons") || Visible(breach, "flight") {
+		t.Error("Weapons and flight should not see hull breaches")
+	}
+	if !Visible(breach, "gm") || !Visible(breach, "") {
+		t.Error("The GM and clients with no station should see everything")
+	}
+	if !Visible(Event{Type: MissionWon}, "flight") {
+		t.Error("Every station should see event types not limited to some")
+	}
+}
@@ -0,0 +1,103 @@
+package events
+
+// Publishers.
+const (
+	SourceSimulation = "simulation"
+	SourceShip       = "ship"
+	SourceDamage     = "damage"
+	SourceAI         = "ai"
+	SourceMission    = "mission"
+	SourceGM         = "gm"
+)
+
+// Simulation events.
+const (
+	ProjectileHit       = "projectile_hit"
+	Collision           = "collision"
+	TorpedoLaunched     = "torpedo_launched"
+	TorpedoDecoyed      = "torpedo_decoyed"
+	TorpedoIntercepted  = "torpedo_intercepted"
+	TorpedoDetonated    = "torpedo_detonated"
+	DecoyDeployed       = "decoy_deployed"
+	DockingClamping     = "docking_clamping"
+	Docked              = "docked"
+	Undocked            = "undocked"
+	DockingAborted      = "docking_aborted"
+	AutopilotArrived    = "autopilot_arrived"
+	AutopilotDisengaged = "autopilot_disengaged"
+	ShipDisabled        = "ship_disabled"
+	ShipDestroyed       = "ship_destroyed"
+	Explosion           = "explosion"
+	WreckSalvaged       = "wreck_salvaged"
+)
+
+// Ship and damage control events.
+const (
+	HullBreach     = "hull_breach"
+	FireStarted    = "fire_started"
+	FireOut        = "fire_out"
+	CrewKilled     = "crew_killed"
+	TeamDeployed   = "team_deployed"
+	TeamLost       = "team_lost"
+	BreachSealed   = "breach_sealed"
+	SystemRepaired = "system_repaired"
+)
+
+// Trigger volume events. Entering a waypoint's trigger also publishes
+// WaypointReached, and entering any other trigger AreaReached.
+const (
+	TriggerEnter    = "trigger_enter"
+	TriggerExit     = "trigger_exit"
+	TriggerDwell    = "trigger_dwell"
+	WaypointReached = "waypoint_reached"
+	AreaReached     = "area_reached"
+)
+
+// AI and mission events.
+const (
+	AIStateChanged     = "ai_state_changed"
+	MissionStarted     = "mission_started"
+	MissionStopped     = "mission_stopped"
+	ObjectiveSet       = "objective_set"
+	ObjectiveCompleted = "objective_completed"
+	MissionWon         = "mission_won"
+	MissionLost        = "mission_lost"
+)
+
+// stationEvents limits event types to the stations that act on them. Every
+// station gets the types not listed.
+var stationEvents = map[string][]string{
+	TorpedoLaunched:     {"captain", "weapons", "relay"},
+	TorpedoDecoyed:      {"captain", "weapons", "relay"},
+	TorpedoIntercepted:  {"captain", "weapons", "relay"},
+	TorpedoDetonated:    {"captain", "weapons", "relay"},
+	DecoyDeployed:       {"captain", "weapons", "relay"},
+	DockingClamping:     {"captain", "flight", "operations"},
+	DockingAborted:      {"captain", "flight", "operations"},
+	AutopilotArrived:    {"captain", "flight"},
+	AutopilotDisengaged: {"captain", "flight"},
+	HullBreach:          {"captain", "engineer", "first_officer"},
+	FireStarted:         {"captain", "engineer", "first_officer"},
+	FireOut:             {"captain", "engineer", "first_officer"},
+	CrewKilled:          {"captain", "first_officer"},
+	TeamDeployed:        {"engineer", "first_officer"},
+	TeamLost:            {"captain", "engineer", "first_officer"},
+	BreachSealed:        {"engineer", "first_officer"},
+	SystemRepaired:      {"engineer", "first_officer"},
+	AIStateChanged:      {"captain", "weapons", "relay"},
+}
+
+// Visible reports whether a client at a station should see an event. The
+// GM and clients with no station see everything.
+func Visible(event Event, role string) bool {
+	roles, ok := stationEvents[event.Type]
+	if !ok || role == "" || role == "gm" {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -1,25 +1,35 @@
 package gm
 
 import (
+	"celestial/internal/events"
 	"celestial/internal/mission"
 	"celestial/internal/ship"
 	"celestial/internal/simulation"
+	"celestial/internal/view"
 	"log"
 	"time"
 )
 
 type Controller struct {
-	simulator      *simulation.Simulator
-	missionEngine  *mission.Engine
-	snapshotTicker *time.Ticker
-	stopChan       chan struct{}
+	simulator        *simulation.Simulator
+	missionEngine    *mission.Engine
+	snapshotInterval time.Duration
+	snapshotTicker   *time.Ticker
+	stopChan         chan struct{}
 }
 
-func NewController(sim *simulation.Simulator, missionEng *mission.Engine) *Controller {
+const defaultSnapshotInterval = 20 * time.Second
+
+func NewController(sim *simulation.Simulator, missionEng *mission.Engine, snapshotInterval time.Duration) *Controller {
+	if snapshotInterval <= 0 {
+		snapshotInterval = defaultSnapshotInterval
+	}
+
 	ctrl := &Controller{
-		simulator:     sim,
-		missionEngine: missionEng,
-		stopChan:      make(chan struct{}),
+		simulator:        sim,
+		missionEngine:    missionEng,
+		snapshotInterval: snapshotInterval,
+		stopChan:         make(chan struct{}),
 	}
 
 	ctrl.startSnapshotLoop()
@@ -27,14 +37,16 @@ func NewController(sim *simulation.Simulator, missionEng *mission.Engine) *Contr
 }
 
 func (c *Controller) startSnapshotLoop() {
-	c.snapshotTicker = time.NewTicker(20 * time.Second)
+	c.snapshotTicker = time.NewTicker(c.snapshotInterval)
 	go func() {
 		for {
 			select {
 			case <-c.stopChan:
 				return
 			case <-c.snapshotTicker.C:
-				c.simulator.CreateSnapshot()
+				if !c.simulator.IsPaused() {
+					c.simulator.CreateSnapshot()
+				}
 			}
 		}
 	}()
@@ -57,11 +69,47 @@ func (c *Controller) Resume() {
 	log.Println("GM: Simulation resumed")
 }
 
+func (c *Controller) SetTimeScale(scale float64) {
+	c.simulator.SetTimeScale(scale)
+	log.Printf("GM: Time scale set to %.2fx", c.simulator.TimeScale())
+}
+
+func (c *Controller) Step() {
+	if !c.simulator.IsPaused() {
+		log.Println("GM: Step ignored, simulation is running")
+		return
+	}
+	c.simulator.Step()
+	log.Println("GM: Stepped simulation one tick")
+}
+
 func (c *Controller) CreateSnapshot() {
 	c.simulator.CreateSnapshot()
 	log.Println("GM: Manual snapshot created")
 }
 
+func (c *Controller) CreateNamedSnapshot(label string, pinned bool) simulation.SnapshotInfo {
+	snapshot := c.simulator.CreateNamedSnapshot(label, pinned)
+	log.Printf("GM: Created snapshot %d %q (pinned: %v)", snapshot.ID, label, pinned)
+	return snapshot.Info()
+}
+
+func (c *Controller) PinSnapshot(id int, pinned bool) error {
+	if err := c.simulator.PinSnapshot(id, pinned); err != nil {
+		log.Printf("GM: Failed to pin snapshot: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) LabelSnapshot(id int, label string) error {
+	if err := c.simulator.LabelSnapshot(id, label); err != nil {
+		log.Printf("GM: Failed to label snapshot: %v", err)
+		return err
+	}
+	return nil
+}
+
 func (c *Controller) RestoreSnapshot(index int) error {
 	err := c.simulator.RestoreSnapshot(index)
 	if err != nil {
@@ -72,8 +120,37 @@ func (c *Controller) RestoreSnapshot(index int) error {
 	return nil
 }
 
-func (c *Controller) GetSnapshots() []*simulation.Snapshot {
-	return c.simulator.Snapshots
+func (c *Controller) RestoreSnapshotByID(id int) error {
+	err := c.simulator.RestoreSnapshotByID(id)
+	if err != nil {
+		log.Printf("GM: Failed to restore snapshot: %v", err)
+		return err
+	}
+	log.Printf("GM: Restored snapshot id %d", id)
+	return nil
+}
+
+func (c *Controller) RestoreStoredSnapshot(file string) error {
+	if err := c.simulator.RestoreStoredSnapshot(file); err != nil {
+		log.Printf("GM: Failed to restore stored snapshot: %v", err)
+		return err
+	}
+	log.Printf("GM: Restored stored snapshot %s", file)
+	return nil
+}
+
+func (c *Controller) GetSnapshots() []simulation.SnapshotInfo {
+	return c.simulator.ListSnapshots()
+}
+
+// GetStoredSnapshots lists the snapshots on disk, which include those of
+// earlier sessions.
+func (c *Controller) GetStoredSnapshots() []simulation.SnapshotInfo {
+	infos, err := c.simulator.StoredSnapshots()
+	if err != nil {
+		log.Printf("GM: Failed to list stored snapshots: %v", err)
+	}
+	return infos
 }
 
 func (c *Controller) SpawnShip(id, classID, name string, isPlayer bool, position ship.Vector3) error {
@@ -91,6 +168,14 @@ func (c *Controller) RemoveShip(id string) {
 	log.Printf("GM: Removed ship %s", id)
 }
 
+func (c *Controller) DestroyShip(id string) {
+	if err := c.simulator.DestroyShip(id, "gm"); err != nil {
+		log.Printf("GM: Failed to destroy ship: %v", err)
+		return
+	}
+	log.Printf("GM: Destroyed ship %s", id)
+}
+
 func (c *Controller) ModifyShipSystem(shipID, systemType, systemID string, property string, value interface{}) {
 	sh := c.simulator.GetShip(shipID)
 	if sh == nil {
@@ -98,33 +183,25 @@ func (c *Controller) ModifyShipSystem(shipID, systemType, systemID string, prope
 		return
 	}
 
-	switch systemType {
-	case "engine":
-		if engine, ok := sh.Engines[systemID]; ok {
-			c.applyProperty(engine, property, value)
-		}
-	case "weapon":
-		if weapon, ok := sh.Weapons[systemID]; ok {
-			c.applyProperty(weapon, property, value)
-		}
-	case "shield":
-		if emitter, ok := sh.Shields.Emitters[systemID]; ok {
-			c.applyProperty(emitter, property, value)
-		}
-	case "hull":
-		if section, ok := sh.Hull.Sections[systemID]; ok {
-			c.applyProperty(section, property, value)
+	number, ok := value.(float64)
+	if enabled, isBool := value.(bool); isBool {
+		number, ok = 0, true
+		if enabled {
+			number = 1
 		}
 	}
+	if !ok {
+		log.Printf("GM: Cannot set %s.%s.%s to %v", systemType, systemID, property, value)
+		return
+	}
+	if err := sh.SetSystem(systemType, systemID, property, number); err != nil {
+		log.Printf("GM: Failed to modify ship system: %v", err)
+		return
+	}
 
 	log.Printf("GM: Modified %s.%s.%s = %v on ship %s", systemType, systemID, property, value, shipID)
 }
 
-func (c *Controller) applyProperty(obj interface{}, property string, value interface{}) {
-	// Simplified property setting
-	log.Printf("Applying property %s = %v", property, value)
-}
-
 func (c *Controller) DamageShip(shipID string, amount float64, location string) {
 	sh := c.simulator.GetShip(shipID)
 	if sh == nil {
@@ -152,7 +229,7 @@ func (c *Controller) StopMission() {
 }
 
 func (c *Controller) TriggerEvent(eventName string, params map[string]interface{}) {
-	c.missionEngine.TriggerEvent(eventName, params)
+	c.simulator.Events().Publish(eventName, events.SourceGM, params)
 	log.Printf("GM: Triggered event %s", eventName)
 }
 
@@ -165,6 +242,7 @@ func (c *Controller) GetSimulationState() map[string]interface{} {
 			"id":       sh.ID,
 			"name":     sh.Name,
 			"class":    sh.ClassID,
+			"faction":  sh.Faction,
 			"position": sh.Position,
 			"velocity": sh.Velocity,
 			"health":   c.getShipHealth(sh),
@@ -182,10 +260,13 @@ func (c *Controller) GetSimulationState() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"time":           c.simulator.CurrentTime,
+		"time":           c.simulator.GetTime(),
+		"paused":         c.simulator.IsPaused(),
+		"time_scale":     c.simulator.TimeScale(),
 		"ships":          shipData,
+		"triggers":       view.Triggers(c.simulator.GetTriggers()),
 		"active_mission": missionData,
-		"snapshot_count": len(c.simulator.Snapshots),
+		"snapshot_count": c.simulator.SnapshotCount(),
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"celestial/internal/simulation"
 	"fmt"
 	"log"
+	"strings"
 )
 
 type Action struct {
@@ -17,10 +18,13 @@ type Action struct {
 type ActionRouter struct {
 	simulator *simulation.Simulator
 	handlers  map[string]ActionHandler
+	observers []ActionObserver
 }
 
 type ActionHandler func(action *Action) error
 
+type ActionObserver func(action *Action)
+
 func NewActionRouter(sim *simulation.Simulator) *ActionRouter {
 	router := &ActionRouter{
 		simulator: sim,
@@ -33,19 +37,29 @@ func NewActionRouter(sim *simulation.Simulator) *ActionRouter {
 
 func (ar *ActionRouter) registerHandlers() {
 	ar.handlers["engineer.power.toggle_breaker"] = ar.handleToggleBreaker
+	ar.handlers["engineer.coolant.assign"] = ar.handleAssignCoolant
 	ar.handlers["engineer.damage.repair"] = ar.handleRepair
 	ar.handlers["engineer.damage.extinguish_fire"] = ar.handleExtinguishFire
+	ar.handlers["engineer.damage.extinguish"] = ar.handleExtinguishFire
 	ar.handlers["engineer.damage.seal_breach"] = ar.handleSealBreach
+	ar.handlers["engineer.damage.door"] = ar.handleSetDoor
+	ar.handlers["engineer.damage.vent"] = ar.handleVentCompartment
 
 	ar.handlers["flight.thrust.set"] = ar.handleSetThrust
+	ar.handlers["flight.strafe.set"] = ar.handleSetStrafe
 	ar.handlers["flight.rotation.set"] = ar.handleSetRotation
+	ar.handlers["flight.docking.request"] = ar.handleRequestDocking
 	ar.handlers["flight.docking.release"] = ar.handleReleaseDocking
+	ar.handlers["flight.flight.navigate_to"] = ar.handleNavigateTo
+	ar.handlers["flight.flight.clear_navigation"] = ar.handleClearNavigation
+	ar.handlers["flight.flight.autopilot"] = ar.handleAutopilot
 
 	ar.handlers["weapons.torpedo.arm"] = ar.handleArmTorpedo
 	ar.handlers["weapons.torpedo.load"] = ar.handleLoadTorpedo
 	ar.handlers["weapons.torpedo.lock"] = ar.handleLockTorpedo
 	ar.handlers["weapons.torpedo.fire"] = ar.handleFireTorpedo
 	ar.handlers["weapons.phaser.fire"] = ar.handleFirePhaser
+	ar.handlers["weapons.decoy.deploy"] = ar.handleDeployDecoy
 	ar.handlers["weapons.target.set"] = ar.handleSetTarget
 
 	ar.handlers["captain.alert.set"] = ar.handleSetAlert
@@ -55,12 +69,23 @@ func (ar *ActionRouter) registerHandlers() {
 	ar.handlers["comms.message.send"] = ar.handleSendMessage
 
 	ar.handlers["operations.power.route"] = ar.handleRoutePower
+	ar.handlers["operations.power.preset"] = ar.handlePowerPreset
+	ar.handlers["operations.engines.route_power"] = ar.handleRoutePower
+	ar.handlers["operations.weapons.route_power"] = ar.handleRoutePower
+	ar.handlers["operations.shields.route_power"] = ar.handleRoutePower
 	ar.handlers["operations.shields.toggle"] = ar.handleToggleShields
+	ar.handlers["operations.shields.raise"] = ar.handleRaiseShields
+	ar.handlers["operations.shields.lower"] = ar.handleLowerShields
+	ar.handlers["operations.shields.set_frequency"] = ar.handleSetShieldFrequency
+	ar.handlers["operations.shields.rotate_frequency"] = ar.handleRotateShieldFrequency
+	ar.handlers["operations.shields.reinforce"] = ar.handleReinforceShields
 
 	ar.handlers["relay.scan.initiate"] = ar.handleInitiateScan
 	ar.handlers["relay.sensors.set_mode"] = ar.handleSetSensorMode
 
 	ar.handlers["first_officer.system.toggle"] = ar.handleToggleSystem
+	ar.handlers["first_officer.xo.deploy_team"] = ar.handleDeployTeam
+	ar.handlers["first_officer.xo.recall_team"] = ar.handleRecallTeam
 }
 
 func (ar *ActionRouter) RouteAction(action *Action) error {
@@ -71,59 +96,180 @@ func (ar *ActionRouter) RouteAction(action *Action) error {
 	}
 
 	log.Printf("Routing action: %s (value: %v)", key, action.Value)
+	for _, observe := range ar.observers {
+		observe(action)
+	}
 	return handler(action)
 }
 
+func (ar *ActionRouter) AddObserver(observer ActionObserver) {
+	ar.observers = append(ar.observers, observer)
+}
+
 func (ar *ActionRouter) handleToggleBreaker(action *Action) error {
 	playerShip := ar.getPlayerShip()
 	if playerShip == nil {
 		return fmt.Errorf("no player ship found")
 	}
 
-	enabled, ok := action.Value.(bool)
-	if !ok {
+	// Stations send {"breaker": id, "enabled": bool}; panels name the breaker
+	// in the system field and send the new state as the value.
+	breakerID := strings.TrimPrefix(action.System, "breaker_")
+	var enabled bool
+	switch v := action.Value.(type) {
+	case bool:
+		enabled = v
+	case map[string]interface{}:
+		id, _ := v["breaker"].(string)
+		on, ok := v["enabled"].(bool)
+		if id == "" || !ok {
+			return fmt.Errorf("invalid value for toggle_breaker")
+		}
+		breakerID, enabled = id, on
+	default:
 		return fmt.Errorf("invalid value type for toggle_breaker")
 	}
 
-	breakerID := action.System
-	breaker, ok := playerShip.Power.Breakers[breakerID]
-	if !ok {
-		return fmt.Errorf("breaker not found: %s", breakerID)
+	if err := playerShip.SetBreaker(breakerID, enabled); err != nil {
+		return err
 	}
-
-	breaker.Enabled = enabled
 	log.Printf("Breaker %s set to %v", breakerID, enabled)
 	return nil
 }
 
+// handleAssignCoolant moves coolant units onto a power group. The value is
+// either {"group": name, "units": n} or a bare unit count for the group
+// named by the action's system.
+func (ar *ActionRouter) handleAssignCoolant(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	group := action.System
+	var units float64
+	switch v := action.Value.(type) {
+	case float64:
+		units = v
+	case map[string]interface{}:
+		g, _ := v["group"].(string)
+		u, ok := v["units"].(float64)
+		if g == "" || !ok {
+			return fmt.Errorf("invalid value for coolant assign")
+		}
+		group, units = g, u
+	default:
+		return fmt.Errorf("invalid value type for coolant assign")
+	}
+
+	if err := playerShip.AssignCoolant(group, int(units)); err != nil {
+		return err
+	}
+	log.Printf("Assigned %d coolant units to: %s", int(units), group)
+	return nil
+}
+
+// handleRepair sends the nearest damage control team standing by to a
+// location.
 func (ar *ActionRouter) handleRepair(action *Action) error {
 	playerShip := ar.getPlayerShip()
 	if playerShip == nil {
 		return fmt.Errorf("no player ship found")
 	}
 
-	log.Printf("Repair initiated on system: %s", action.System)
+	location := damageLocation(action)
+	team, err := ar.simulator.DeployTeam(playerShip.ID, "", location, "", "")
+	if err != nil {
+		return err
+	}
+	log.Printf("Repair initiated in %s by team %s", location, team)
 	return nil
 }
 
-func (ar *ActionRouter) handleExtinguishFire(action *Action) error {
+// teamID reads the team a first officer order names, either by index from
+// the station or by ID.
+func teamID(v map[string]interface{}) (string, error) {
+	switch team := v["team"].(type) {
+	case float64:
+		return ship.TeamName(int(team)), nil
+	case string:
+		return team, nil
+	}
+	return "", fmt.Errorf("invalid damage control team")
+}
+
+// handleDeployTeam sends a damage control team to a location. Stations send
+// {"team": index or id, "section": id} or a compartment in place of the
+// section, and may add {"system": type, "id": id} to have a system repaired.
+func (ar *ActionRouter) handleDeployTeam(action *Action) error {
 	playerShip := ar.getPlayerShip()
 	if playerShip == nil {
 		return fmt.Errorf("no player ship found")
 	}
 
-	location := action.System
-	section, ok := playerShip.Hull.Sections[location]
-	if ok {
-		section.OnFire = false
+	v, ok := action.Value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid value type for deploy_team")
+	}
+	team, err := teamID(v)
+	if err != nil {
+		return err
 	}
+	systemType, _ := v["system"].(string)
+	systemID, _ := v["id"].(string)
 
-	comp, ok := playerShip.LifeSupport.Compartments[location]
-	if ok {
-		comp.OnFire = false
+	_, err = ar.simulator.DeployTeam(playerShip.ID, team, damageLocation(action), systemType, systemID)
+	return err
+}
+
+func (ar *ActionRouter) handleRecallTeam(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
 	}
 
-	log.Printf("Fire extinguished in: %s", location)
+	v, ok := action.Value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid value type for recall_team")
+	}
+	team, err := teamID(v)
+	if err != nil {
+		return err
+	}
+	return ar.simulator.RecallTeam(playerShip.ID, team)
+}
+
+// damageLocation returns the compartment or hull section a damage control
+// action targets: {"section": id} or {"compartment": id} from stations, or
+// the action's system from panels.
+func damageLocation(action *Action) string {
+	if v, ok := action.Value.(map[string]interface{}); ok {
+		for _, key := range []string{"compartment", "section"} {
+			if location, _ := v[key].(string); location != "" {
+				return location
+			}
+		}
+	}
+	return action.System
+}
+
+// handleExtinguishFire discharges suppressant at a location. Stations may
+// name the agent with {"agent": "halon" | "foam"}.
+func (ar *ActionRouter) handleExtinguishFire(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	location := damageLocation(action)
+	agent := ""
+	if v, ok := action.Value.(map[string]interface{}); ok {
+		agent, _ = v["agent"].(string)
+	}
+	if err := playerShip.Suppress(location, agent); err != nil {
+		return err
+	}
+	log.Printf("Fire suppression discharged in: %s", location)
 	return nil
 }
 
@@ -133,18 +279,74 @@ func (ar *ActionRouter) handleSealBreach(action *Action) error {
 		return fmt.Errorf("no player ship found")
 	}
 
-	location := action.System
-	comp, ok := playerShip.LifeSupport.Compartments[location]
-	if ok {
-		comp.Breached = false
+	location := damageLocation(action)
+	if err := playerShip.SealBreach(location); err != nil {
+		return err
 	}
+	log.Printf("Breach sealed in: %s", location)
+	return nil
+}
 
-	section, ok := playerShip.Hull.Sections[location]
-	if ok {
-		section.Breached = false
+// handleSetDoor opens or closes a door. Stations send {"door": id, "open":
+// bool}; panels name the door in the system field and send the new state.
+func (ar *ActionRouter) handleSetDoor(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
 	}
 
-	log.Printf("Breach sealed in: %s", location)
+	doorID := strings.TrimPrefix(action.System, "door_")
+	var open bool
+	switch v := action.Value.(type) {
+	case bool:
+		open = v
+	case map[string]interface{}:
+		id, _ := v["door"].(string)
+		o, ok := v["open"].(bool)
+		if id == "" || !ok {
+			return fmt.Errorf("invalid value for door")
+		}
+		doorID, open = id, o
+	default:
+		return fmt.Errorf("invalid value type for door")
+	}
+
+	if err := playerShip.SetDoor(doorID, open); err != nil {
+		return err
+	}
+	log.Printf("Door %s open: %v", doorID, open)
+	return nil
+}
+
+// handleVentCompartment opens or closes a compartment's vents to space.
+// Stations send {"compartment": id, "vent": bool}; panels name the
+// compartment in the system field and send the new state.
+func (ar *ActionRouter) handleVentCompartment(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	compartment := strings.TrimPrefix(action.System, "vent_")
+	var vent bool
+	switch v := action.Value.(type) {
+	case bool:
+		vent = v
+	case map[string]interface{}:
+		id, _ := v["compartment"].(string)
+		on, ok := v["vent"].(bool)
+		if id == "" || !ok {
+			return fmt.Errorf("invalid value for vent")
+		}
+		compartment, vent = id, on
+	default:
+		return fmt.Errorf("invalid value type for vent")
+	}
+
+	if err := playerShip.VentCompartment(compartment, vent); err != nil {
+		return err
+	}
+	log.Printf("Compartment %s vented: %v", compartment, vent)
 	return nil
 }
 
@@ -159,7 +361,25 @@ func (ar *ActionRouter) handleSetThrust(action *Action) error {
 		return fmt.Errorf("invalid thrust value")
 	}
 
-	playerShip.ApplyThrust(0, 0, thrust)
+	playerShip.SetThrottle(thrust)
+	return nil
+}
+
+func (ar *ActionRouter) handleSetStrafe(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	strafeData, ok := action.Value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid strafe value")
+	}
+
+	lateral, _ := strafeData["lateral"].(float64)
+	vertical, _ := strafeData["vertical"].(float64)
+
+	playerShip.SetStrafe(lateral, vertical)
 	return nil
 }
 
@@ -188,11 +408,127 @@ func (ar *ActionRouter) handleReleaseDocking(action *Action) error {
 		return fmt.Errorf("no player ship found")
 	}
 
-	playerShip.Docked = false
+	if err := ar.simulator.ReleaseDocking(playerShip.ID); err != nil {
+		return err
+	}
 	log.Println("Docking clamps released")
 	return nil
 }
 
+func (ar *ActionRouter) handleRequestDocking(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	var hostID, portID string
+	switch v := action.Value.(type) {
+	case string:
+		hostID = v
+	case map[string]interface{}:
+		hostID, _ = v["target_id"].(string)
+		portID, _ = v["port_id"].(string)
+	}
+	if hostID == "" {
+		return fmt.Errorf("invalid docking target")
+	}
+
+	return ar.simulator.RequestDocking(playerShip.ID, hostID, portID)
+}
+
+func (ar *ActionRouter) handleNavigateTo(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	navData, ok := action.Value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid navigation value")
+	}
+
+	targetID, _ := navData["target_id"].(string)
+	radius, _ := navData["radius"].(float64)
+	if err := playerShip.Engage(ship.FlightNavigate, targetID, pointFromValue(navData), radius); err != nil {
+		return err
+	}
+	log.Printf("Navigating to %v", navData)
+	return nil
+}
+
+func (ar *ActionRouter) handleClearNavigation(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	playerShip.Disengage()
+	return nil
+}
+
+// autopilotModes maps the flight station's mode selector to flight computer
+// modes.
+var autopilotModes = []string{ship.FlightHold, ship.FlightNavigate, ship.FlightIntercept, ship.FlightMatch}
+
+func (ar *ActionRouter) handleAutopilot(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	apData, ok := action.Value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid autopilot value")
+	}
+
+	if enabled, ok := apData["enabled"].(bool); ok && !enabled {
+		playerShip.Disengage()
+		return nil
+	}
+
+	var mode string
+	switch m := apData["mode"].(type) {
+	case string:
+		mode = m
+	case float64:
+		if int(m) < 0 || int(m) >= len(autopilotModes) {
+			return fmt.Errorf("invalid autopilot mode: %v", m)
+		}
+		mode = autopilotModes[int(m)]
+	default:
+		return fmt.Errorf("invalid autopilot mode")
+	}
+
+	current := playerShip.FlightState()
+	targetID, hasTarget := apData["target_id"].(string)
+	point := pointFromValue(apData)
+	_, hasPoint := apData["x"]
+	radius, _ := apData["radius"].(float64)
+
+	switch {
+	case hasTarget || hasPoint:
+	case mode == ship.FlightNavigate && current.Mode == ship.FlightNavigate:
+		targetID, point = current.TargetID, current.Point
+	case mode == ship.FlightNavigate:
+		return fmt.Errorf("no course plotted")
+	default:
+		targetID = playerShip.TargetID
+	}
+
+	if err := playerShip.Engage(mode, targetID, point, radius); err != nil {
+		return err
+	}
+	log.Printf("Autopilot engaged: %s (target: %s)", mode, targetID)
+	return nil
+}
+
+func pointFromValue(data map[string]interface{}) ship.Vector3 {
+	x, _ := data["x"].(float64)
+	y, _ := data["y"].(float64)
+	z, _ := data["z"].(float64)
+	return ship.Vector3{X: x, Y: y, Z: z}
+}
+
 func (ar *ActionRouter) handleArmTorpedo(action *Action) error {
 	playerShip := ar.getPlayerShip()
 	if playerShip == nil {
@@ -284,22 +620,6 @@ func (ar *ActionRouter) handleFireTorpedo(action *Action) error {
 	}
 
 	if playerShip.FireWeapon(weaponID, targetID) {
-		forward := playerShip.Position
-		velocity := playerShip.Velocity
-		velocity.X += 500
-		velocity.Y += 0
-		velocity.Z += 0
-
-		ar.simulator.SpawnProjectile(
-			fmt.Sprintf("torpedo_%s_%.0f", weaponID, ar.simulator.CurrentTime),
-			"torpedo",
-			playerShip.ID,
-			targetID,
-			forward,
-			velocity,
-			weapon.Damage,
-		)
-
 		log.Printf("Fired torpedo %s at target %s", weaponID, targetID)
 		return nil
 	}
@@ -307,6 +627,30 @@ func (ar *ActionRouter) handleFireTorpedo(action *Action) error {
 	return fmt.Errorf("failed to fire torpedo")
 }
 
+func (ar *ActionRouter) handleDeployDecoy(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	if weapon, ok := playerShip.Weapons[action.System]; ok && weapon.Type == "decoy" {
+		if !playerShip.FireWeapon(weapon.ID, "") {
+			return fmt.Errorf("decoy launcher %s not ready", weapon.ID)
+		}
+		log.Printf("Deployed decoy from %s", weapon.ID)
+		return nil
+	}
+
+	for id, weapon := range playerShip.Weapons {
+		if weapon.Type == "decoy" && playerShip.FireWeapon(id, "") {
+			log.Printf("Deployed decoy from %s", id)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no decoy launcher ready")
+}
+
 func (ar *ActionRouter) handleFirePhaser(action *Action) error {
 	playerShip := ar.getPlayerShip()
 	if playerShip == nil {
@@ -334,7 +678,11 @@ func (ar *ActionRouter) handleFirePhaser(action *Action) error {
 	}
 
 	if playerShip.FireWeapon(weaponID, targetID) {
-		target.TakeDamage(weapon.Damage, "forward")
+		target.TakeHit(ship.Hit{
+			Amount:    weapon.Damage,
+			Point:     playerShip.Position,
+			Frequency: weapon.Frequency,
+		})
 		log.Printf("Fired phaser %s at target %s for %.1f damage", weaponID, targetID, weapon.Damage)
 		return nil
 	}
@@ -398,8 +746,52 @@ func (ar *ActionRouter) handleSendMessage(action *Action) error {
 	return nil
 }
 
+// handleRoutePower sets a power group's allocation in percent, 0 to 300.
+// The value is either {"group": name, "level": percent} or a bare percent
+// for the group named by the action's system.
 func (ar *ActionRouter) handleRoutePower(action *Action) error {
-	log.Printf("Routing power to: %s", action.System)
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	group := action.System
+	var level float64
+	switch v := action.Value.(type) {
+	case float64:
+		level = v
+	case map[string]interface{}:
+		g, _ := v["group"].(string)
+		l, ok := v["level"].(float64)
+		if g == "" || !ok {
+			return fmt.Errorf("invalid value for route_power")
+		}
+		group, level = g, l
+	default:
+		return fmt.Errorf("invalid value type for route_power")
+	}
+
+	if err := playerShip.SetAllocation(group, level/100); err != nil {
+		return err
+	}
+	log.Printf("Routing %.0f%% power to: %s", level, group)
+	return nil
+}
+
+func (ar *ActionRouter) handlePowerPreset(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	preset, ok := action.Value.(string)
+	if !ok {
+		return fmt.Errorf("invalid power preset")
+	}
+	if err := playerShip.ApplyPowerPreset(preset); err != nil {
+		return err
+	}
+	log.Printf("Power preset set to: %s", preset)
 	return nil
 }
 
@@ -414,17 +806,111 @@ func (ar *ActionRouter) handleToggleShields(action *Action) error {
 		return fmt.Errorf("invalid shield toggle value")
 	}
 
-	playerShip.Shields.Enabled = enabled
+	playerShip.SetShieldsRaised(enabled)
 	log.Printf("Shields set to: %v", enabled)
 	return nil
 }
 
+func (ar *ActionRouter) handleRaiseShields(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	playerShip.SetShieldsRaised(true)
+	log.Printf("Shields raised")
+	return nil
+}
+
+func (ar *ActionRouter) handleLowerShields(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	playerShip.SetShieldsRaised(false)
+	log.Printf("Shields lowered")
+	return nil
+}
+
+func (ar *ActionRouter) handleSetShieldFrequency(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	var frequency float64
+	switch v := action.Value.(type) {
+	case float64:
+		frequency = v
+	case map[string]interface{}:
+		f, ok := v["frequency"].(float64)
+		if !ok {
+			return fmt.Errorf("invalid shield frequency")
+		}
+		frequency = f
+	default:
+		return fmt.Errorf("invalid shield frequency")
+	}
+
+	playerShip.SetShieldFrequency(frequency)
+	log.Printf("Shield frequency set to: %.0f", frequency)
+	return nil
+}
+
+func (ar *ActionRouter) handleRotateShieldFrequency(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	frequency := playerShip.RotateShieldFrequency()
+	log.Printf("Shield frequency rotated to: %.0f", frequency)
+	return nil
+}
+
+func (ar *ActionRouter) handleReinforceShields(action *Action) error {
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	var facing string
+	switch v := action.Value.(type) {
+	case string:
+		facing = v
+	case map[string]interface{}:
+		facing, _ = v["facing"].(string)
+	}
+	if facing == "" {
+		return fmt.Errorf("invalid shield facing")
+	}
+
+	if err := playerShip.ReinforceShields(facing); err != nil {
+		return err
+	}
+	log.Printf("Reinforcing %s shields", facing)
+	return nil
+}
+
 func (ar *ActionRouter) handleInitiateScan(action *Action) error {
 	targetID, ok := action.Value.(string)
 	if !ok {
 		return fmt.Errorf("invalid scan target")
 	}
 
+	playerShip := ar.getPlayerShip()
+	if playerShip == nil {
+		return fmt.Errorf("no player ship found")
+	}
+
+	if ar.simulator.GetShip(targetID) == nil {
+		return fmt.Errorf("scan target not found: %s", targetID)
+	}
+	if !ar.simulator.LineOfSight(playerShip.ID, targetID) {
+		return fmt.Errorf("scan of %s blocked: no line of sight", targetID)
+	}
+
 	log.Printf("Initiating scan of: %s", targetID)
 	return nil
 }
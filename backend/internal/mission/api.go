@@ -0,0 +1,177 @@
+package mission
+
+import (
+	"celestial/internal/ship"
+	"celestial/internal/view"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// registerWorldAPI adds the functions scripts use to query and control the
+// world. Ships come back as tables in the WebSocket ship schema.
+func (e *Engine) registerWorldAPI() {
+	e.L.SetGlobal("get_ship", e.L.NewFunction(e.luaGetShip))
+	e.L.SetGlobal("get_ships_in_range", e.L.NewFunction(e.luaGetShipsInRange))
+	e.L.SetGlobal("distance", e.L.NewFunction(e.luaDistance))
+	e.L.SetGlobal("get_time", e.L.NewFunction(e.luaGetTime))
+	e.L.SetGlobal("get_objective", e.L.NewFunction(e.luaGetObjective))
+	e.L.SetGlobal("set_ai_orders", e.L.NewFunction(e.luaSetAIOrders))
+	e.L.SetGlobal("set_ai_mode", e.L.NewFunction(e.luaSetAIMode))
+	e.L.SetGlobal("teleport", e.L.NewFunction(e.luaTeleport))
+	e.L.SetGlobal("move_ship", e.L.NewFunction(e.luaMoveShip))
+	e.L.SetGlobal("set_system", e.L.NewFunction(e.luaSetSystem))
+}
+
+// schemaToLua converts a value through its JSON form, so scripts see exactly
+// what clients do.
+func (e *Engine) schemaToLua(v interface{}) lua.LValue {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return lua.LNil
+	}
+	var plain interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return lua.LNil
+	}
+	return e.goToLua(plain)
+}
+
+// point resolves a script argument naming a place: a ship or object ID, or
+// an {x, y, z} table.
+func (e *Engine) point(v lua.LValue) (ship.Vector3, bool) {
+	if id, ok := v.(lua.LString); ok {
+		return e.simulator.Position(string(id))
+	}
+	if _, ok := v.(*lua.LTable); ok {
+		return luaVector(v), true
+	}
+	return ship.Vector3{}, false
+}
+
+// result returns whether a control function succeeded, logging why not.
+func result(L *lua.LState, name string, err error) int {
+	if err != nil {
+		log.Printf("Lua %s error: %v", name, err)
+	}
+	L.Push(lua.LBool(err == nil))
+	return 1
+}
+
+func (e *Engine) luaGetShip(L *lua.LState) int {
+	sh := e.simulator.GetShip(L.CheckString(1))
+	if sh == nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(e.schemaToLua(view.Ship(sh)))
+	return 1
+}
+
+// luaGetShipsInRange lists the ships within radius of a place, nearest
+// first, optionally only those of one faction.
+func (e *Engine) luaGetShipsInRange(L *lua.LState) int {
+	center, ok := e.point(L.Get(1))
+	radius := float64(L.CheckNumber(2))
+	faction := L.OptString(3, "")
+	table := L.NewTable()
+	if !ok {
+		L.Push(table)
+		return 1
+	}
+
+	ships := e.simulator.ShipsInRange(center, radius)
+	sort.Slice(ships, func(i, j int) bool {
+		return ships[i].Position.Sub(center).Length() < ships[j].Position.Sub(center).Length()
+	})
+	for _, sh := range ships {
+		if faction == "" || sh.Faction == faction {
+			table.Append(e.schemaToLua(view.Ship(sh)))
+		}
+	}
+	L.Push(table)
+	return 1
+}
+
+func (e *Engine) luaDistance(L *lua.LState) int {
+	a, okA := e.point(L.Get(1))
+	b, okB := e.point(L.Get(2))
+	if !okA || !okB {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(lua.LNumber(a.Sub(b).Length()))
+	return 1
+}
+
+func (e *Engine) luaGetTime(L *lua.LState) int {
+	L.Push(lua.LNumber(e.simulator.GetTime()))
+	return 1
+}
+
+func (e *Engine) luaGetObjective(L *lua.LState) int {
+	objID := L.CheckString(1)
+	if e.active != nil {
+		for _, obj := range e.active.Objectives {
+			if obj.ID == objID {
+				table := L.NewTable()
+				table.RawSetString("id", lua.LString(obj.ID))
+				table.RawSetString("description", lua.LString(obj.Description))
+				table.RawSetString("completed", lua.LBool(obj.Completed))
+				L.Push(table)
+				return 1
+			}
+		}
+	}
+	L.Push(lua.LNil)
+	return 1
+}
+
+// luaSetAIOrders orders an AI ship to patrol, or to engage, evade or
+// retreat from a target: set_ai_orders(ship_id, state, target_id).
+func (e *Engine) luaSetAIOrders(L *lua.LState) int {
+	err := e.simulator.SetAIOrders(L.CheckString(1), L.CheckString(2), L.OptString(3, ""))
+	return result(L, "set_ai_orders", err)
+}
+
+func (e *Engine) luaSetAIMode(L *lua.LState) int {
+	return result(L, "set_ai_mode", e.simulator.SetAITacticalMode(L.CheckString(1), L.CheckString(2)))
+}
+
+func (e *Engine) luaTeleport(L *lua.LState) int {
+	return result(L, "teleport", e.simulator.Teleport(L.CheckString(1), luaVector(L.CheckTable(2))))
+}
+
+// luaMoveShip flies a ship on autopilot to a position or another ship:
+// move_ship(ship_id, {x, y, z} or target_id, arrival_radius).
+func (e *Engine) luaMoveShip(L *lua.LState) int {
+	sh := e.simulator.GetShip(L.CheckString(1))
+	if sh == nil {
+		return result(L, "move_ship", fmt.Errorf("ship not found: %s", L.CheckString(1)))
+	}
+	radius := float64(L.OptNumber(3, 0))
+	if target, ok := L.Get(2).(lua.LString); ok {
+		if _, found := e.simulator.Position(string(target)); !found {
+			return result(L, "move_ship", fmt.Errorf("no ship or object %s", target))
+		}
+		return result(L, "move_ship", sh.Engage(ship.FlightNavigate, string(target), ship.Vector3{}, radius))
+	}
+	return result(L, "move_ship", sh.Engage(ship.FlightNavigate, "", luaVector(L.CheckTable(2)), radius))
+}
+
+// luaSetSystem sets a system property:
+// set_system(ship_id, system_type, system_id, property, value).
+func (e *Engine) luaSetSystem(L *lua.LState) int {
+	sh := e.simulator.GetShip(L.CheckString(1))
+	if sh == nil {
+		return result(L, "set_system", fmt.Errorf("ship not found: %s", L.CheckString(1)))
+	}
+	value := float64(lua.LVAsNumber(L.Get(5)))
+	if enabled, ok := L.Get(5).(lua.LBool); ok && bool(enabled) {
+		value = 1
+	}
+	return result(L, "set_system", sh.SetSystem(L.CheckString(2), L.CheckString(3), L.CheckString(4), value))
+}
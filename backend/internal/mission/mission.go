@@ -1,21 +1,32 @@
 package mission
 
 import (
+	"celestial/internal/events"
 	"celestial/internal/ship"
 	"celestial/internal/simulation"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
 
 	lua "github.com/yuin/gopher-lua"
 )
 
 type Engine struct {
-	simulator *simulation.Simulator
-	missions  map[string]*Mission
-	active    *Mission
-	L         *lua.LState
+	mu          sync.Mutex
+	simulator   *simulation.Simulator
+	missions    map[string]*Mission
+	active      *Mission
+	L           *lua.LState
+	baseGlobals map[string]bool
+	subscribed  map[string]bool
+
+	now       float64
+	timers    []*timer
+	nextTimer int
+	routines  []*routine
 }
 
 type Mission struct {
@@ -33,11 +44,52 @@ type Objective struct {
 	Completed   bool
 }
 
+// missionEvents are the events forwarded to the mission script's on_event
+// handler, along with everything the GM triggers and the types the script
+// asks for with subscribe_event.
+var missionEvents = map[string]bool{
+	events.Docked:        true,
+	events.Undocked:      true,
+	events.ShipDestroyed: true,
+	events.ShipDisabled:  true,
+	events.WreckSalvaged: true,
+
+	events.TriggerEnter:    true,
+	events.TriggerExit:     true,
+	events.TriggerDwell:    true,
+	events.WaypointReached: true,
+	events.AreaReached:     true,
+}
+
 func NewEngine(sim *simulation.Simulator) *Engine {
-	return &Engine{
+	e := &Engine{
 		simulator: sim,
 		missions:  make(map[string]*Mission),
 	}
+	sim.Events().Subscribe(func(event events.Event) {
+		if e.forwards(event) {
+			e.TriggerEvent(event.Type, event.Data)
+		}
+	})
+	sim.AddTickHook(e.update)
+	return e
+}
+
+func (e *Engine) forwards(event events.Event) bool {
+	if missionEvents[event.Type] || event.Source == events.SourceGM {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.subscribed[event.Type]
+}
+
+// publish puts a mission event on the simulator's bus.
+func (e *Engine) publish(eventType string, data map[string]interface{}) {
+	if e.active != nil {
+		data["mission_id"] = e.active.ID
+	}
+	e.simulator.Events().Publish(eventType, events.SourceMission, data)
 }
 
 func (e *Engine) LoadMissions(dir string) error {
@@ -73,38 +125,63 @@ func (e *Engine) LoadMissions(dir string) error {
 }
 
 func (e *Engine) StartMission(missionID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	mission, ok := e.missions[missionID]
 	if !ok {
 		return fmt.Errorf("mission not found: %s", missionID)
 	}
 
-	e.active = mission
-	e.L = lua.NewState()
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("Mission panic: %v", r)
 		}
 	}()
 
-	e.registerAPI()
-
-	if err := e.L.DoString(mission.Script); err != nil {
-		return fmt.Errorf("executing mission script: %w", err)
+	if err := e.loadScript(mission); err != nil {
+		return err
 	}
 
-	if err := e.L.CallByParam(lua.P{
-		Fn:      e.L.GetGlobal("on_start"),
-		NRet:    0,
-		Protect: true,
-	}); err != nil {
-		log.Printf("Mission on_start error: %v", err)
+	if fn, ok := e.L.GetGlobal("on_start").(*lua.LFunction); ok {
+		e.spawn(e.L, fn)
 	}
 
 	log.Printf("Started mission: %s", missionID)
+	e.publish(events.MissionStarted, map[string]interface{}{"name": mission.Name})
+	return nil
+}
+
+func (e *Engine) loadScript(mission *Mission) error {
+	if e.L != nil {
+		e.L.Close()
+	}
+
+	mission.Objectives = make([]Objective, 0)
+	e.active = mission
+	e.subscribed = make(map[string]bool)
+	e.resetScheduler()
+	e.L = lua.NewState()
+	e.registerAPI()
+
+	e.baseGlobals = make(map[string]bool)
+	e.L.G.Global.ForEach(func(k, _ lua.LValue) {
+		e.baseGlobals[k.String()] = true
+	})
+
+	if err := e.L.DoString(mission.Script); err != nil {
+		return fmt.Errorf("executing mission script: %w", err)
+	}
 	return nil
 }
 
 func (e *Engine) StopMission() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopMission()
+}
+
+func (e *Engine) stopMission() {
 	if e.active == nil {
 		return
 	}
@@ -113,32 +190,29 @@ func (e *Engine) StopMission() {
 		e.L.Close()
 		e.L = nil
 	}
+	e.timers, e.routines = nil, nil
 
 	log.Printf("Stopped mission: %s", e.active.ID)
+	e.publish(events.MissionStopped, map[string]interface{}{})
 	e.active = nil
 }
 
 func (e *Engine) TriggerEvent(eventName string, params map[string]interface{}) {
-	if e.active == nil || e.L == nil {
-		return
-	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	fn := e.L.GetGlobal("on_event")
-	if fn.Type() != lua.LTFunction {
+	if e.active == nil || e.L == nil {
 		return
 	}
 
-	e.L.Push(fn)
-	e.L.Push(lua.LString(eventName))
-
 	table := e.L.NewTable()
 	for k, v := range params {
 		e.L.SetField(table, k, e.goToLua(v))
 	}
-	e.L.Push(table)
+	e.wake(eventName, table)
 
-	if err := e.L.PCall(2, 0, nil); err != nil {
-		log.Printf("Event trigger error: %v", err)
+	if fn, ok := e.L.GetGlobal("on_event").(*lua.LFunction); ok {
+		e.spawn(e.L, fn, lua.LString(eventName), table)
 	}
 }
 
@@ -148,11 +222,19 @@ func (e *Engine) registerAPI() {
 	e.L.SetGlobal("spawn_object", e.L.NewFunction(e.luaSpawnObject))
 	e.L.SetGlobal("remove_object", e.L.NewFunction(e.luaRemoveObject))
 	e.L.SetGlobal("damage_ship", e.L.NewFunction(e.luaDamageShip))
+	e.L.SetGlobal("set_power_allocation", e.L.NewFunction(e.luaSetPowerAllocation))
+	e.L.SetGlobal("set_power_preset", e.L.NewFunction(e.luaSetPowerPreset))
 	e.L.SetGlobal("set_objective", e.L.NewFunction(e.luaSetObjective))
 	e.L.SetGlobal("complete_objective", e.L.NewFunction(e.luaCompleteObjective))
 	e.L.SetGlobal("mission_win", e.L.NewFunction(e.luaMissionWin))
 	e.L.SetGlobal("mission_lose", e.L.NewFunction(e.luaMissionLose))
+	e.L.SetGlobal("add_trigger", e.L.NewFunction(e.luaAddTrigger))
+	e.L.SetGlobal("remove_trigger", e.L.NewFunction(e.luaRemoveTrigger))
+	e.L.SetGlobal("set_faction", e.L.NewFunction(e.luaSetFaction))
+	e.L.SetGlobal("subscribe_event", e.L.NewFunction(e.luaSubscribeEvent))
 	e.L.SetGlobal("log", e.L.NewFunction(e.luaLog))
+	e.registerWorldAPI()
+	e.registerSchedulerAPI()
 }
 
 func (e *Engine) luaSpawnShip(L *lua.LState) int {
@@ -182,6 +264,70 @@ func (e *Engine) luaSpawnShip(L *lua.LState) int {
 	return 1
 }
 
+// luaAddTrigger adds a trigger volume: add_trigger(id, {shape="sphere",
+// position={x,y,z} or object=id, radius=r or extents={x,y,z}, ships={...},
+// factions={...}, dwell=seconds, ["repeat"]=true}).
+func (e *Engine) luaAddTrigger(L *lua.LState) int {
+	opts := L.CheckTable(2)
+	trigger := &simulation.Trigger{
+		ID:       L.CheckString(1),
+		Shape:    lua.LVAsString(opts.RawGetString("shape")),
+		Position: luaVector(opts.RawGetString("position")),
+		Radius:   float64(lua.LVAsNumber(opts.RawGetString("radius"))),
+		Extents:  luaVector(opts.RawGetString("extents")),
+		ObjectID: lua.LVAsString(opts.RawGetString("object")),
+		Ships:    luaStrings(opts.RawGetString("ships")),
+		Factions: luaStrings(opts.RawGetString("factions")),
+		Dwell:    float64(lua.LVAsNumber(opts.RawGetString("dwell"))),
+		Repeat:   lua.LVAsBool(opts.RawGetString("repeat")),
+	}
+	if err := e.simulator.AddTrigger(trigger); err != nil {
+		log.Printf("Lua add_trigger error: %v", err)
+		L.Push(lua.LBool(false))
+		return 1
+	}
+	L.Push(lua.LBool(true))
+	return 1
+}
+
+func (e *Engine) luaRemoveTrigger(L *lua.LState) int {
+	if err := e.simulator.RemoveTrigger(L.CheckString(1)); err != nil {
+		log.Printf("Lua remove_trigger error: %v", err)
+	}
+	return 0
+}
+
+func (e *Engine) luaSetFaction(L *lua.LState) int {
+	if err := e.simulator.SetFaction(L.CheckString(1), L.CheckString(2)); err != nil {
+		log.Printf("Lua set_faction error: %v", err)
+	}
+	return 0
+}
+
+func luaVector(v lua.LValue) ship.Vector3 {
+	table, ok := v.(*lua.LTable)
+	if !ok {
+		return ship.Vector3{}
+	}
+	return ship.Vector3{
+		X: float64(lua.LVAsNumber(table.RawGetString("x"))),
+		Y: float64(lua.LVAsNumber(table.RawGetString("y"))),
+		Z: float64(lua.LVAsNumber(table.RawGetString("z"))),
+	}
+}
+
+func luaStrings(v lua.LValue) []string {
+	table, ok := v.(*lua.LTable)
+	if !ok {
+		return nil
+	}
+	var list []string
+	table.ForEach(func(_, item lua.LValue) {
+		list = append(list, item.String())
+	})
+	return list
+}
+
 func (e *Engine) luaRemoveShip(L *lua.LState) int {
 	shipID := L.ToString(1)
 	e.simulator.RemoveShip(shipID)
@@ -204,6 +350,17 @@ func (e *Engine) luaSpawnObject(L *lua.LState) int {
 	}
 
 	e.simulator.SpawnObject(objectID, objectType, position)
+	if opts, ok := L.Get(4).(*lua.LTable); ok {
+		// Fields left out keep the defaults of the object type.
+		radius, mass, _ := e.simulator.ObjectBody(objectID)
+		if v, ok := opts.RawGetString("radius").(lua.LNumber); ok {
+			radius = float64(v)
+		}
+		if v, ok := opts.RawGetString("mass").(lua.LNumber); ok {
+			mass = float64(v)
+		}
+		e.simulator.SetObjectBody(objectID, radius, mass)
+	}
 	return 0
 }
 
@@ -226,6 +383,43 @@ func (e *Engine) luaDamageShip(L *lua.LState) int {
 	return 0
 }
 
+func (e *Engine) luaSetPowerAllocation(L *lua.LState) int {
+	shipID := L.ToString(1)
+	group := L.ToString(2)
+	percent := L.ToNumber(3)
+
+	sh := e.simulator.GetShip(shipID)
+	if sh == nil {
+		L.Push(lua.LBool(false))
+		return 1
+	}
+	if err := sh.SetAllocation(group, float64(percent)/100); err != nil {
+		log.Printf("Lua set_power_allocation error: %v", err)
+		L.Push(lua.LBool(false))
+		return 1
+	}
+	L.Push(lua.LBool(true))
+	return 1
+}
+
+func (e *Engine) luaSetPowerPreset(L *lua.LState) int {
+	shipID := L.ToString(1)
+	preset := L.ToString(2)
+
+	sh := e.simulator.GetShip(shipID)
+	if sh == nil {
+		L.Push(lua.LBool(false))
+		return 1
+	}
+	if err := sh.ApplyPowerPreset(preset); err != nil {
+		log.Printf("Lua set_power_preset error: %v", err)
+		L.Push(lua.LBool(false))
+		return 1
+	}
+	L.Push(lua.LBool(true))
+	return 1
+}
+
 func (e *Engine) luaSetObjective(L *lua.LState) int {
 	objID := L.ToString(1)
 	description := L.ToString(2)
@@ -237,6 +431,7 @@ func (e *Engine) luaSetObjective(L *lua.LState) int {
 			Completed:   false,
 		})
 		log.Printf("Objective set: %s - %s", objID, description)
+		e.publish(events.ObjectiveSet, map[string]interface{}{"objective_id": objID, "description": description})
 	}
 
 	return 0
@@ -250,6 +445,10 @@ func (e *Engine) luaCompleteObjective(L *lua.LState) int {
 			if e.active.Objectives[i].ID == objID {
 				e.active.Objectives[i].Completed = true
 				log.Printf("Objective completed: %s", objID)
+				e.publish(events.ObjectiveCompleted, map[string]interface{}{
+					"objective_id": objID,
+					"description":  e.active.Objectives[i].Description,
+				})
 				break
 			}
 		}
@@ -260,12 +459,23 @@ func (e *Engine) luaCompleteObjective(L *lua.LState) int {
 
 func (e *Engine) luaMissionWin(L *lua.LState) int {
 	log.Println("Mission completed successfully!")
+	e.publish(events.MissionWon, map[string]interface{}{})
 	return 0
 }
 
 func (e *Engine) luaMissionLose(L *lua.LState) int {
 	reason := L.ToString(1)
 	log.Printf("Mission failed: %s", reason)
+	e.publish(events.MissionLost, map[string]interface{}{"reason": reason})
+	return 0
+}
+
+// luaSubscribeEvent forwards more event types to on_event, such as
+// hull_breach or ai_state_changed.
+func (e *Engine) luaSubscribeEvent(L *lua.LState) int {
+	for i := 1; i <= L.GetTop(); i++ {
+		e.subscribed[L.CheckString(i)] = true
+	}
 	return 0
 }
 
@@ -285,12 +495,193 @@ func (e *Engine) goToLua(v interface{}) lua.LValue {
 		return lua.LNumber(val)
 	case bool:
 		return lua.LBool(val)
+	case []interface{}:
+		table := e.L.NewTable()
+		for _, item := range val {
+			table.Append(e.goToLua(item))
+		}
+		return table
+	case map[string]interface{}:
+		table := e.L.NewTable()
+		for k, item := range val {
+			e.L.SetField(table, k, e.goToLua(item))
+		}
+		return table
 	default:
 		return lua.LNil
 	}
 }
 
+// SaveMissionState captures the script's data: objectives, plain globals,
+// the file-level locals its functions close over and its pending timers.
+func (e *Engine) SaveMissionState() *simulation.MissionState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.active == nil || e.L == nil {
+		return nil
+	}
+
+	state := &simulation.MissionState{
+		MissionID:  e.active.ID,
+		Objectives: make([]simulation.ObjectiveState, 0, len(e.active.Objectives)),
+		Variables:  make(map[string]interface{}),
+	}
+
+	for _, obj := range e.active.Objectives {
+		state.Objectives = append(state.Objectives, simulation.ObjectiveState{
+			ID:          obj.ID,
+			Description: obj.Description,
+			Completed:   obj.Completed,
+		})
+	}
+
+	globals := make(map[string]interface{})
+	upvalues := make(map[string]interface{})
+	e.L.G.Global.ForEach(func(k, v lua.LValue) {
+		name := k.String()
+		if e.baseGlobals[name] {
+			return
+		}
+		if fn, ok := v.(*lua.LFunction); ok {
+			if fn.Proto == nil {
+				return
+			}
+			for i, uv := range fn.Upvalues {
+				if i >= len(fn.Proto.DbgUpvalues) || uv == nil {
+					continue
+				}
+				if val, ok := e.luaToGo(uv.Value()); ok {
+					upvalues[fn.Proto.DbgUpvalues[i]] = val
+				}
+			}
+			return
+		}
+		if val, ok := e.luaToGo(v); ok {
+			globals[name] = val
+		}
+	})
+
+	types := make([]string, 0, len(e.subscribed))
+	for eventType := range e.subscribed {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	subscribed := make([]interface{}, len(types))
+	for i, eventType := range types {
+		subscribed[i] = eventType
+	}
+
+	state.Variables["globals"] = globals
+	state.Variables["upvalues"] = upvalues
+	state.Variables["subscribed"] = subscribed
+	state.Variables["timers"], state.Dropped = e.saveSchedule()
+	return state
+}
+
+func (e *Engine) RestoreMissionState(state *simulation.MissionState) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if state == nil || state.MissionID == "" {
+		e.stopMission()
+		return nil
+	}
+
+	mission, ok := e.missions[state.MissionID]
+	if !ok {
+		return fmt.Errorf("mission not found: %s", state.MissionID)
+	}
+
+	if err := e.loadScript(mission); err != nil {
+		return err
+	}
+
+	for _, obj := range state.Objectives {
+		mission.Objectives = append(mission.Objectives, Objective{
+			ID:          obj.ID,
+			Description: obj.Description,
+			Completed:   obj.Completed,
+		})
+	}
+
+	if subscribed, ok := state.Variables["subscribed"].([]interface{}); ok {
+		for _, eventType := range subscribed {
+			if name, ok := eventType.(string); ok {
+				e.subscribed[name] = true
+			}
+		}
+	}
+
+	if globals, ok := state.Variables["globals"].(map[string]interface{}); ok {
+		for name, val := range globals {
+			e.L.SetGlobal(name, e.goToLua(val))
+		}
+	}
+
+	if upvalues, ok := state.Variables["upvalues"].(map[string]interface{}); ok {
+		e.L.G.Global.ForEach(func(_, v lua.LValue) {
+			fn, ok := v.(*lua.LFunction)
+			if !ok || fn.Proto == nil {
+				return
+			}
+			for i, uv := range fn.Upvalues {
+				if i >= len(fn.Proto.DbgUpvalues) || uv == nil {
+					continue
+				}
+				if val, ok := upvalues[fn.Proto.DbgUpvalues[i]]; ok {
+					uv.SetValue(e.goToLua(val))
+				}
+			}
+		})
+	}
+
+	if timers, ok := state.Variables["timers"].([]interface{}); ok {
+		e.restoreSchedule(timers)
+	}
+
+	log.Printf("Restored mission state: %s", state.MissionID)
+	return nil
+}
+
+func (e *Engine) luaToGo(v lua.LValue) (interface{}, bool) {
+	switch val := v.(type) {
+	case lua.LString:
+		return string(val), true
+	case lua.LNumber:
+		return float64(val), true
+	case lua.LBool:
+		return bool(val), true
+	case *lua.LTable:
+		n := val.MaxN()
+		isArray := n > 0
+		fields := make(map[string]interface{})
+		val.ForEach(func(k, item lua.LValue) {
+			converted, ok := e.luaToGo(item)
+			if !ok {
+				return
+			}
+			if num, isNum := k.(lua.LNumber); !isNum || int(num) < 1 || int(num) > n {
+				isArray = false
+			}
+			fields[k.String()] = converted
+		})
+		if isArray && len(fields) == n {
+			items := make([]interface{}, n)
+			for i := 1; i <= n; i++ {
+				items[i-1] = fields[fmt.Sprintf("%d", i)]
+			}
+			return items, true
+		}
+		return fields, true
+	default:
+		return nil, false
+	}
+}
+
 func (e *Engine) GetActiveMission() *Mission {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.active
 }
 
@@ -0,0 +1,272 @@
+package mission
+
+import (
+	"celestial/internal/config"
+	"celestial/internal/simulation"
+	"math"
+	"testing"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+func startScript(t *testing.T, sim *simulation.Simulator, script string) *Engine {
+	t.Helper()
+	e := NewEngine(sim)
+	e.missions["test"] = &Mission{ID: "test", Name: "Test", Script: script, State: make(map[string]interface{})}
+	if err := e.StartMission("test"); err != nil {
+		t.Fatalf("Failed to start mission: %v", err)
+	}
+	return e
+}
+
+func TestSpawnObjectBody(t *testing.T) {
+	sim := simulation.NewSimulator(60, make(map[string]*config.ShipClass))
+	startScript(t, sim, `
+		function on_start()
+			spawn_object("big", "asteroid", {x=0, y=0, z=0}, {radius=80})
+			spawn_object("light", "asteroid", {x=500, y=0, z=0}, {mass=1000})
+			spawn_object("anchor", "asteroid", {x=1000, y=0, z=0}, {mass=0})
+		end
+	`)
+
+	if radius, mass, _ := sim.ObjectBody("big"); radius != 80 || mass != 5e6 {
+		t.Errorf("Expected radius 80 with the default mass, got %f and %f", radius, mass)
+	}
+	if radius, mass, _ := sim.ObjectBody("light"); radius != 40 || mass != 1000 {
+		t.Errorf("Expected mass 1000 with the default radius, got %f and %f", radius, mass)
+	}
+	if _, mass, _ := sim.ObjectBody("anchor"); mass != 0 {
+		t.Errorf("An explicit mass of 0 should make the object immovable, got %f", mass)
+	}
+}
+
+func TestWorldAPI(t *testing.T) {
+	sim := simulation.NewSimulator(60, map[string]*config.ShipClass{
+		"scout": {
+			ID:       "scout",
+			Name:     "Scout",
+			Mass:     50000,
+			MaxSpeed: 200,
+			Engines:  []config.EngineConfig{{ID: "main_1", Type: "main", Thrust: 50000, Health: 100}},
+		},
+	})
+	e := startScript(t, sim, `
+		function on_start()
+			set_objective("find", "Find the scout")
+			spawn_ship("scout", "scout", "Scout", false, {x=0, y=0, z=0})
+			spawn_ship("far", "scout", "Far", false, {x=9000, y=0, z=0})
+			teleport("far", {x=300, y=0, z=400})
+
+			local sh = get_ship("scout")
+			scout_id = sh.id
+			scout_x = sh.position.x
+			gap = distance("scout", "far")
+			in_range = #get_ships_in_range("scout", 1000)
+			objective = get_objective("find")
+			disabled = set_system("scout", "engine", "main_1", "enabled", false)
+			moved = move_ship("scout", "nowhere")
+		end
+	`)
+
+	if e.L.GetGlobal("scout_id").String() != "scout" || e.L.GetGlobal("scout_x") != lua.LNumber(0) {
+		t.Error("get_ship should return the ship's ID and position")
+	}
+	if gap := e.L.GetGlobal("gap"); gap != lua.LNumber(500) {
+		t.Errorf("Expected a distance of 500, got %v", gap)
+	}
+	if n := e.L.GetGlobal("in_range"); n != lua.LNumber(2) {
+		t.Errorf("get_ships_in_range should see ships spawned and moved this tick, got %v", n)
+	}
+	objective, ok := e.L.GetGlobal("objective").(*lua.LTable)
+	if !ok || objective.RawGetString("description").String() != "Find the scout" || objective.RawGetString("completed") != lua.LFalse {
+		t.Errorf("Expected the open objective, got %v", e.L.GetGlobal("objective"))
+	}
+	if e.L.GetGlobal("disabled") != lua.LTrue || sim.GetShip("scout").Engines["main_1"].Enabled {
+		t.Error("set_system should disable an engine given false")
+	}
+	if e.L.GetGlobal("moved") != lua.LFalse {
+		t.Error("move_ship should fail for an unknown target")
+	}
+}
+
+func tick(sim *simulation.Simulator, n int) {
+	for i := 0; i < n; i++ {
+		sim.Tick()
+	}
+}
+
+func number(e *Engine, name string) float64 {
+	return float64(lua.LVAsNumber(e.L.GetGlobal(name)))
+}
+
+func TestTimers(t *testing.T) {
+	sim := simulation.NewSimulator(10, make(map[string]*config.ShipClass))
+	e := startScript(t, sim, `
+		once, repeats, cancelled = 0, 0, 0
+		function on_start()
+			after(1, function() once = once + 1 end)
+			every(0.5, function() repeats = repeats + 1 end)
+			local id = every(0.2, function() cancelled = cancelled + 1 end)
+			after(0.5, function() cancel(id) end)
+		end
+	`)
+
+	tick(sim, 9)
+	if number(e, "once") != 0 || number(e, "repeats") != 1 {
+		t.Errorf("Expected only the first repeat by 0.9s, got %v once and %v repeats", number(e, "once"), number(e, "repeats"))
+	}
+	tick(sim, 13)
+	if number(e, "once") != 1 {
+		t.Errorf("after should fire exactly once, got %v", number(e, "once"))
+	}
+	if number(e, "repeats") != 4 {
+		t.Errorf("Expected every(0.5) to fire 4 times in 2.2s, got %v", number(e, "repeats"))
+	}
+	if number(e, "cancelled") != 2 {
+		t.Errorf("A cancelled timer should stop firing, got %v runs", number(e, "cancelled"))
+	}
+}
+
+func TestWait(t *testing.T) {
+	sim := simulation.NewSimulator(10, make(map[string]*config.ShipClass))
+	e := startScript(t, sim, `
+		function on_start()
+			wait(1)
+			woke_at = get_time()
+		end
+	`)
+
+	tick(sim, 9)
+	if e.L.GetGlobal("woke_at") != lua.LNil {
+		t.Fatal("wait should not resume before its time")
+	}
+	tick(sim, 1)
+	if woke := number(e, "woke_at"); math.Abs(woke-1) > 1e-9 {
+		t.Errorf("Expected wait(1) to resume at 1s, got %v", woke)
+	}
+}
+
+func TestWaitForEvent(t *testing.T) {
+	sim := simulation.NewSimulator(10, make(map[string]*config.ShipClass))
+	e := startScript(t, sim, `
+		function on_start()
+			local params = wait_for_event("docked")
+			port = params.port
+		end
+	`)
+
+	e.TriggerEvent("undocked", map[string]interface{}{"port": "wrong"})
+	if e.L.GetGlobal("port") != lua.LNil {
+		t.Fatal("wait_for_event should ignore other events")
+	}
+	e.TriggerEvent("docked", map[string]interface{}{"port": "alpha"})
+	if port := e.L.GetGlobal("port").String(); port != "alpha" {
+		t.Errorf("Expected the event's port, got %s", port)
+	}
+}
+
+func TestOnUpdate(t *testing.T) {
+	sim := simulation.NewSimulator(10, make(map[string]*config.ShipClass))
+	e := startScript(t, sim, `
+		updates, total = 0, 0
+		function on_update(dt)
+			updates = updates + 1
+			total = total + dt
+		end
+	`)
+
+	tick(sim, 5)
+	if number(e, "updates") != 5 {
+		t.Errorf("Expected on_update every tick, got %v calls", number(e, "updates"))
+	}
+	if total := number(e, "total"); math.Abs(total-0.5) > 1e-9 {
+		t.Errorf("Expected dt to add up to 0.5s, got %v", total)
+	}
+}
+
+func TestMissionPaused(t *testing.T) {
+	sim := simulation.NewSimulator(60, make(map[string]*config.ShipClass))
+	e := startScript(t, sim, `
+		updates = 0
+		function on_update(dt) updates = updates + 1 end
+		function on_start()
+			after(0.05, function() fired = true end)
+		end
+	`)
+
+	go sim.Start()
+	defer sim.Stop()
+	sim.Pause()
+	e.mu.Lock()
+	before := e.L.GetGlobal("updates")
+	e.mu.Unlock()
+	time.Sleep(100 * time.Millisecond)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.L.GetGlobal("updates") != before {
+		t.Error("on_update should not run while paused")
+	}
+	if e.L.GetGlobal("fired") != lua.LNil {
+		t.Error("Timers should not fire while paused")
+	}
+}
+
+func TestTopLevelWait(t *testing.T) {
+	sim := simulation.NewSimulator(10, make(map[string]*config.ShipClass))
+	e := startScript(t, sim, `
+		ok, err = pcall(wait, 1)
+	`)
+
+	if e.L.GetGlobal("ok") != lua.LFalse {
+		t.Error("wait at the top level of a script should raise an error")
+	}
+}
+
+func TestTimersSurviveSnapshot(t *testing.T) {
+	sim := simulation.NewSimulator(10, make(map[string]*config.ShipClass))
+	e := startScript(t, sim, `
+		ticks = 0
+		function count() ticks = ticks + 1 end
+		function on_start()
+			every(0.5, count)
+		end
+	`)
+	sim.SetMissionStateProvider(e)
+
+	tick(sim, 6)
+	snapshot := sim.CaptureSnapshot()
+	if dropped := snapshot.Info().MissionDropped; len(dropped) != 0 {
+		t.Errorf("Expected nothing dropped, got %v", dropped)
+	}
+	tick(sim, 6)
+	if err := sim.RestoreFromSnapshot(snapshot); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if number(e, "ticks") != 1 {
+		t.Fatalf("Expected the restored count of 1, got %v", number(e, "ticks"))
+	}
+
+	tick(sim, 5)
+	if number(e, "ticks") != 2 {
+		t.Errorf("Expected the restored timer to fire at 1s, got %v runs", number(e, "ticks"))
+	}
+}
+
+func TestSnapshotReportsDroppedSchedule(t *testing.T) {
+	sim := simulation.NewSimulator(10, make(map[string]*config.ShipClass))
+	e := startScript(t, sim, `
+		function on_start()
+			after(5, function() end)
+			wait(10)
+		end
+	`)
+	sim.SetMissionStateProvider(e)
+
+	tick(sim, 1)
+	dropped := sim.CaptureSnapshot().Info().MissionDropped
+	if len(dropped) != 2 || dropped[0] != "1 timers without a global function" || dropped[1] != "1 waiting coroutines" {
+		t.Errorf("Expected the anonymous timer and the waiting coroutine reported, got %v", dropped)
+	}
+}
@@ -0,0 +1,276 @@
+package mission
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// timer runs fn at simulation time due, and again every interval seconds
+// if interval is set.
+type timer struct {
+	id       int
+	due      float64
+	interval float64
+	fn       *lua.LFunction
+}
+
+// routine is a script coroutine waiting until simulation time due or for
+// the next event named event.
+type routine struct {
+	thread *lua.LState
+	fn     *lua.LFunction
+	due    float64
+	event  string
+}
+
+func (e *Engine) registerSchedulerAPI() {
+	e.L.SetGlobal("after", e.L.NewFunction(e.luaAfter))
+	e.L.SetGlobal("every", e.L.NewFunction(e.luaEvery))
+	e.L.SetGlobal("cancel", e.L.NewFunction(e.luaCancel))
+	e.L.SetGlobal("sequence", e.L.NewFunction(e.luaSequence))
+	e.L.SetGlobal("wait", e.L.NewFunction(e.luaWait))
+	e.L.SetGlobal("wait_for_event", e.L.NewFunction(e.luaWaitForEvent))
+}
+
+// resetScheduler drops the timers and coroutines of the previous script.
+func (e *Engine) resetScheduler() {
+	e.timers = nil
+	e.routines = nil
+	e.now = e.simulator.GetTime()
+}
+
+// saveSchedule lists the pending timers for a snapshot. Only timers that
+// call a global function can be saved, by its name; the rest, and coroutines
+// partway through, are lost and described in dropped.
+func (e *Engine) saveSchedule() (saved []interface{}, dropped []string) {
+	names := make(map[*lua.LFunction]string)
+	e.L.G.Global.ForEach(func(k, v lua.LValue) {
+		if fn, ok := v.(*lua.LFunction); ok && !e.baseGlobals[k.String()] {
+			names[fn] = k.String()
+		}
+	})
+
+	saved = make([]interface{}, 0, len(e.timers))
+	lost := 0
+	for _, t := range e.timers {
+		name, ok := names[t.fn]
+		if !ok {
+			lost++
+			continue
+		}
+		saved = append(saved, map[string]interface{}{
+			"id":       float64(t.id),
+			"due":      t.due,
+			"interval": t.interval,
+			"function": name,
+		})
+	}
+	if lost > 0 {
+		dropped = append(dropped, fmt.Sprintf("%d timers without a global function", lost))
+	}
+	if len(e.routines) > 0 {
+		dropped = append(dropped, fmt.Sprintf("%d waiting coroutines", len(e.routines)))
+	}
+	if len(dropped) > 0 {
+		log.Printf("Mission snapshot cannot keep %s", strings.Join(dropped, " or "))
+	}
+	return saved, dropped
+}
+
+// restoreSchedule recreates the timers saved by saveSchedule.
+func (e *Engine) restoreSchedule(saved []interface{}) {
+	for _, item := range saved {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := fields["function"].(string)
+		fn, ok := e.L.GetGlobal(name).(*lua.LFunction)
+		if !ok {
+			log.Printf("Mission timer function %s no longer exists", name)
+			continue
+		}
+		id, _ := fields["id"].(float64)
+		due, _ := fields["due"].(float64)
+		interval, _ := fields["interval"].(float64)
+		e.timers = append(e.timers, &timer{id: int(id), due: due, interval: interval, fn: fn})
+		if int(id) > e.nextTimer {
+			e.nextTimer = int(id)
+		}
+	}
+}
+
+// spawn runs fn as a coroutine of L, so it may wait.
+func (e *Engine) spawn(L *lua.LState, fn *lua.LFunction, args ...lua.LValue) {
+	thread, _ := L.NewThread()
+	e.resume(L, &routine{thread: thread, fn: fn}, args...)
+}
+
+// resume runs a coroutine from L until it finishes or waits again.
+func (e *Engine) resume(L *lua.LState, r *routine, args ...lua.LValue) {
+	state, err, values := L.Resume(r.thread, r.fn, args...)
+	if err != nil {
+		log.Printf("Mission script error: %v", err)
+		return
+	}
+	if state != lua.ResumeYield || len(values) < 2 {
+		return
+	}
+
+	r.due, r.event = 0, ""
+	if values[0].String() == "wait" {
+		r.due = e.now + float64(lua.LVAsNumber(values[1]))
+	} else {
+		r.event = values[1].String()
+	}
+	e.routines = append(e.routines, r)
+}
+
+// update runs the timers and coroutines that have come due and then the
+// script's on_update. It is a simulator tick hook, so the mission stands
+// still while the simulator is paused.
+func (e *Engine) update(now float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.active == nil || e.L == nil {
+		return
+	}
+	dt := now - e.now
+	e.now = now
+	if dt < 0 {
+		dt = 0
+	}
+
+	var due []*timer
+	kept := e.timers[:0]
+	for _, t := range e.timers {
+		if t.due <= now {
+			due = append(due, t)
+			if t.interval <= 0 {
+				continue
+			}
+			t.due += t.interval
+			if t.due <= now {
+				t.due = now + t.interval
+			}
+		}
+		kept = append(kept, t)
+	}
+	e.timers = kept
+	sort.SliceStable(due, func(i, j int) bool { return due[i].due < due[j].due })
+	for _, t := range due {
+		e.spawn(e.L, t.fn)
+	}
+
+	var ready []*routine
+	waiting := e.routines[:0]
+	for _, r := range e.routines {
+		if r.event == "" && r.due <= now {
+			ready = append(ready, r)
+		} else {
+			waiting = append(waiting, r)
+		}
+	}
+	e.routines = waiting
+	for _, r := range ready {
+		e.resume(e.L, r)
+	}
+
+	if fn, ok := e.L.GetGlobal("on_update").(*lua.LFunction); ok {
+		if err := e.L.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}, lua.LNumber(dt)); err != nil {
+			log.Printf("Mission on_update error: %v", err)
+		}
+	}
+}
+
+// wake resumes the coroutines waiting for an event, passing them its
+// parameters.
+func (e *Engine) wake(eventName string, params lua.LValue) {
+	var ready []*routine
+	waiting := e.routines[:0]
+	for _, r := range e.routines {
+		if r.event == eventName {
+			ready = append(ready, r)
+		} else {
+			waiting = append(waiting, r)
+		}
+	}
+	e.routines = waiting
+	for _, r := range ready {
+		e.resume(e.L, r, params)
+	}
+}
+
+func (e *Engine) addTimer(delay, interval float64, fn *lua.LFunction) int {
+	e.nextTimer++
+	e.timers = append(e.timers, &timer{id: e.nextTimer, due: e.now + delay, interval: interval, fn: fn})
+	return e.nextTimer
+}
+
+// luaAfter runs a function once, seconds from now: after(seconds, fn). It
+// returns an ID for cancel.
+func (e *Engine) luaAfter(L *lua.LState) int {
+	id := e.addTimer(float64(L.CheckNumber(1)), 0, L.CheckFunction(2))
+	L.Push(lua.LNumber(id))
+	return 1
+}
+
+// luaEvery runs a function every so many seconds: every(seconds, fn).
+func (e *Engine) luaEvery(L *lua.LState) int {
+	interval := float64(L.CheckNumber(1))
+	if interval <= 0 {
+		L.ArgError(1, "interval must be positive")
+	}
+	id := e.addTimer(interval, interval, L.CheckFunction(2))
+	L.Push(lua.LNumber(id))
+	return 1
+}
+
+func (e *Engine) luaCancel(L *lua.LState) int {
+	id := L.CheckInt(1)
+	for i, t := range e.timers {
+		if t.id == id {
+			e.timers = append(e.timers[:i], e.timers[i+1:]...)
+			break
+		}
+	}
+	return 0
+}
+
+// luaSequence starts a function as a coroutine that may wait, without
+// holding up the caller.
+func (e *Engine) luaSequence(L *lua.LState) int {
+	fn := L.CheckFunction(1)
+	args := make([]lua.LValue, 0, L.GetTop()-1)
+	for i := 2; i <= L.GetTop(); i++ {
+		args = append(args, L.Get(i))
+	}
+	e.spawn(L, fn, args...)
+	return 0
+}
+
+// luaWait pauses the calling coroutine for some seconds of simulation time.
+func (e *Engine) luaWait(L *lua.LState) int {
+	seconds := L.CheckNumber(1)
+	if L.Parent == nil {
+		L.RaiseError("wait can only be used in on_start, on_event, timers and sequences")
+	}
+	return L.Yield(lua.LString("wait"), seconds)
+}
+
+// luaWaitForEvent pauses the calling coroutine until an event arrives and
+// returns its parameters. The mission receives that event type from then
+// on.
+func (e *Engine) luaWaitForEvent(L *lua.LState) int {
+	name := L.CheckString(1)
+	if L.Parent == nil {
+		L.RaiseError("wait_for_event can only be used in on_start, on_event, timers and sequences")
+	}
+	e.subscribed[name] = true
+	return L.Yield(lua.LString("event"), lua.LString(name))
+}
@@ -0,0 +1,91 @@
+package network
+
+import (
+	"celestial/internal/recording"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// NewReplayServer serves a recorded session to the normal frontends. The GM
+// transport commands drive the player instead of a live simulator.
+func NewReplayServer(port int, player *recording.Player) *WebSocketServer {
+	return &WebSocketServer{
+		port:    port,
+		replay:  player,
+		clients: make(map[*Client]bool),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (ws *WebSocketServer) handleReplayCommand(client *Client, payload map[string]interface{}) {
+	command, _ := payload["command"].(string)
+
+	switch command {
+	case "pause":
+		ws.replay.Pause()
+	case "resume", "play":
+		ws.replay.Play()
+	case "set_speed", "set_time_scale":
+		speed, ok := payload["speed"].(float64)
+		if !ok {
+			speed, ok = payload["scale"].(float64)
+		}
+		if !ok {
+			log.Printf("Ignoring %s without a numeric speed", command)
+			return
+		}
+		ws.replay.SetSpeed(speed)
+	case "seek":
+		t, _ := payload["time"].(float64)
+		ws.replay.Seek(t)
+		ws.broadcastFullState()
+	case "list_inputs":
+		from, _ := payload["from"].(float64)
+		to, ok := payload["to"].(float64)
+		if !ok {
+			to = ws.replay.Duration()
+		}
+		ws.sendMessage(client, Message{
+			Type: "replay_inputs",
+			Payload: map[string]interface{}{
+				"inputs": ws.replay.Inputs(from, to),
+			},
+		})
+	case "list_events":
+		from, _ := payload["from"].(float64)
+		to, ok := payload["to"].(float64)
+		if !ok {
+			to = ws.replay.Duration()
+		}
+		ws.sendMessage(client, Message{
+			Type: "replay_events",
+			Payload: map[string]interface{}{
+				"events": ws.replay.Events(from, to),
+			},
+		})
+	}
+}
+
+func (ws *WebSocketServer) buildReplayStateMessage() Message {
+	payload := map[string]interface{}{
+		"time":       ws.replay.Position(),
+		"paused":     !ws.replay.IsPlaying(),
+		"time_scale": ws.replay.Speed(),
+		"ships":      ws.replay.Ships(),
+		"replay": map[string]interface{}{
+			"start":    ws.replay.Start(),
+			"duration": ws.replay.Duration(),
+		},
+	}
+	for category, items := range ws.replay.World() {
+		payload[category] = items
+	}
+	return Message{Type: "state_update", Payload: payload}
+}
@@ -3,6 +3,7 @@ package network
 import (
 	"bufio"
 	"celestial/internal/config"
+	"celestial/internal/events"
 	"celestial/internal/input"
 	"celestial/internal/panel"
 	"celestial/internal/simulation"
@@ -24,6 +25,7 @@ type TCPServer struct {
 	stopChan          chan struct{}
 	actionRouter      *input.ActionRouter
 	panelStateManager *panel.PanelStateManager
+	events            chan events.Event
 }
 
 type PanelConnection struct {
@@ -37,16 +39,28 @@ type PanelMessage struct {
 	Value   interface{} `json:"value"`
 }
 
-func NewTCPServer(port int, sim *simulation.Simulator, mappings *config.PanelMapping) *TCPServer {
-	return &TCPServer{
+// panelEventBuffer is how many events may wait for the panel writer before
+// new ones are dropped, so a slow panel never stalls the simulation.
+const panelEventBuffer = 256
+
+func NewTCPServer(port int, sim *simulation.Simulator, mappings *config.PanelMapping, router *input.ActionRouter) *TCPServer {
+	ts := &TCPServer{
 		port:              port,
 		simulator:         sim,
 		panelMappings:     mappings,
 		connections:       make(map[string]*PanelConnection),
 		stopChan:          make(chan struct{}),
-		actionRouter:      input.NewActionRouter(sim),
+		actionRouter:      router,
 		panelStateManager: panel.NewPanelStateManager(),
+		events:            make(chan events.Event, panelEventBuffer),
 	}
+	sim.Events().Subscribe(func(event events.Event) {
+		select {
+		case ts.events <- event:
+		default:
+		}
+	})
+	return ts
 }
 
 func (ts *TCPServer) Start() {
@@ -199,6 +213,8 @@ func (ts *TCPServer) broadcastPanelStates() {
 		select {
 		case <-ts.stopChan:
 			return
+		case event := <-ts.events:
+			ts.sendEvent(event)
 		case <-ticker.C:
 			ships := ts.simulator.GetAllShips()
 			for _, sh := range ships {
@@ -212,7 +228,7 @@ func (ts *TCPServer) broadcastPanelStates() {
 						continue
 					}
 
-					state := ts.panelStateManager.UpdateFromShip(panelConn.panelID, sh, ts.simulator.CurrentTime)
+					state := ts.panelStateManager.UpdateFromShip(panelConn.panelID, sh, ts.simulator.GetTime())
 					ts.sendPanelState(panelConn.conn, state)
 				}
 				ts.mu.RUnlock()
@@ -235,3 +251,29 @@ func (ts *TCPServer) sendPanelState(conn net.Conn, state *panel.PanelState) {
 	data = append(data, '\n')
 	conn.Write(data)
 }
+
+// sendEvent passes an event to the panels of the stations it concerns.
+func (ts *TCPServer) sendEvent(event events.Event) {
+	data, err := json.Marshal(map[string]interface{}{
+		"type":   "event",
+		"event":  event.Type,
+		"time":   event.Time,
+		"source": event.Source,
+		"data":   event.Data,
+	})
+	if err != nil {
+		log.Printf("Error marshaling %s event: %v", event.Type, err)
+		return
+	}
+	data = append(data, '\n')
+
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	for _, panelConn := range ts.connections {
+		panelConfig, ok := ts.panelMappings.Panels[panelConn.panelID]
+		if !ok || !events.Visible(event, panelConfig.Role) {
+			continue
+		}
+		panelConn.conn.Write(data)
+	}
+}
@@ -1,10 +1,15 @@
 package network
 
 import (
+	"celestial/internal/events"
 	"celestial/internal/gm"
+	"celestial/internal/input"
+	"celestial/internal/recording"
 	"celestial/internal/ship"
 	"celestial/internal/simulation"
+	"celestial/internal/view"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"sync"
@@ -22,6 +27,9 @@ type WebSocketServer struct {
 	upgrader     websocket.Upgrader
 	stopChan     chan struct{}
 	server       *http.Server
+	recorder     *recording.Recorder
+	replay       *recording.Player
+	actionRouter *input.ActionRouter
 }
 
 type Client struct {
@@ -35,10 +43,24 @@ type Client struct {
 type Message struct {
 	Type    string                 `json:"type"`
 	Payload map[string]interface{} `json:"payload"`
+
+	// Station actions carry their routing at the top level.
+	Role   string      `json:"role,omitempty"`
+	System string      `json:"system,omitempty"`
+	Action string      `json:"action,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
+type EventMessage struct {
+	Type   string                 `json:"type"`
+	Event  string                 `json:"event"`
+	Time   float64                `json:"time"`
+	Source string                 `json:"source,omitempty"`
+	Data   map[string]interface{} `json:"data"`
 }
 
 func NewWebSocketServer(port int, sim *simulation.Simulator, gmCtrl *gm.Controller) *WebSocketServer {
-	return &WebSocketServer{
+	ws := &WebSocketServer{
 		port:         port,
 		simulator:    sim,
 		gmController: gmCtrl,
@@ -50,6 +72,16 @@ func NewWebSocketServer(port int, sim *simulation.Simulator, gmCtrl *gm.Controll
 		},
 		stopChan: make(chan struct{}),
 	}
+	sim.Events().Subscribe(ws.broadcastEvent)
+	return ws
+}
+
+func (ws *WebSocketServer) SetRecorder(rec *recording.Recorder) {
+	ws.recorder = rec
+}
+
+func (ws *WebSocketServer) SetActionRouter(router *input.ActionRouter) {
+	ws.actionRouter = router
 }
 
 func (ws *WebSocketServer) Start() {
@@ -57,7 +89,7 @@ func (ws *WebSocketServer) Start() {
 	mux.HandleFunc("/ws", ws.handleWebSocket)
 
 	ws.server = &http.Server{
-		Addr:    ":" + string(rune(ws.port)),
+		Addr:    fmt.Sprintf(":%d", ws.port),
 		Handler: mux,
 	}
 
@@ -65,7 +97,7 @@ func (ws *WebSocketServer) Start() {
 	go ws.heartbeatLoop()
 
 	log.Printf("WebSocket server starting on port %d", ws.port)
-	if err := http.ListenAndServe(":8080", mux); err != nil && err != http.ErrServerClosed {
+	if err := ws.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Printf("WebSocket server error: %v", err)
 	}
 }
@@ -182,10 +214,21 @@ func (ws *WebSocketServer) handleMessage(client *Client, msg *Message) {
 		log.Printf("Client registered as %s (role: %s)", clientType, stationRole)
 
 	case "input":
-		ws.handleInput(client, msg.Payload)
+		if ws.replay == nil {
+			ws.handleInput(client, msg.Payload)
+		}
+
+	case "action":
+		if ws.replay == nil {
+			ws.handleAction(client, msg)
+		}
 
 	case "gm_command":
-		ws.handleGMCommand(client, msg.Payload)
+		if ws.replay != nil {
+			ws.handleReplayCommand(client, msg.Payload)
+		} else {
+			ws.handleGMCommand(client, msg.Payload)
+		}
 
 	case "request_state":
 		ws.sendFullState(client)
@@ -206,17 +249,39 @@ func (ws *WebSocketServer) handleInput(client *Client, payload map[string]interf
 	}
 }
 
+func (ws *WebSocketServer) handleAction(client *Client, msg *Message) {
+	if ws.actionRouter == nil {
+		return
+	}
+
+	role := msg.Role
+	if role == "" {
+		role = client.stationRole
+	}
+	action := &input.Action{
+		Role:   role,
+		System: msg.System,
+		Action: msg.Action,
+		Value:  msg.Value,
+	}
+	if err := ws.actionRouter.RouteAction(action); err != nil {
+		log.Printf("Error routing action: %v", err)
+	}
+}
+
 func (ws *WebSocketServer) handleHOTASInput(payload map[string]interface{}) {
 	shipID, _ := payload["ship_id"].(string)
 	pitch, _ := payload["pitch"].(float64)
 	yaw, _ := payload["yaw"].(float64)
 	roll, _ := payload["roll"].(float64)
 	thrust, _ := payload["thrust"].(float64)
+	strafeX, _ := payload["strafe_x"].(float64)
+	strafeY, _ := payload["strafe_y"].(float64)
 
 	sh := ws.simulator.GetShip(shipID)
 	if sh != nil {
 		sh.ApplyRotation(pitch, yaw, roll)
-		sh.ApplyThrust(0, 0, thrust)
+		sh.ApplyThrust(strafeX, strafeY, thrust)
 	}
 }
 
@@ -228,22 +293,65 @@ func (ws *WebSocketServer) handleUIAction(payload map[string]interface{}) {
 func (ws *WebSocketServer) handleGMCommand(client *Client, payload map[string]interface{}) {
 	command, _ := payload["command"].(string)
 
+	if ws.recorder != nil {
+		ws.recorder.RecordGMCommand(ws.simulator.GetTime(), command, payload)
+	}
+
 	switch command {
 	case "pause":
 		ws.simulator.Pause()
 	case "resume":
 		ws.simulator.Resume()
+	case "set_time_scale":
+		scale, ok := payload["scale"].(float64)
+		if !ok {
+			log.Printf("Ignoring set_time_scale without a numeric scale: %v", payload["scale"])
+			return
+		}
+		ws.gmController.SetTimeScale(scale)
+	case "step":
+		ws.gmController.Step()
 	case "create_snapshot":
-		ws.simulator.CreateSnapshot()
+		label, _ := payload["label"].(string)
+		pinned, _ := payload["pinned"].(bool)
+		ws.gmController.CreateNamedSnapshot(label, pinned)
+	case "pin_snapshot":
+		id, _ := payload["id"].(float64)
+		pinned, ok := payload["pinned"].(bool)
+		if !ok {
+			pinned = true
+		}
+		ws.gmController.PinSnapshot(int(id), pinned)
+	case "label_snapshot":
+		id, _ := payload["id"].(float64)
+		label, _ := payload["label"].(string)
+		ws.gmController.LabelSnapshot(int(id), label)
+	case "list_snapshots":
+		ws.sendMessage(client, Message{
+			Type: "snapshot_list",
+			Payload: map[string]interface{}{
+				"snapshots": ws.gmController.GetSnapshots(),
+				"stored":    ws.gmController.GetStoredSnapshots(),
+			},
+		})
 	case "restore_snapshot":
-		index, _ := payload["index"].(float64)
-		ws.simulator.RestoreSnapshot(int(index))
+		if file, ok := payload["file"].(string); ok {
+			ws.gmController.RestoreStoredSnapshot(file)
+		} else if id, ok := payload["id"].(float64); ok {
+			ws.gmController.RestoreSnapshotByID(int(id))
+		} else {
+			index, _ := payload["index"].(float64)
+			ws.gmController.RestoreSnapshot(int(index))
+		}
 		ws.broadcastFullState()
 	case "spawn_ship":
 		ws.handleSpawnShip(payload)
 	case "remove_ship":
 		shipID, _ := payload["ship_id"].(string)
 		ws.simulator.RemoveShip(shipID)
+	case "destroy_ship":
+		shipID, _ := payload["ship_id"].(string)
+		ws.gmController.DestroyShip(shipID)
 	}
 }
 
@@ -264,17 +372,20 @@ func (ws *WebSocketServer) handleSpawnShip(payload map[string]interface{}) {
 }
 
 func (ws *WebSocketServer) sendFullState(client *Client) {
-	state := ws.buildStateMessage()
-	data, err := json.Marshal(state)
+	ws.sendMessage(client, ws.buildStateMessage())
+}
+
+func (ws *WebSocketServer) sendMessage(client *Client, msg Message) {
+	data, err := json.Marshal(msg)
 	if err != nil {
-		log.Printf("Error marshaling state: %v", err)
+		log.Printf("Error marshaling %s message: %v", msg.Type, err)
 		return
 	}
 
 	select {
 	case client.send <- data:
 	default:
-		log.Printf("Client send buffer full, dropping state update")
+		log.Printf("Client send buffer full, dropping %s message", msg.Type)
 	}
 }
 
@@ -282,11 +393,16 @@ func (ws *WebSocketServer) broadcastLoop() {
 	ticker := time.NewTicker(50 * time.Millisecond)
 	defer ticker.Stop()
 
+	last := time.Now()
 	for {
 		select {
 		case <-ws.stopChan:
 			return
-		case <-ticker.C:
+		case now := <-ticker.C:
+			if ws.replay != nil {
+				ws.replay.Advance(now.Sub(last).Seconds())
+			}
+			last = now
 			ws.broadcastFullState()
 		}
 	}
@@ -300,6 +416,37 @@ func (ws *WebSocketServer) broadcastFullState() {
 		return
 	}
 
+	ws.broadcast(data)
+}
+
+// broadcastEvent sends an event to the clients whose station it concerns.
+func (ws *WebSocketServer) broadcastEvent(event events.Event) {
+	data, err := json.Marshal(EventMessage{
+		Type:   "mission_event",
+		Event:  event.Type,
+		Time:   event.Time,
+		Source: event.Source,
+		Data:   event.Data,
+	})
+	if err != nil {
+		log.Printf("Error marshaling %s event: %v", event.Type, err)
+		return
+	}
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	for client := range ws.clients {
+		if !events.Visible(event, client.stationRole) {
+			continue
+		}
+		select {
+		case client.send <- data:
+		default:
+		}
+	}
+}
+
+func (ws *WebSocketServer) broadcast(data []byte) {
 	ws.mu.RLock()
 	clients := make([]*Client, 0, len(ws.clients))
 	for client := range ws.clients {
@@ -316,97 +463,20 @@ func (ws *WebSocketServer) broadcastFullState() {
 }
 
 func (ws *WebSocketServer) buildStateMessage() Message {
-	ships := ws.simulator.GetAllShips()
-	shipData := make(map[string]interface{})
-
-	for id, sh := range ships {
-		shipData[id] = map[string]interface{}{
-			"id":       sh.ID,
-			"name":     sh.Name,
-			"class_id": sh.ClassID,
-			"position": map[string]float64{
-				"x": sh.Position.X,
-				"y": sh.Position.Y,
-				"z": sh.Position.Z,
-			},
-			"velocity": map[string]float64{
-				"x": sh.Velocity.X,
-				"y": sh.Velocity.Y,
-				"z": sh.Velocity.Z,
-			},
-			"rotation": map[string]float64{
-				"w": sh.Rotation.W,
-				"x": sh.Rotation.X,
-				"y": sh.Rotation.Y,
-				"z": sh.Rotation.Z,
-			},
-			"systems": ws.buildSystemsData(sh),
-		}
+	if ws.replay != nil {
+		return ws.buildReplayStateMessage()
 	}
 
 	return Message{
 		Type: "state_update",
 		Payload: map[string]interface{}{
-			"time":  ws.simulator.CurrentTime,
-			"ships": shipData,
-		},
-	}
-}
-
-func (ws *WebSocketServer) buildSystemsData(sh *ship.Ship) map[string]interface{} {
-	engines := make(map[string]interface{})
-	for id, eng := range sh.Engines {
-		engines[id] = map[string]interface{}{
-			"health":  eng.Health,
-			"enabled": eng.Enabled,
-			"on_fire": eng.OnFire,
-		}
-	}
-
-	weapons := make(map[string]interface{})
-	for id, wpn := range sh.Weapons {
-		weapons[id] = map[string]interface{}{
-			"health":   wpn.Health,
-			"enabled":  wpn.Enabled,
-			"cooldown": wpn.Cooldown,
-			"armed":    wpn.Armed,
-			"loaded":   wpn.Loaded,
-			"locked":   wpn.Locked,
-			"ammo":     wpn.AmmoCount,
-			"on_fire":  wpn.OnFire,
-		}
-	}
-
-	shields := make(map[string]interface{})
-	for id, em := range sh.Shields.Emitters {
-		shields[id] = map[string]interface{}{
-			"strength": em.Strength,
-			"health":   em.Health,
-			"facing":   em.Facing,
-			"on_fire":  em.OnFire,
-		}
-	}
-
-	hull := make(map[string]interface{})
-	for id, sec := range sh.Hull.Sections {
-		hull[id] = map[string]interface{}{
-			"armor":    sec.Armor,
-			"health":   sec.Health,
-			"breached": sec.Breached,
-			"on_fire":  sec.OnFire,
-		}
-	}
-
-	return map[string]interface{}{
-		"engines": engines,
-		"weapons": weapons,
-		"shields": shields,
-		"hull":    hull,
-		"power": map[string]interface{}{
-			"current":     sh.Power.CurrentCapacity,
-			"max":         sh.Power.MaxCapacity,
-			"generation":  sh.Power.Generation,
-			"consumption": sh.Power.Consumption,
+			"time":        ws.simulator.GetTime(),
+			"paused":      ws.simulator.IsPaused(),
+			"time_scale":  ws.simulator.TimeScale(),
+			"ships":       view.Ships(ws.simulator.GetAllShips()),
+			"projectiles": view.Projectiles(ws.simulator.GetProjectiles()),
+			"objects":     view.Objects(ws.simulator.GetObjects()),
+			"triggers":    view.Triggers(ws.simulator.GetTriggers()),
 		},
 	}
 }
@@ -2,6 +2,7 @@ package panel
 
 import (
 	"celestial/internal/ship"
+	"strconv"
 	"sync"
 )
 
@@ -125,12 +126,40 @@ func (psm *PanelStateManager) updateEngineerPowerPanel(state *PanelState, sh *sh
 		Blink: powerPercent < 15,
 	}
 
+	state.Displays["power_demand"] = Display{
+		Type:   "numeric",
+		Value:  sh.Power.Demand,
+		Unit:   "MW",
+		Format: "%.0f",
+	}
+
+	state.Displays["power_supply"] = Display{
+		Type:   "numeric",
+		Value:  sh.Power.Supply * 100,
+		Unit:   "%",
+		Format: "%.0f",
+	}
+
+	state.Indicators["brownout"] = Indicator{
+		Type:  "led",
+		Value: sh.Power.Supply < 1,
+		Color: "yellow",
+		Blink: sh.Power.Supply < 0.5,
+	}
+
 	for id, breaker := range sh.Power.Breakers {
+		color := "green"
+		if breaker.Tripped {
+			color = "red"
+		} else if breaker.Rating > 0 && breaker.Load > breaker.Rating*0.9 {
+			color = "yellow"
+		}
+
 		state.Indicators["breaker_"+id] = Indicator{
 			Type:  "led",
-			Value: breaker.Enabled,
-			Color: "green",
-			Blink: false,
+			Value: breaker.Enabled && !breaker.Tripped,
+			Color: color,
+			Blink: breaker.Tripped || breaker.Overload > 0,
 		}
 
 		state.Displays["breaker_load_"+id] = Display{
@@ -200,7 +229,76 @@ func (psm *PanelStateManager) updateEngineerDamagePanel(state *PanelState, sh *s
 			Unit:   "%",
 			Format: "%.1f",
 		}
+
+		state.Displays["co2_"+id] = Display{
+			Type:   "numeric",
+			Value:  comp.CarbonDioxide,
+			Unit:   "%",
+			Format: "%.1f",
+		}
+
+		state.Indicators["vent_"+id] = Indicator{
+			Type:  "led",
+			Value: comp.Vented,
+			Color: "red",
+			Blink: comp.Vented,
+		}
+
+		level := ship.FireLevel(comp.Fire)
+		state.Displays["fire_intensity_"+id] = Display{
+			Type:   "numeric",
+			Value:  comp.Fire * 100,
+			Unit:   "%",
+			Format: "%.0f",
+		}
+		state.Displays["fire_level_"+id] = Display{
+			Type:   "text",
+			Value:  level,
+			Unit:   "",
+			Format: "%s",
+		}
+
+		fireColor := "red"
+		if level == "smoldering" {
+			fireColor = "yellow"
+		}
+		state.Indicators["fire_level_"+id] = Indicator{
+			Type:  "led",
+			Value: comp.Fire > 0,
+			Color: fireColor,
+			Blink: level == "blazing",
+		}
+
+		state.Indicators["suppression_"+id] = Indicator{
+			Type:  "led",
+			Value: comp.Suppression > 0,
+			Color: "blue",
+			Blink: false,
+		}
 	}
+
+	for agent, charges := range sh.Suppression.Charges {
+		state.Displays[agent+"_charges"] = Display{
+			Type:   "numeric",
+			Value:  charges,
+			Unit:   "",
+			Format: "%d",
+		}
+	}
+
+	for id, door := range sh.LifeSupport.Doors {
+		color := "green"
+		if !door.Open {
+			color = "yellow"
+		}
+		state.Indicators["door_"+id] = Indicator{
+			Type:  "led",
+			Value: door.Open,
+			Color: color,
+		}
+	}
+
+	teamIndicators(state, sh)
 }
 
 func (psm *PanelStateManager) updateEngineerSystemsPanel(state *PanelState, sh *ship.Ship) {
@@ -234,6 +332,55 @@ func (psm *PanelStateManager) updateEngineerSystemsPanel(state *PanelState, sh *
 			Unit:   "kN",
 			Format: "%.0f",
 		}
+
+		heatDisplay(state, "engine_"+id, engine.Thermal)
+	}
+
+	for id, weapon := range sh.Weapons {
+		heatDisplay(state, "weapon_"+id, weapon.Thermal)
+	}
+	heatDisplay(state, "shields", sh.Shields.Thermal)
+	for id, subsystem := range sh.Subsystems {
+		heatDisplay(state, "subsystem_"+id, subsystem.Thermal)
+	}
+
+	state.Displays["coolant_available"] = Display{
+		Type:   "numeric",
+		Value:  sh.Coolant.Available(),
+		Unit:   "/" + strconv.Itoa(sh.Coolant.Capacity),
+		Format: "%d",
+	}
+	for _, group := range sh.PowerGroups() {
+		state.Displays["coolant_"+group] = Display{
+			Type:   "numeric",
+			Value:  sh.Coolant.Assigned[group],
+			Unit:   "units",
+			Format: "%d",
+		}
+	}
+}
+
+// heatDisplay adds a system's heat readout and an overheat warning LED.
+func heatDisplay(state *PanelState, key string, thermal ship.Thermal) {
+	state.Displays["heat_"+key] = Display{
+		Type:   "numeric",
+		Value:  thermal.Heat,
+		Unit:   "%",
+		Format: "%.0f",
+	}
+
+	color := "green"
+	if thermal.Overheated || thermal.Heat >= 90 {
+		color = "red"
+	} else if thermal.Heat >= 50 {
+		color = "yellow"
+	}
+
+	state.Indicators["heat_"+key] = Indicator{
+		Type:  "led",
+		Value: thermal.Heat > 0 || thermal.Overheated,
+		Color: color,
+		Blink: thermal.Overheated,
 	}
 }
 
@@ -271,11 +418,12 @@ func (psm *PanelStateManager) updateFlightMainPanel(state *PanelState, sh *ship.
 		Format: "%.0f",
 	}
 
+	dock := sh.DockingState()
 	state.Indicators["docked"] = Indicator{
 		Type:  "led",
-		Value: sh.Docked,
+		Value: dock.Status != "",
 		Color: "blue",
-		Blink: false,
+		Blink: dock.Status == ship.DockingApproach || dock.Status == ship.DockingClamping,
 	}
 }
 
@@ -303,10 +451,53 @@ func (psm *PanelStateManager) updateFlightNavigationPanel(state *PanelState, sh
 
 	state.Displays["heading"] = Display{
 		Type:   "numeric",
-		Value:  0.0,
+		Value:  sh.Heading(),
 		Unit:   "°",
 		Format: "%.1f",
 	}
+
+	flight := sh.FlightState()
+	state.Displays["autopilot_mode"] = Display{
+		Type:   "text",
+		Value:  flight.Mode,
+		Unit:   "",
+		Format: "%s",
+	}
+
+	state.Displays["nav_distance"] = Display{
+		Type:   "numeric",
+		Value:  flight.Status.Distance,
+		Unit:   "m",
+		Format: "%.0f",
+	}
+
+	state.Displays["nav_eta"] = Display{
+		Type:   "numeric",
+		Value:  flight.Status.ETA,
+		Unit:   "s",
+		Format: "%.0f",
+	}
+
+	state.Displays["heading_error"] = Display{
+		Type:   "numeric",
+		Value:  flight.Status.HeadingError,
+		Unit:   "°",
+		Format: "%.1f",
+	}
+
+	state.Indicators["autopilot_engaged"] = Indicator{
+		Type:  "led",
+		Value: flight.Mode != ship.FlightManual,
+		Color: "green",
+		Blink: false,
+	}
+
+	state.Indicators["nav_arrived"] = Indicator{
+		Type:  "led",
+		Value: flight.Status.Arrived,
+		Color: "blue",
+		Blink: false,
+	}
 }
 
 func (psm *PanelStateManager) updateWeaponsTorpedosPanel1(state *PanelState, sh *ship.Ship) {
@@ -428,22 +619,43 @@ func (psm *PanelStateManager) updateCaptainCommandPanel(state *PanelState, sh *s
 		Blink: false,
 	}
 
-	for role, crew := range sh.Crew {
-		healthPercent := crew.Health
+	// Each station's LED shows whether anyone is left at it and how well it
+	// is staffed, and blinks while any of its crew is hurt or dead.
+	alive := make(map[string]int)
+	hurt := make(map[string]bool)
+	casualties := 0
+	for _, crew := range sh.Crew {
+		hurt[crew.Role] = hurt[crew.Role] || crew.Status != "healthy"
+		if crew.Health > 0 {
+			alive[crew.Role]++
+		} else {
+			casualties++
+		}
+	}
+
+	for role := range hurt {
+		effectiveness := sh.Effectiveness(role)
 		color := "green"
-		if healthPercent < 25 {
+		if effectiveness < 0.5 {
 			color = "red"
-		} else if healthPercent < 50 {
+		} else if effectiveness < 1 {
 			color = "yellow"
 		}
 
 		state.Indicators["crew_"+role] = Indicator{
 			Type:  "led",
-			Value: healthPercent > 0,
+			Value: alive[role] > 0,
 			Color: color,
-			Blink: crew.Status != "healthy",
+			Blink: hurt[role],
 		}
 	}
+
+	state.Displays["casualties"] = Display{
+		Type:   "numeric",
+		Value:  casualties,
+		Unit:   "",
+		Format: "%d",
+	}
 }
 
 func (psm *PanelStateManager) updateCaptainStatusPanel(state *PanelState, sh *ship.Ship) {
@@ -520,6 +732,28 @@ func (psm *PanelStateManager) updateCommsMainPanel(state *PanelState, sh *ship.S
 }
 
 func (psm *PanelStateManager) updateOperationsPowerPanel(state *PanelState, sh *ship.Ship) {
+	for _, group := range sh.PowerGroups() {
+		level, ok := sh.Power.Allocation[group]
+		if !ok {
+			level = 1
+		}
+		state.Displays["allocation_"+group] = Display{
+			Type:   "numeric",
+			Value:  level * 100,
+			Unit:   "%",
+			Format: "%.0f",
+		}
+	}
+
+	for _, preset := range ship.PowerPresets() {
+		state.Indicators["preset_"+preset] = Indicator{
+			Type:  "led",
+			Value: sh.Power.Preset == preset,
+			Color: "green",
+			Blink: false,
+		}
+	}
+
 	state.Indicators["shields_enabled"] = Indicator{
 		Type:  "led",
 		Value: sh.Shields.Enabled,
@@ -527,6 +761,13 @@ func (psm *PanelStateManager) updateOperationsPowerPanel(state *PanelState, sh *
 		Blink: false,
 	}
 
+	state.Displays["shield_frequency"] = Display{
+		Type:   "numeric",
+		Value:  sh.Shields.Frequency,
+		Unit:   "",
+		Format: "%.0f",
+	}
+
 	for id, emitter := range sh.Shields.Emitters {
 		strengthPercent := (emitter.Strength / emitter.MaxStrength) * 100
 		state.Displays["shield_"+id] = Display{
@@ -587,6 +828,13 @@ func (psm *PanelStateManager) updateRelaySensorsPanel(state *PanelState, sh *shi
 			Color: color,
 			Blink: false,
 		}
+
+		state.Displays["sensor_range"] = Display{
+			Type:   "numeric",
+			Value:  sh.DetectionRange(),
+			Unit:   "m",
+			Format: "%.0f",
+		}
 	}
 }
 
@@ -624,6 +872,43 @@ func (psm *PanelStateManager) updateFirstOfficerMainPanel(state *PanelState, sh
 			Format: "%.0f",
 		}
 	}
+
+	teamIndicators(state, sh)
+}
+
+// teamIndicators shows where each damage control team is and what it is
+// doing.
+func teamIndicators(state *PanelState, sh *ship.Ship) {
+	for id, team := range sh.Teams {
+		color := "blue"
+		switch team.Status {
+		case ship.TeamStandingBy:
+			color = "green"
+		case ship.TeamMoving:
+			color = "yellow"
+		case ship.TeamLost:
+			color = "red"
+		}
+		state.Indicators["team_"+id] = Indicator{
+			Type:  "led",
+			Value: team.Status != ship.TeamLost,
+			Color: color,
+			Blink: team.Health < 50 && team.Status != ship.TeamLost,
+		}
+
+		state.Displays["team_eta_"+id] = Display{
+			Type:   "numeric",
+			Value:  team.ETA,
+			Unit:   "s",
+			Format: "%.0f",
+		}
+		state.Displays["team_location_"+id] = Display{
+			Type:   "text",
+			Value:  team.Compartment,
+			Unit:   "",
+			Format: "%s",
+		}
+	}
 }
 
 func (psm *PanelStateManager) GetState(panelID string) *PanelState {
@@ -0,0 +1,267 @@
+package recording
+
+import (
+	"bufio"
+	"celestial/internal/view"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	MinReplaySpeed = 0.25
+	MaxReplaySpeed = 8.0
+)
+
+type Player struct {
+	mu sync.Mutex
+
+	frames    []Entry
+	keyframes []int
+	inputs    []Entry
+	events    []Entry
+
+	tickRate int
+	start    float64
+	duration float64
+
+	position float64
+	speed    float64
+	playing  bool
+	cursor   int
+	ships    map[string]interface{}
+	world    map[string]interface{}
+}
+
+func OpenPlayer(path string) (*Player, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening recording: %w", err)
+	}
+	defer file.Close()
+
+	return NewPlayer(file)
+}
+
+func NewPlayer(r io.Reader) (*Player, error) {
+	gz, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("reading recording: %w", err)
+	}
+	defer gz.Close()
+
+	p := &Player{speed: 1.0, ships: make(map[string]interface{}), world: make(map[string]interface{})}
+
+	decoder := json.NewDecoder(gz)
+	for {
+		var entry Entry
+		if err := decoder.Decode(&entry); err != nil {
+			if !errors.Is(err, io.EOF) {
+				// A session cut off by a crash or power loss ends in a partial
+				// gzip block; keep everything decoded up to that point.
+				log.Printf("Recording truncated after %d entries: %v", len(p.frames)+len(p.inputs), err)
+			}
+			break
+		}
+
+		switch entry.Kind {
+		case KindHeader:
+			if entry.Version != FormatVersion {
+				return nil, fmt.Errorf("unsupported recording version %d", entry.Version)
+			}
+			if p.tickRate == 0 {
+				p.tickRate = entry.TickRate
+			}
+		case KindKeyframe:
+			if entry.Snapshot == nil {
+				continue
+			}
+			entry.Ships = normalize(view.Ships(entry.Snapshot.Ships)).(map[string]interface{})
+			entry.World = World(entry.Snapshot.Projectiles, entry.Snapshot.Objects, entry.Snapshot.Triggers)
+			entry.Snapshot = nil
+			p.keyframes = append(p.keyframes, len(p.frames))
+			p.frames = append(p.frames, entry)
+		case KindTick:
+			p.frames = append(p.frames, entry)
+		case KindAction, KindGM:
+			p.inputs = append(p.inputs, entry)
+		case KindEvent:
+			p.events = append(p.events, entry)
+		}
+	}
+
+	if len(p.keyframes) == 0 {
+		return nil, fmt.Errorf("recording contains no keyframes")
+	}
+
+	p.start = p.frames[p.keyframes[0]].Time
+	p.duration = p.frames[len(p.frames)-1].Time
+	p.seekLocked(p.start)
+	return p, nil
+}
+
+func (p *Player) Play() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.position >= p.duration {
+		p.seekLocked(p.start)
+	}
+	p.playing = true
+}
+
+func (p *Player) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.playing = false
+}
+
+func (p *Player) IsPlaying() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.playing
+}
+
+func (p *Player) SetSpeed(speed float64) {
+	if speed < MinReplaySpeed {
+		speed = MinReplaySpeed
+	}
+	if speed > MaxReplaySpeed {
+		speed = MaxReplaySpeed
+	}
+
+	p.mu.Lock()
+	p.speed = speed
+	p.mu.Unlock()
+}
+
+func (p *Player) Speed() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.speed
+}
+
+func (p *Player) Position() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.position
+}
+
+func (p *Player) Start() float64 {
+	return p.start
+}
+
+func (p *Player) Duration() float64 {
+	return p.duration
+}
+
+func (p *Player) Seek(t float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seekLocked(t)
+}
+
+// Advance moves playback forward by a wall-clock interval scaled by the
+// replay speed.
+func (p *Player) Advance(wallDt float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.playing {
+		return
+	}
+
+	target := p.position + wallDt*p.speed
+	if target >= p.duration {
+		target = p.duration
+		p.playing = false
+	}
+	p.applyUntil(target)
+}
+
+func (p *Player) Ships() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return deepCopy(p.ships).(map[string]interface{})
+}
+
+// World returns the projectiles, objects and triggers at the playback
+// position, keyed by category.
+func (p *Player) World() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return deepCopy(p.world).(map[string]interface{})
+}
+
+// Inputs returns the recorded crew actions and GM commands with timestamps
+// in (from, to].
+func (p *Player) Inputs(from, to float64) []Entry {
+	lo := sort.Search(len(p.inputs), func(i int) bool { return p.inputs[i].Time > from })
+	hi := sort.Search(len(p.inputs), func(i int) bool { return p.inputs[i].Time > to })
+	return append([]Entry(nil), p.inputs[lo:hi]...)
+}
+
+// Events returns the recorded simulation events with timestamps in
+// (from, to].
+func (p *Player) Events(from, to float64) []Entry {
+	lo := sort.Search(len(p.events), func(i int) bool { return p.events[i].Time > from })
+	hi := sort.Search(len(p.events), func(i int) bool { return p.events[i].Time > to })
+	return append([]Entry(nil), p.events[lo:hi]...)
+}
+
+func (p *Player) seekLocked(t float64) {
+	if t < p.start {
+		t = p.start
+	}
+	if t > p.duration {
+		t = p.duration
+	}
+
+	k := sort.Search(len(p.keyframes), func(i int) bool {
+		return p.frames[p.keyframes[i]].Time > t
+	}) - 1
+	if k < 0 {
+		k = 0
+	}
+
+	idx := p.keyframes[k]
+	p.ships = deepCopy(p.frames[idx].Ships).(map[string]interface{})
+	p.world = deepCopy(p.frames[idx].World).(map[string]interface{})
+	p.cursor = idx + 1
+	p.position = p.frames[idx].Time
+	p.applyUntil(t)
+}
+
+func (p *Player) applyUntil(t float64) {
+	for p.cursor < len(p.frames) && p.frames[p.cursor].Time <= t {
+		frame := p.frames[p.cursor]
+		switch frame.Kind {
+		case KindKeyframe:
+			p.ships = deepCopy(frame.Ships).(map[string]interface{})
+			p.world = deepCopy(frame.World).(map[string]interface{})
+		case KindTick:
+			for id, delta := range frame.Ships {
+				dm, ok := delta.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if current, ok := p.ships[id].(map[string]interface{}); ok {
+					apply(current, dm)
+				} else {
+					p.ships[id] = deepCopy(dm)
+				}
+			}
+			for _, id := range frame.Removed {
+				delete(p.ships, id)
+			}
+			apply(p.world, frame.World)
+		}
+		p.cursor++
+	}
+	p.position = t
+}
@@ -0,0 +1,372 @@
+package recording
+
+import (
+	"celestial/internal/simulation"
+	"celestial/internal/view"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+)
+
+const FormatVersion = 1
+
+const (
+	KindHeader   = "header"
+	KindKeyframe = "keyframe"
+	KindTick     = "tick"
+	KindAction   = "action"
+	KindGM       = "gm"
+	KindEvent    = "event"
+)
+
+// Entry is one line of a session log. Keyframes carry a full simulation
+// snapshot; ticks carry only the per-ship fields that changed since the
+// previous entry, and in World the changed projectiles, objects and
+// triggers, with nil marking one removed.
+type Entry struct {
+	Kind     string                 `json:"k"`
+	Time     float64                `json:"t"`
+	Version  int                    `json:"v,omitempty"`
+	TickRate int                    `json:"tick_rate,omitempty"`
+	Snapshot *simulation.Snapshot   `json:"snap,omitempty"`
+	Ships    map[string]interface{} `json:"s,omitempty"`
+	Removed  []string               `json:"r,omitempty"`
+	World    map[string]interface{} `json:"w,omitempty"`
+	Action   *ActionRecord          `json:"a,omitempty"`
+	Command  string                 `json:"c,omitempty"`
+	Payload  map[string]interface{} `json:"p,omitempty"`
+	Event    *simulation.Event      `json:"e,omitempty"`
+}
+
+type ActionRecord struct {
+	Role   string      `json:"role"`
+	System string      `json:"system"`
+	Action string      `json:"action"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
+type Recorder struct {
+	mu sync.Mutex
+
+	file    *os.File
+	gz      *gzip.Writer
+	encoder *json.Encoder
+
+	keyframeInterval float64
+	lastKeyframe     float64
+	lastFlush        float64
+	hasKeyframe      bool
+	ships            map[string]interface{}
+	world            map[string]interface{}
+	closed           bool
+}
+
+func NewRecorder(path string, tickRate int, keyframeInterval float64) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening recording: %w", err)
+	}
+
+	if keyframeInterval <= 0 {
+		keyframeInterval = 10
+	}
+
+	gz := gzip.NewWriter(file)
+	r := &Recorder{
+		file:             file,
+		gz:               gz,
+		encoder:          json.NewEncoder(gz),
+		keyframeInterval: keyframeInterval,
+		ships:            make(map[string]interface{}),
+		world:            make(map[string]interface{}),
+	}
+
+	if err := r.write(&Entry{Kind: KindHeader, Version: FormatVersion, TickRate: tickRate}); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	log.Printf("Recording session to %s", path)
+	return r, nil
+}
+
+// Attach records every simulator tick, writing a keyframe whenever the
+// keyframe interval has elapsed and a delta otherwise.
+func (r *Recorder) Attach(sim *simulation.Simulator) {
+	sim.AddTickHook(func(now float64) {
+		r.mu.Lock()
+		due := !r.hasKeyframe || now-r.lastKeyframe >= r.keyframeInterval || now < r.lastKeyframe
+		r.mu.Unlock()
+
+		if due {
+			r.RecordKeyframe(sim.CaptureSnapshot())
+			return
+		}
+		r.RecordTick(now, view.Ships(sim.GetAllShips()), World(sim.GetProjectiles(), sim.GetObjects(), sim.GetTriggers()))
+	})
+	sim.AddEventHandler(r.RecordEvent)
+}
+
+func (r *Recorder) RecordKeyframe(snapshot *simulation.Snapshot) {
+	ships := normalize(view.Ships(snapshot.Ships)).(map[string]interface{})
+	world := World(snapshot.Projectiles, snapshot.Objects, snapshot.Triggers)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	r.ships = ships
+	r.world = world
+	r.hasKeyframe = true
+	r.lastKeyframe = snapshot.Time
+	if err := r.write(&Entry{Kind: KindKeyframe, Time: snapshot.Time, Snapshot: snapshot}); err != nil {
+		log.Printf("Recording keyframe failed: %v", err)
+		return
+	}
+	r.flush(snapshot.Time)
+}
+
+// RecordTick writes what changed since the previous entry, given the ship
+// views and the World of the tick.
+func (r *Recorder) RecordTick(t float64, shipViews, world map[string]interface{}) {
+	current := normalize(shipViews).(map[string]interface{})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	entry := &Entry{Kind: KindTick, Time: t, Ships: make(map[string]interface{})}
+	for id, cur := range current {
+		prev, ok := r.ships[id].(map[string]interface{})
+		if !ok {
+			entry.Ships[id] = cur
+			continue
+		}
+		if delta := diff(prev, cur.(map[string]interface{})); len(delta) > 0 {
+			entry.Ships[id] = delta
+		}
+	}
+	for id := range r.ships {
+		if _, ok := current[id]; !ok {
+			entry.Removed = append(entry.Removed, id)
+		}
+	}
+	r.ships = current
+	if delta := diff(r.world, world); len(delta) > 0 {
+		entry.World = delta
+	}
+	r.world = world
+
+	if len(entry.Ships) == 0 && len(entry.Removed) == 0 && len(entry.World) == 0 {
+		return
+	}
+	if err := r.write(entry); err != nil {
+		log.Printf("Recording tick failed: %v", err)
+	}
+	if t-r.lastFlush >= 1.0 {
+		r.flush(t)
+	}
+}
+
+// World renders the projectiles, objects and triggers as recorded and
+// replayed.
+func World(projectiles map[string]*simulation.Projectile, objects map[string]*simulation.Object, triggers map[string]*simulation.Trigger) map[string]interface{} {
+	return normalize(map[string]interface{}{
+		"projectiles": view.Projectiles(projectiles),
+		"objects":     view.Objects(objects),
+		"triggers":    view.Triggers(triggers),
+	}).(map[string]interface{})
+}
+
+func (r *Recorder) RecordAction(t float64, role, system, action string, value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	entry := &Entry{
+		Kind: KindAction,
+		Time: t,
+		Action: &ActionRecord{
+			Role:   role,
+			System: system,
+			Action: action,
+			Value:  value,
+		},
+	}
+	if err := r.write(entry); err != nil {
+		log.Printf("Recording action failed: %v", err)
+	}
+}
+
+func (r *Recorder) RecordGMCommand(t float64, command string, payload map[string]interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	if err := r.write(&Entry{Kind: KindGM, Time: t, Command: command, Payload: payload}); err != nil {
+		log.Printf("Recording GM command failed: %v", err)
+	}
+}
+
+func (r *Recorder) RecordEvent(event simulation.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	if err := r.write(&Entry{Kind: KindEvent, Time: event.Time, Event: &event}); err != nil {
+		log.Printf("Recording event failed: %v", err)
+	}
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	if err := r.gz.Close(); err != nil {
+		r.file.Close()
+		return fmt.Errorf("closing recording: %w", err)
+	}
+	return r.file.Close()
+}
+
+func (r *Recorder) write(entry *Entry) error {
+	if err := r.encoder.Encode(entry); err != nil {
+		return fmt.Errorf("writing recording entry: %w", err)
+	}
+	return nil
+}
+
+func (r *Recorder) flush(t float64) {
+	r.lastFlush = t
+	if err := r.gz.Flush(); err != nil {
+		log.Printf("Recording flush failed: %v", err)
+	}
+}
+
+// diff returns the fields of cur that differ from prev, recursing into nested
+// maps. Fields missing from cur are reported as nil so they can be deleted.
+func diff(prev, cur map[string]interface{}) map[string]interface{} {
+	delta := make(map[string]interface{})
+	for k, cv := range cur {
+		pv, ok := prev[k]
+		if !ok {
+			delta[k] = cv
+			continue
+		}
+		cm, curIsMap := cv.(map[string]interface{})
+		pm, prevIsMap := pv.(map[string]interface{})
+		if curIsMap && prevIsMap {
+			if sub := diff(pm, cm); len(sub) > 0 {
+				delta[k] = sub
+			}
+			continue
+		}
+		if !reflect.DeepEqual(pv, cv) {
+			delta[k] = cv
+		}
+	}
+	for k := range prev {
+		if _, ok := cur[k]; !ok {
+			delta[k] = nil
+		}
+	}
+	return delta
+}
+
+func apply(target, delta map[string]interface{}) {
+	for k, dv := range delta {
+		if dv == nil {
+			delete(target, k)
+			continue
+		}
+		dm, deltaIsMap := dv.(map[string]interface{})
+		tm, targetIsMap := target[k].(map[string]interface{})
+		if deltaIsMap && targetIsMap {
+			apply(tm, dm)
+			continue
+		}
+		target[k] = deepCopy(dv)
+	}
+}
+
+// normalize converts view values into the generic shapes produced by JSON
+// decoding so live deltas and replayed deltas compare the same way.
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = normalize(item)
+		}
+		return out
+	case map[string]float64:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = item
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = normalize(item)
+		}
+		return out
+	case int:
+		return float64(val)
+	case float64, string, bool, nil:
+		return val
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return nil
+		}
+		var out interface{}
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil
+		}
+		return out
+	}
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = deepCopy(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = deepCopy(item)
+		}
+		return out
+	default:
+		return val
+	}
+}
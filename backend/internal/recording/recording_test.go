@@ -0,0 +1,180 @@
+package recording
+
+import (
+	"celestial/internal/config"
+	"celestial/internal/ship"
+	"celestial/internal/simulation"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func positionX(t *testing.T, ships map[string]interface{}, id string) float64 {
+	t.Helper()
+	sh, ok := ships[id].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Ship %s missing from replay state", id)
+	}
+	pos := sh["position"].(map[string]interface{})
+	return pos["x"].(float64)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.rec.gz")
+	rec, err := NewRecorder(path, 60, 1.0)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	classes := map[string]*config.ShipClass{
+		"test_ship": {ID: "test_ship", Name: "Test Ship", Mass: 100000, MaxSpeed: 200},
+	}
+	sim := simulation.NewSimulator(60, classes)
+	sim.SpawnShip("player", "test_ship", "Player", true, ship.Vector3{})
+	sim.GetShip("player").Velocity = ship.Vector3{X: 10}
+	rec.Attach(sim)
+
+	positions := make(map[int]float64)
+	for i := 1; i <= 180; i++ {
+		if i == 90 {
+			sim.SpawnShip("late", "test_ship", "Late", true, ship.Vector3{X: -50})
+			rec.RecordGMCommand(sim.GetTime(), "spawn_ship", map[string]interface{}{"ship_id": "late"})
+		}
+		if i == 150 {
+			sim.RemoveShip("late")
+		}
+		sim.Tick()
+		positions[i] = sim.GetShip("player").Position.X
+	}
+
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	player, err := OpenPlayer(path)
+	if err != nil {
+		t.Fatalf("OpenPlayer failed: %v", err)
+	}
+
+	for _, tick := range []int{150, 45, 100, 180, 1} {
+		player.Seek(float64(tick) / 60.0)
+		got := positionX(t, player.Ships(), "player")
+		if math.Abs(got-positions[tick]) > 1e-9 {
+			t.Errorf("Tick %d: expected x=%f, got %f", tick, positions[tick], got)
+		}
+	}
+
+	player.Seek(2.0)
+	if _, ok := player.Ships()["late"]; !ok {
+		t.Error("Expected late ship to be present at t=2.0")
+	}
+	player.Seek(2.9)
+	if _, ok := player.Ships()["late"]; ok {
+		t.Error("Expected late ship to be removed at t=2.9")
+	}
+
+	if inputs := player.Inputs(0, player.Duration()); len(inputs) != 1 || inputs[0].Command != "spawn_ship" {
+		t.Errorf("Expected one recorded GM command, got %+v", inputs)
+	}
+}
+
+func TestPlayerAdvance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.rec.gz")
+	rec, err := NewRecorder(path, 60, 1.0)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	classes := map[string]*config.ShipClass{
+		"test_ship": {ID: "test_ship", Name: "Test Ship", Mass: 100000, MaxSpeed: 200},
+	}
+	sim := simulation.NewSimulator(60, classes)
+	sim.SpawnShip("player", "test_ship", "Player", true, ship.Vector3{})
+	sim.GetShip("player").Velocity = ship.Vector3{X: 10}
+	rec.Attach(sim)
+	for i := 0; i < 120; i++ {
+		sim.Tick()
+	}
+	rec.Close()
+
+	player, err := OpenPlayer(path)
+	if err != nil {
+		t.Fatalf("OpenPlayer failed: %v", err)
+	}
+
+	player.Play()
+	player.SetSpeed(2.0)
+	player.Advance(0.5)
+	if pos := player.Position(); math.Abs(pos-(player.Start()+1.0)) > 1e-9 {
+		t.Errorf("Expected position %f at 2x speed, got %f", player.Start()+1.0, pos)
+	}
+
+	player.Advance(10)
+	if player.IsPlaying() {
+		t.Error("Player should stop at the end of the recording")
+	}
+	if player.Position() != player.Duration() {
+		t.Errorf("Expected position to clamp at %f, got %f", player.Duration(), player.Position())
+	}
+}
+
+func TestReplayWorld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.rec.gz")
+	rec, err := NewRecorder(path, 60, 1.0)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	classes := map[string]*config.ShipClass{
+		"test_ship": {ID: "test_ship", Name: "Test Ship", Mass: 100000, MaxSpeed: 200},
+	}
+	sim := simulation.NewSimulator(60, classes)
+	sim.SpawnShip("player", "test_ship", "Player", true, ship.Vector3{})
+	rec.Attach(sim)
+
+	shots := make(map[int]float64)
+	for i := 1; i <= 180; i++ {
+		switch i {
+		case 30:
+			sim.SpawnProjectile("shot", "torpedo", "player", "", ship.Vector3{Y: 5000}, ship.Vector3{Y: 100}, 10)
+		case 60:
+			sim.SpawnObject("rock", "asteroid", ship.Vector3{Y: -5000})
+		case 150:
+			sim.RemoveObject("rock")
+		}
+		sim.Tick()
+		if proj, ok := sim.GetProjectiles()["shot"]; ok {
+			shots[i] = proj.Position.Y
+		}
+	}
+	rec.Close()
+
+	player, err := OpenPlayer(path)
+	if err != nil {
+		t.Fatalf("OpenPlayer failed: %v", err)
+	}
+
+	player.Seek(0.4)
+	if projectiles := player.World()["projectiles"].(map[string]interface{}); len(projectiles) != 0 {
+		t.Error("Expected no projectiles before the shot")
+	}
+	for _, tick := range []int{45, 90, 150} {
+		player.Seek(float64(tick) / 60.0)
+		shot, ok := player.World()["projectiles"].(map[string]interface{})["shot"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("Tick %d: projectile missing from replay", tick)
+		}
+		if y := shot["position"].(map[string]interface{})["y"].(float64); math.Abs(y-shots[tick]) > 1e-9 {
+			t.Errorf("Tick %d: expected projectile y=%f, got %f", tick, shots[tick], y)
+		}
+	}
+
+	player.Seek(2.0)
+	if _, ok := player.World()["objects"].(map[string]interface{})["rock"]; !ok {
+		t.Error("Expected the asteroid at t=2.0")
+	}
+	player.Seek(2.9)
+	if _, ok := player.World()["objects"].(map[string]interface{})["rock"]; ok {
+		t.Error("Expected the asteroid to be removed at t=2.9")
+	}
+}
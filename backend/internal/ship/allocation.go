@@ -0,0 +1,145 @@
+package ship
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	MaxAllocation = 3.0
+
+	DefaultSensorRange = 5000.0
+)
+
+// powerPresets are allocation setpoints by power group. Groups a preset does
+// not name return to 100%.
+var powerPresets = map[string]map[string]float64{
+	"balanced": {},
+	"combat": {
+		"engines":        1.0,
+		"weapons":        2.0,
+		"shields":        2.0,
+		"sensors":        1.5,
+		"communications": 0.5,
+		"navigation":     0.5,
+	},
+	"cruise": {
+		"engines":    2.0,
+		"weapons":    0.5,
+		"shields":    0.5,
+		"navigation": 1.5,
+	},
+	"silent_running": {
+		"engines":        0.25,
+		"weapons":        0,
+		"shields":        0,
+		"sensors":        0.5,
+		"communications": 0,
+		"navigation":     0.5,
+	},
+}
+
+// PowerPresets lists the preset names in order.
+func PowerPresets() []string {
+	names := make([]string, 0, len(powerPresets))
+	for name := range powerPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PowerGroups returns the allocation groups of the ship: engines, weapons,
+// shields and one per subsystem type.
+func (s *Ship) PowerGroups() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.powerGroups()
+}
+
+func (s *Ship) powerGroups() []string {
+	groups := []string{"engines", "weapons", "shields"}
+	seen := make(map[string]bool)
+	var subsystems []string
+	for _, subsystem := range s.Subsystems {
+		if !seen[subsystem.Type] {
+			seen[subsystem.Type] = true
+			subsystems = append(subsystems, subsystem.Type)
+		}
+	}
+	sort.Strings(subsystems)
+	return append(groups, subsystems...)
+}
+
+// allocation returns the setpoint of a power group, 1 if it was never set.
+func (s *Ship) allocation(group string) float64 {
+	if level, ok := s.Power.Allocation[group]; ok {
+		return level
+	}
+	return 1
+}
+
+// SetAllocation sets a power group's setpoint, as a fraction of nominal
+// power clamped to 0..MaxAllocation.
+func (s *Ship) SetAllocation(group string, level float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := false
+	for _, g := range s.powerGroups() {
+		known = known || g == group
+	}
+	if !known {
+		return fmt.Errorf("unknown power group: %s", group)
+	}
+
+	s.Power.Allocation[group] = math.Max(0, math.Min(MaxAllocation, level))
+	s.Power.Preset = ""
+	return nil
+}
+
+// ApplyPowerPreset switches every power group to a named preset.
+func (s *Ship) ApplyPowerPreset(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	preset, ok := powerPresets[name]
+	if !ok {
+		return fmt.Errorf("unknown power preset: %s", name)
+	}
+	for _, group := range s.powerGroups() {
+		level, ok := preset[group]
+		if !ok {
+			level = 1
+		}
+		s.Power.Allocation[group] = level
+	}
+	s.Power.Preset = name
+	return nil
+}
+
+// output is the effect multiplier of a system: its setpoint times the
+// fraction of the power it asked for that the bus delivered, less any loss
+// to heat.
+func (s *Ship) output(group string, powerLevel float64, thermal Thermal) float64 {
+	return s.allocation(group) * powerLevel * thermal.efficiency()
+}
+
+// DetectionRange is the sensor range scaled by the sensors' output. Ships
+// without sensors see out to their base range.
+func (s *Ship) DetectionRange() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, subsystem := range s.Subsystems {
+		if subsystem.Type == "sensors" {
+			if !subsystem.Enabled || subsystem.Health <= 0 {
+				return 0
+			}
+			return s.SensorRange * s.output("sensors", subsystem.PowerLevel, subsystem.Thermal)
+		}
+	}
+	return s.SensorRange
+}
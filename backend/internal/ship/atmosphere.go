@@ -0,0 +1,218 @@
+package ship
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// Breached and vented compartments lose ventPressureRate kPa per second
+	// at the default volume; larger compartments take longer to empty.
+	ventPressureRate = 10.0
+
+	// Open doors close doorFlowRate of the pressure and mix difference
+	// between two compartments per second.
+	doorFlowRate = 0.5
+
+	// Gas amounts are in kPa·m³. Each crew member turns crewOxygenUse of
+	// oxygen into carbon dioxide per second and a fire at full intensity
+	// fireOxygenUse.
+	crewOxygenUse = 0.5
+	fireOxygenUse = 50.0
+
+	// A fire at full intensity heats its compartment by fireHeatRate °C per
+	// second. Compartments lose hullHeatLoss of their excess over ambient
+	// per second through the hull, climateRate more with life support at
+	// full output, and ventHeatLoss while open to space.
+	ambientTemperature = 20.0
+	fireHeatRate       = 10.0
+	hullHeatLoss       = 0.01
+	climateRate        = 0.1
+	ventHeatLoss       = 0.5
+
+	// At full output life support scrubs scrubRate of carbon dioxide back
+	// into oxygen and pumps regenRate kPa of fresh air per second into each
+	// sealed compartment at the default volume.
+	scrubRate = 10.0
+	regenRate = 2.0
+
+	// Crew are harmed below hypoxiaOxygen kPa of oxygen or minCrewPressure
+	// kPa of pressure, at up to the given rates per second in a vacuum.
+	hypoxiaOxygen       = 16.0
+	minCrewPressure     = 50.0
+	hypoxiaDamage       = 5.0
+	decompressionDamage = 10.0
+)
+
+// station returns the compartment a crew member works in: the requested one
+// if it exists, otherwise the bridge, otherwise the first compartment.
+func (ls *LifeSupportSystem) station(id string) string {
+	if _, ok := ls.Compartments[id]; ok {
+		return id
+	}
+	if _, ok := ls.Compartments["bridge"]; ok {
+		return "bridge"
+	}
+	ids := make([]string, 0, len(ls.Compartments))
+	for id := range ls.Compartments {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+// gas returns the total, oxygen and carbon dioxide amounts in a compartment.
+func (c *Compartment) gas() (float64, float64, float64) {
+	total := c.Pressure * c.Volume
+	return total, total * c.Oxygen / 100, total * c.CarbonDioxide / 100
+}
+
+func (c *Compartment) setGas(total, oxygen, co2 float64) {
+	c.Pressure = math.Max(0, total/c.Volume)
+	if total <= 0 {
+		c.Oxygen, c.CarbonDioxide = 0, 0
+		return
+	}
+	c.Oxygen = math.Max(0, oxygen/total*100)
+	c.CarbonDioxide = math.Max(0, co2/total*100)
+}
+
+// OxygenPressure is the partial pressure of oxygen in kPa.
+func (c *Compartment) OxygenPressure() float64 {
+	return c.Pressure * c.Oxygen / 100
+}
+
+// VentCompartment opens or closes a compartment's emergency vents to space.
+func (s *Ship) VentCompartment(id string, vent bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comp, ok := s.LifeSupport.Compartments[id]
+	if !ok {
+		return fmt.Errorf("compartment not found: %s", id)
+	}
+	comp.Vented = vent
+	return nil
+}
+
+// lifeSupportOutput is the share of full scrubbing and regeneration the
+// life support plant delivers, by its power, heat and health.
+func (s *Ship) lifeSupportOutput() float64 {
+	output := 0.0
+	for _, subsystem := range s.Subsystems {
+		if subsystem.Type != "life_support" || !subsystem.Enabled || subsystem.MaxHealth <= 0 {
+			continue
+		}
+		health := math.Max(0, subsystem.Health) / subsystem.MaxHealth
+		output = math.Max(output, s.output("life_support", subsystem.PowerLevel, subsystem.Thermal)*health)
+	}
+	return output
+}
+
+// updateLifeSupport moves gas through open doors, vents breached and vented
+// compartments, burns oxygen for crew and fires, scrubs and regenerates what
+// the plant can, and heats compartments with fire and cools them again.
+func (s *Ship) updateLifeSupport(dt float64) {
+	ls := s.LifeSupport
+
+	doorIDs := make([]string, 0, len(ls.Doors))
+	for id := range ls.Doors {
+		doorIDs = append(doorIDs, id)
+	}
+	sort.Strings(doorIDs)
+	flow := math.Min(0.5, doorFlowRate*dt)
+	for _, id := range doorIDs {
+		door := ls.Doors[id]
+		a, b := ls.Compartments[door.A], ls.Compartments[door.B]
+		if !door.Open || a == nil || b == nil {
+			continue
+		}
+		equalize(a, b, flow)
+	}
+
+	crew := make(map[string]int)
+	for _, member := range s.Crew {
+		if member.Health > 0 {
+			crew[member.Compartment]++
+		}
+	}
+
+	output := s.lifeSupportOutput()
+	for id, comp := range ls.Compartments {
+		total, oxygen, co2 := comp.gas()
+		open := comp.Breached || comp.Vented
+
+		if open {
+			vented := math.Min(total, ventPressureRate*defaultCompartmentVolume*dt)
+			if total > 0 {
+				oxygen -= oxygen * vented / total
+				co2 -= co2 * vented / total
+			}
+			total -= vented
+		}
+
+		burned := crewOxygenUse * float64(crew[id]) * dt
+		burned += fireOxygenUse * comp.Fire * dt
+		burned = math.Min(burned, oxygen)
+		oxygen -= burned
+		co2 += burned
+
+		if !open && output > 0 {
+			scrubbed := math.Min(co2, scrubRate*output*dt)
+			co2 -= scrubbed
+			oxygen += scrubbed
+
+			if comp.Pressure < comp.MaxPressure {
+				added := math.Min(regenRate*output*defaultCompartmentVolume*dt, (comp.MaxPressure-comp.Pressure)*comp.Volume)
+				total += added
+				oxygen += added * comp.MaxOxygen / 100
+			}
+		}
+
+		comp.setGas(total, oxygen, co2)
+
+		cooling := hullHeatLoss + climateRate*output
+		if open {
+			cooling = ventHeatLoss
+		}
+		comp.Temperature += fireHeatRate * comp.Fire * dt
+		comp.Temperature -= (comp.Temperature - ambientTemperature) * math.Min(1, cooling*dt)
+	}
+}
+
+// equalize moves gas from the higher pressure compartment to the lower and
+// mixes their oxygen and carbon dioxide, closing fraction of the difference.
+func equalize(a, b *Compartment, fraction float64) {
+	totalA, oxygenA, co2A := a.gas()
+	totalB, oxygenB, co2B := b.gas()
+
+	target := (totalA + totalB) / (a.Volume + b.Volume)
+	moved := fraction * (a.Pressure - target) * a.Volume
+	srcTotal, srcOxygen, srcCO2 := totalA, oxygenA, co2A
+	if moved < 0 {
+		srcTotal, srcOxygen, srcCO2 = totalB, oxygenB, co2B
+	}
+	if srcTotal > 0 {
+		oxygenMoved := moved * srcOxygen / srcTotal
+		co2Moved := moved * srcCO2 / srcTotal
+		totalA, oxygenA, co2A = totalA-moved, oxygenA-oxygenMoved, co2A-co2Moved
+		totalB, oxygenB, co2B = totalB+moved, oxygenB+oxygenMoved, co2B+co2Moved
+	}
+
+	// Diffusion evens out the mix even at equal pressure.
+	if totalA > 0 && totalB > 0 {
+		mixOxygen := (oxygenA + oxygenB) / (totalA + totalB)
+		mixCO2 := (co2A + co2B) / (totalA + totalB)
+		dOxygen := fraction * (oxygenA - mixOxygen*totalA)
+		dCO2 := fraction * (co2A - mixCO2*totalA)
+		oxygenA, oxygenB = oxygenA-dOxygen, oxygenB+dOxygen
+		co2A, co2B = co2A-dCO2, co2B+dCO2
+	}
+
+	a.setGas(totalA, oxygenA, co2A)
+	b.setGas(totalB, oxygenB, co2B)
+}
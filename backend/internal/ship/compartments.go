@@ -0,0 +1,305 @@
+package ship
+
+import (
+	"celestial/internal/config"
+	"celestial/internal/events"
+	"fmt"
+	"log"
+	"sort"
+)
+
+const defaultCompartmentVolume = 100.0
+
+// Door joins two adjacent compartments. Gas only moves between compartments
+// through an open door.
+type Door struct {
+	ID   string
+	A    string
+	B    string
+	Open bool
+}
+
+func newCompartment(id, section string, volume, fuel float64) *Compartment {
+	if volume <= 0 {
+		volume = defaultCompartmentVolume
+	}
+	if fuel <= 0 {
+		fuel = defaultFireFuel
+	}
+	return &Compartment{
+		ID:          id,
+		Section:     section,
+		Volume:      volume,
+		Fuel:        fuel,
+		MaxFuel:     fuel,
+		MaxPressure: 101.3,
+		Pressure:    101.3,
+		MaxOxygen:   21.0,
+		Oxygen:      21.0,
+		Temperature: ambientTemperature,
+	}
+}
+
+// newLifeSupport builds the compartment graph of a class. Classes that
+// declare no compartments get one behind each hull section, joined by open
+// doors to the compartments on perpendicular facings.
+func newLifeSupport(class *config.ShipClass) *LifeSupportSystem {
+	ls := &LifeSupportSystem{
+		Compartments: make(map[string]*Compartment),
+		Doors:        make(map[string]*Door),
+	}
+
+	compartments := class.Compartments
+	doors := class.Doors
+	if len(compartments) == 0 {
+		compartments, doors = defaultLayout(class.Hull.Sections)
+	}
+
+	for _, cfg := range compartments {
+		ls.Compartments[cfg.ID] = newCompartment(cfg.ID, cfg.Section, cfg.Volume, cfg.Fuel)
+	}
+	link := func(a, b string) {
+		if ls.Compartments[a] == nil || ls.Compartments[b] == nil || a == b || ls.adjacent(a, b) {
+			return
+		}
+		ls.Compartments[a].Adjacent = append(ls.Compartments[a].Adjacent, b)
+		ls.Compartments[b].Adjacent = append(ls.Compartments[b].Adjacent, a)
+	}
+	for _, cfg := range compartments {
+		for _, adj := range cfg.Adjacent {
+			link(cfg.ID, adj)
+		}
+	}
+	for _, cfg := range doors {
+		if len(cfg.Connects) != 2 {
+			continue
+		}
+		ls.Doors[cfg.ID] = &Door{ID: cfg.ID, A: cfg.Connects[0], B: cfg.Connects[1], Open: !cfg.Closed}
+		link(cfg.Connects[0], cfg.Connects[1])
+	}
+	for _, comp := range ls.Compartments {
+		sort.Strings(comp.Adjacent)
+	}
+	ls.Sickbay = sickbay(class, ls)
+	return ls
+}
+
+func defaultLayout(sections []config.HullSectionConfig) ([]config.CompartmentConfig, []config.DoorConfig) {
+	compartments := make([]config.CompartmentConfig, 0, len(sections))
+	for _, sec := range sections {
+		compartments = append(compartments, config.CompartmentConfig{ID: sec.ID, Section: sec.ID})
+	}
+
+	var doors []config.DoorConfig
+	for i, a := range compartments {
+		for _, b := range compartments[i+1:] {
+			axisA, okA := facingAxes[a.ID]
+			axisB, okB := facingAxes[b.ID]
+			if okA && okB && axisA.Dot(axisB) != 0 {
+				continue
+			}
+			doors = append(doors, config.DoorConfig{ID: a.ID + "_" + b.ID, Connects: []string{a.ID, b.ID}})
+		}
+	}
+	return compartments, doors
+}
+
+func (ls *LifeSupportSystem) adjacent(a, b string) bool {
+	comp, ok := ls.Compartments[a]
+	if !ok {
+		return false
+	}
+	for _, id := range comp.Adjacent {
+		if id == b {
+			return true
+		}
+	}
+	return false
+}
+
+// connected reports whether an open door joins two compartments.
+func (ls *LifeSupportSystem) connected(a, b string) bool {
+	for _, door := range ls.Doors {
+		if door.Open && (door.A == a && door.B == b || door.A == b && door.B == a) {
+			return true
+		}
+	}
+	return false
+}
+
+// compartmentsAt resolves a location, either a compartment ID or a hull
+// section ID, to the compartments it covers in ID order.
+func (s *Ship) compartmentsAt(location string) []*Compartment {
+	if comp, ok := s.LifeSupport.Compartments[location]; ok {
+		return []*Compartment{comp}
+	}
+	return s.sectionCompartments(location)
+}
+
+// sectionCompartments returns the compartments behind a hull section.
+func (s *Ship) sectionCompartments(section string) []*Compartment {
+	var comps []*Compartment
+	for _, comp := range s.LifeSupport.Compartments {
+		if comp.Section == section {
+			comps = append(comps, comp)
+		}
+	}
+	sort.Slice(comps, func(i, j int) bool { return comps[i].ID < comps[j].ID })
+	return comps
+}
+
+// CompartmentsAt returns the IDs of the compartments at a location, either a
+// compartment ID or a hull section ID.
+func (s *Ship) CompartmentsAt(location string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []string
+	for _, comp := range s.compartmentsAt(location) {
+		ids = append(ids, comp.ID)
+	}
+	return ids
+}
+
+// Neighbours returns the compartments sharing a bulkhead with a compartment.
+func (s *Ship) Neighbours(compartment string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comp, ok := s.LifeSupport.Compartments[compartment]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), comp.Adjacent...)
+}
+
+// Connected reports whether an open door joins two compartments.
+func (s *Ship) Connected(a, b string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.LifeSupport.connected(a, b)
+}
+
+// sectionsAt returns the hull sections touched by a location and its
+// compartments.
+func (s *Ship) sectionsAt(location string, comps []*Compartment) []*HullSection {
+	var sections []*HullSection
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if section, ok := s.Hull.Sections[id]; ok && !seen[id] {
+			seen[id] = true
+			sections = append(sections, section)
+		}
+	}
+	add(location)
+	for _, comp := range comps {
+		add(comp.Section)
+	}
+	return sections
+}
+
+// breachSection opens every compartment behind a breached hull section.
+func (s *Ship) breachSection(section string) {
+	for _, comp := range s.sectionCompartments(section) {
+		if !comp.Breached {
+			comp.Breached = true
+			log.Printf("Hull breach in %s on ship %s", comp.ID, s.ID)
+			s.publish(events.HullBreach, map[string]interface{}{"compartment": comp.ID, "section": section})
+		}
+	}
+}
+
+// SetDoor opens or closes a door.
+func (s *Ship) SetDoor(id string, open bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	door, ok := s.LifeSupport.Doors[id]
+	if !ok {
+		return fmt.Errorf("door not found: %s", id)
+	}
+	door.Open = open
+	return nil
+}
+
+// StartFire sets the compartments at a location alight, along with their
+// hull sections. Fires start small and grow.
+func (s *Ship) StartFire(location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comps := s.compartmentsAt(location)
+	sections := s.sectionsAt(location, comps)
+	if len(comps) == 0 && len(sections) == 0 {
+		return fmt.Errorf("unknown location: %s", location)
+	}
+	for _, comp := range comps {
+		if !comp.OnFire {
+			s.publish(events.FireStarted, map[string]interface{}{"compartment": comp.ID, "section": comp.Section})
+		}
+		comp.ignite()
+	}
+	for _, section := range sections {
+		section.OnFire = true
+	}
+	return nil
+}
+
+// ExtinguishFire puts out the fires at a location at once. A hull section
+// stays alight while any of its compartments burns.
+func (s *Ship) ExtinguishFire(location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comps := s.compartmentsAt(location)
+	sections := s.sectionsAt(location, comps)
+	if len(comps) == 0 && len(sections) == 0 {
+		return fmt.Errorf("unknown location: %s", location)
+	}
+	for _, comp := range comps {
+		if comp.OnFire {
+			s.publish(events.FireOut, map[string]interface{}{"compartment": comp.ID, "section": comp.Section})
+		}
+		comp.extinguish()
+	}
+	for _, section := range sections {
+		s.syncSectionFire(section)
+	}
+	return nil
+}
+
+// syncSectionFire keeps a hull section alight while any compartment behind
+// it burns.
+func (s *Ship) syncSectionFire(section *HullSection) {
+	section.OnFire = false
+	for _, comp := range s.sectionCompartments(section.ID) {
+		section.OnFire = section.OnFire || comp.OnFire
+	}
+}
+
+// SealBreach patches the breaches at a location. A hull section stays
+// breached while any of its compartments is open to space.
+func (s *Ship) SealBreach(location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comps := s.compartmentsAt(location)
+	sections := s.sectionsAt(location, comps)
+	if len(comps) == 0 && len(sections) == 0 {
+		return fmt.Errorf("unknown location: %s", location)
+	}
+	for _, comp := range comps {
+		if comp.Breached {
+			s.publish(events.BreachSealed, map[string]interface{}{"compartment": comp.ID, "section": comp.Section})
+		}
+		comp.Breached = false
+	}
+	for _, section := range sections {
+		section.Breached = false
+		for _, comp := range s.sectionCompartments(section.ID) {
+			section.Breached = section.Breached || comp.Breached
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package ship
+
+import (
+	"celestial/internal/config"
+	"celestial/internal/events"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+const (
+	// Crew below injuredHealth leave their post for sickbay, where they heal
+	// sickbayHealRate health per second and return to duty once healed.
+	injuredHealth   = 50.0
+	sickbayHealRate = 2.0
+
+	// Crew caught in a fire lose crewFireDamage health per second at full
+	// intensity. A hull hit injures the crew behind the section by
+	// crewHitInjury of the hull damage.
+	crewFireDamage = 10.0
+	crewHitInjury  = 0.1
+
+	// Automation keeps a station working at minStationEffectiveness however
+	// few of its crew are left.
+	minStationEffectiveness = 0.25
+)
+
+// Every player ship has at least one crew member at each bridge station.
+var crewRoles = []string{"engineer", "flight", "weapons", "captain", "comms", "operations", "relay", "first_officer"}
+
+var crewNames = []string{
+	"Okafor", "Lindqvist", "Tanaka", "Moreau", "Haddad", "Novak", "Reyes", "Kowalski",
+	"Adeyemi", "Petrov", "Castillo", "Nakamura", "Brennan", "Osei", "Varga", "Duarte",
+}
+
+// CrewMember is one of the ship's personnel. Role is the station they serve
+// and Post the compartment it is in; Compartment is where they are now and
+// breathe. The first crew member of each station has the role as their ID.
+type CrewMember struct {
+	ID          string
+	Name        string
+	Role        string
+	Health      float64
+	Status      string
+	Post        string
+	Compartment string
+}
+
+// newCrew musters a class's complement: the `crew` count for each role, at
+// least one for each bridge station.
+func newCrew(class *config.ShipClass, ls *LifeSupportSystem) map[string]*CrewMember {
+	roles := append([]string(nil), crewRoles...)
+	extra := make([]string, 0, len(class.Crew))
+	for role := range class.Crew {
+		extra = append(extra, role)
+	}
+	sort.Strings(extra)
+	for _, role := range extra {
+		if !containsString(crewRoles, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	crew := make(map[string]*CrewMember)
+	for _, role := range roles {
+		count := class.Crew[role]
+		if count <= 0 && containsString(crewRoles, role) {
+			count = 1
+		}
+		post := ls.station(class.Stations[role])
+		for i := 0; i < count; i++ {
+			id := role
+			if i > 0 {
+				id = fmt.Sprintf("%s_%d", role, i+1)
+			}
+			name := crewNames[len(crew)%len(crewNames)]
+			if n := len(crew) / len(crewNames); n > 0 {
+				name = fmt.Sprintf("%s %d", name, n+1)
+			}
+			crew[id] = &CrewMember{
+				ID:          id,
+				Name:        name,
+				Role:        role,
+				Health:      100.0,
+				Status:      "healthy",
+				Post:        post,
+				Compartment: post,
+			}
+		}
+	}
+	return crew
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sickbay returns the compartment where crew are treated: the `sickbay`
+// station if the class names one, otherwise a compartment called sickbay.
+// Ships without one cannot treat their wounded.
+func sickbay(class *config.ShipClass, ls *LifeSupportSystem) string {
+	for _, id := range []string{class.Stations["sickbay"], "sickbay"} {
+		if _, ok := ls.Compartments[id]; ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// Effectiveness is how well a station is staffed, from
+// minStationEffectiveness to 1. Crew fit for duty at their post count fully
+// and injured crew still at their post count half. Stations with no crew
+// assigned work at full effectiveness.
+func (s *Ship) Effectiveness(role string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.effectiveness(role)
+}
+
+func (s *Ship) effectiveness(role string) float64 {
+	complement, staffed := 0, 0.0
+	for _, member := range s.Crew {
+		if member.Role != role {
+			continue
+		}
+		complement++
+		if member.Health <= 0 || member.Compartment != member.Post {
+			continue
+		}
+		if member.Health < injuredHealth {
+			staffed += 0.5
+		} else {
+			staffed++
+		}
+	}
+	if complement == 0 {
+		return 1
+	}
+	return math.Max(minStationEffectiveness, staffed/float64(complement))
+}
+
+// injureCrew hurts the crew behind a hull section struck for amount of hull
+// damage.
+func (s *Ship) injureCrew(section string, amount float64) {
+	for _, member := range s.Crew {
+		comp, ok := s.LifeSupport.Compartments[member.Compartment]
+		if !ok || comp.Section != section || member.Health <= 0 {
+			continue
+		}
+		s.hurtCrew(member, amount*crewHitInjury, "injured")
+	}
+}
+
+func (s *Ship) hurtCrew(member *CrewMember, harm float64, status string) {
+	member.Health = math.Max(0, member.Health-harm)
+	member.Status = status
+	if member.Health == 0 {
+		member.Status = "dead"
+		log.Printf("Ship %s lost %s %s in %s", s.ID, member.Role, member.Name, member.Compartment)
+		s.publish(events.CrewKilled, map[string]interface{}{
+			"crew_id":     member.ID,
+			"name":        member.Name,
+			"role":        member.Role,
+			"compartment": member.Compartment,
+			"cause":       status,
+		})
+	}
+}
+
+// updateCrew harms crew breathing thin or oxygen-starved air or caught in a
+// fire, sends the badly injured to sickbay and heals them there.
+func (s *Ship) updateCrew(dt float64) {
+	ls := s.LifeSupport
+	heal := sickbayHealRate * s.effectiveness("medical")
+
+	for _, member := range s.Crew {
+		comp, ok := ls.Compartments[member.Compartment]
+		if !ok || member.Health <= 0 {
+			continue
+		}
+
+		harm := 0.0
+		status := ""
+		if ppO2 := comp.OxygenPressure(); ppO2 < hypoxiaOxygen {
+			harm += hypoxiaDamage * (1 - ppO2/hypoxiaOxygen)
+			status = "hypoxic"
+		}
+		if comp.Pressure < minCrewPressure {
+			harm += decompressionDamage * (1 - comp.Pressure/minCrewPressure)
+			status = "decompression"
+		}
+		if comp.Fire > 0 {
+			harm += crewFireDamage * comp.Fire
+			status = "burned"
+		}
+		if harm > 0 {
+			s.hurtCrew(member, harm*dt, status)
+			if member.Health == 0 {
+				continue
+			}
+		}
+
+		switch {
+		case ls.Sickbay != "" && member.Compartment != ls.Sickbay && member.Health < injuredHealth:
+			member.Compartment = ls.Sickbay
+			member.Status = "in_sickbay"
+			log.Printf("Ship %s: %s %s taken to sickbay", s.ID, member.Role, member.Name)
+		case harm > 0:
+			// Still in danger; the status names the hazard.
+		case member.Compartment == ls.Sickbay && member.Health < 100:
+			member.Health = math.Min(100, member.Health+heal*dt)
+			member.Status = "in_sickbay"
+		case member.Compartment != member.Post:
+			member.Compartment = member.Post
+			member.Status = "healthy"
+			log.Printf("Ship %s: %s %s returned to duty", s.ID, member.Role, member.Name)
+		case member.Health < 100:
+			member.Status = "injured"
+		default:
+			member.Status = "healthy"
+		}
+	}
+}
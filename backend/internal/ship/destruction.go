@@ -0,0 +1,109 @@
+package ship
+
+import (
+	"celestial/internal/config"
+	"log"
+	"math"
+)
+
+const (
+	defaultDestroyedHull   = 0.1
+	defaultDisabledHull    = 0.3
+	defaultExplosionDamage = 200.0
+	defaultExplosionRadius = 300.0
+
+	// A reactor breach blows up with reactorBlastFactor times the damage
+	// and radius of an ordinary explosion.
+	reactorBlastFactor = 2.0
+)
+
+// Reasons a ship is destroyed or disabled.
+const (
+	CauseCoreSection = "core_section"
+	CauseHull        = "hull"
+	CauseReactor     = "reactor"
+	CauseEngines     = "engines"
+)
+
+// Assess checks the ship against its class's destruction rules. It returns
+// why the ship is destroyed or, failing that, why it is disabled; empty
+// strings mean it fights on.
+func (s *Ship) Assess(rules config.DestructionConfig) (destroyed, disabled string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range rules.CoreSections {
+		if section, ok := s.Hull.Sections[id]; ok && section.Health <= 0 {
+			return CauseCoreSection, ""
+		}
+	}
+	for _, subsystem := range s.Subsystems {
+		if subsystem.Type == "reactor" && subsystem.Health <= 0 {
+			return CauseReactor, ""
+		}
+	}
+
+	health, maxHealth := 0.0, 0.0
+	for _, section := range s.Hull.Sections {
+		health += math.Max(0, section.Health)
+		maxHealth += section.MaxHealth
+	}
+	if maxHealth > 0 {
+		destroyedHull := rules.DestroyedHull
+		if destroyedHull <= 0 {
+			destroyedHull = defaultDestroyedHull
+		}
+		disabledHull := rules.DisabledHull
+		if disabledHull <= 0 {
+			disabledHull = defaultDisabledHull
+		}
+		if health <= destroyedHull*maxHealth {
+			return CauseHull, ""
+		}
+		if health <= disabledHull*maxHealth {
+			return "", CauseHull
+		}
+	}
+
+	engines := 0
+	for _, engine := range s.Engines {
+		if engine.Type == "maneuvering" {
+			continue
+		}
+		if engine.Health > 0 {
+			return "", ""
+		}
+		engines++
+	}
+	if engines > 0 {
+		return "", CauseEngines
+	}
+	return "", ""
+}
+
+// SetDisabled cuts or restores a ship's drive and weapons.
+func (s *Ship) SetDisabled(disabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Disabled != disabled {
+		log.Printf("Ship %s disabled: %v", s.ID, disabled)
+	}
+	s.Disabled = disabled
+}
+
+// Explosion returns the damage and radius of a ship's explosion.
+func Explosion(rules config.DestructionConfig, cause string) (float64, float64) {
+	damage, radius := rules.ExplosionDamage, rules.ExplosionRadius
+	if damage <= 0 {
+		damage = defaultExplosionDamage
+	}
+	if radius <= 0 {
+		radius = defaultExplosionRadius
+	}
+	if cause == CauseReactor {
+		damage *= reactorBlastFactor
+		radius *= reactorBlastFactor
+	}
+	return damage, radius
+}
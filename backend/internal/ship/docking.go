@@ -0,0 +1,213 @@
+package ship
+
+import (
+	"celestial/internal/config"
+	"math"
+	"sort"
+)
+
+const (
+	DockingApproach = "approach"
+	DockingClamping = "clamping"
+	DockingDocked   = "docked"
+
+	defaultApproachLength     = 1000.0
+	defaultMaxApproachSpeed   = 10.0
+	defaultAlignmentTolerance = 15.0
+
+	resupplyInterval = 10.0
+	dockRepairRate   = 5.0
+	dockRechargeRate = 500.0
+)
+
+// DockingPort is a berth on a ship or station. Ships approach along the port
+// axis, inside a cone that widens by AlignmentTolerance out to
+// ApproachLength.
+type DockingPort struct {
+	ID                 string
+	Facing             string
+	ApproachLength     float64
+	MaxApproachSpeed   float64
+	AlignmentTolerance float64
+	OccupiedBy         string
+}
+
+// DockingState tracks a ship through approach, clamping and docked.
+type DockingState struct {
+	Status string
+	HostID string
+	PortID string
+	Timer  float64
+
+	Distance       float64
+	ClosingSpeed   float64
+	AlignmentError float64
+	InCorridor     bool
+}
+
+func NewDockingPorts(configs []config.DockingPortConfig) map[string]*DockingPort {
+	ports := make(map[string]*DockingPort, len(configs))
+	for _, cfg := range configs {
+		port := &DockingPort{
+			ID:                 cfg.ID,
+			Facing:             cfg.Facing,
+			ApproachLength:     cfg.ApproachLength,
+			MaxApproachSpeed:   cfg.MaxApproachSpeed,
+			AlignmentTolerance: cfg.AlignmentTolerance,
+		}
+		if port.ApproachLength <= 0 {
+			port.ApproachLength = defaultApproachLength
+		}
+		if port.MaxApproachSpeed <= 0 {
+			port.MaxApproachSpeed = defaultMaxApproachSpeed
+		}
+		if port.AlignmentTolerance <= 0 {
+			port.AlignmentTolerance = defaultAlignmentTolerance
+		}
+		ports[cfg.ID] = port
+	}
+	return ports
+}
+
+func CopyDockingPorts(src map[string]*DockingPort) map[string]*DockingPort {
+	if src == nil {
+		return nil
+	}
+	ports := make(map[string]*DockingPort, len(src))
+	for id, port := range src {
+		p := *port
+		ports[id] = &p
+	}
+	return ports
+}
+
+// FacingAxis returns the ship-local unit vector of a facing.
+func FacingAxis(facing string) Vector3 {
+	axis, ok := facingAxes[facing]
+	if !ok {
+		return facingAxes["forward"]
+	}
+	return axis
+}
+
+func (s *Ship) DockingState() DockingState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Dock
+}
+
+func (s *Ship) SetDockingState(state DockingState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Dock = state
+	s.Docked = state.Status == DockingDocked
+}
+
+// HoldAt pins the ship in place while the docking clamps have it.
+func (s *Ship) HoldAt(position, velocity Vector3) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Position = position
+	s.Velocity = velocity
+	s.AngularVelocity = Vector3{}
+}
+
+// Resupply reloads munitions and suppressant, repairs and recharges a
+// docked ship.
+func (s *Ship) Resupply(dt float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Dock.Timer += dt
+	for s.Dock.Timer >= resupplyInterval {
+		s.Dock.Timer -= resupplyInterval
+		for _, weapon := range s.Weapons {
+			if weapon.AmmoCount < weapon.AmmoCapacity {
+				weapon.AmmoCount++
+			}
+		}
+		for agent, charges := range s.Suppression.Charges {
+			if charges < s.Suppression.Capacity[agent] {
+				s.Suppression.Charges[agent]++
+			}
+		}
+	}
+
+	repair := dockRepairRate * dt
+	for _, section := range s.Hull.Sections {
+		section.Health = math.Min(section.MaxHealth, section.Health+repair)
+		section.Armor = math.Min(section.MaxArmor, section.Armor+repair)
+	}
+	for _, engine := range s.Engines {
+		engine.Health = math.Min(engine.MaxHealth, engine.Health+repair)
+	}
+	for _, weapon := range s.Weapons {
+		weapon.Health = math.Min(weapon.MaxHealth, weapon.Health+repair)
+	}
+	for _, emitter := range s.Shields.Emitters {
+		emitter.Health = math.Min(emitter.MaxHealth, emitter.Health+repair)
+	}
+	for _, subsystem := range s.Subsystems {
+		subsystem.Health = math.Min(subsystem.MaxHealth, subsystem.Health+repair)
+	}
+
+	s.Power.charge(dockRechargeRate, dt)
+}
+
+// Salvage strips munitions and suppressant from a wreck the ship is docked
+// with, one round per weapon and one charge per agent each resupply
+// interval, taking no more than available in all. It returns how many it
+// took.
+func (s *Ship) Salvage(dt float64, available int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	weaponIDs := make([]string, 0, len(s.Weapons))
+	for id := range s.Weapons {
+		weaponIDs = append(weaponIDs, id)
+	}
+	sort.Strings(weaponIDs)
+	agents := make([]string, 0, len(s.Suppression.Charges))
+	for agent := range s.Suppression.Charges {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+
+	taken := 0
+	s.Dock.Timer += dt
+	for s.Dock.Timer >= resupplyInterval {
+		s.Dock.Timer -= resupplyInterval
+		for _, id := range weaponIDs {
+			if weapon := s.Weapons[id]; taken < available && weapon.AmmoCount < weapon.AmmoCapacity {
+				weapon.AmmoCount++
+				taken++
+			}
+		}
+		for _, agent := range agents {
+			if taken < available && s.Suppression.Charges[agent] < s.Suppression.Capacity[agent] {
+				s.Suppression.Charges[agent]++
+				taken++
+			}
+		}
+	}
+	return taken
+}
+
+// Stores counts the munitions and suppressant charges aboard, which a wreck
+// leaves behind for salvage.
+func (s *Ship) Stores() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stores := 0
+	for _, weapon := range s.Weapons {
+		stores += weapon.AmmoCount
+	}
+	for _, charges := range s.Suppression.Charges {
+		stores += charges
+	}
+	return stores
+}
@@ -0,0 +1,37 @@
+package ship
+
+import "celestial/internal/events"
+
+// SetPublisher sets where the ship publishes its events. Ships without one,
+// such as snapshot copies, drop them.
+func (s *Ship) SetPublisher(p events.Publisher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = p
+}
+
+// Publish publishes an event about the ship on behalf of source, adding the
+// ship's ID to data.
+func (s *Ship) Publish(eventType, source string, data map[string]interface{}) {
+	s.mu.RLock()
+	p := s.events
+	s.mu.RUnlock()
+
+	publish(p, s.ID, eventType, source, data)
+}
+
+func (s *Ship) publish(eventType string, data map[string]interface{}) {
+	publish(s.events, s.ID, eventType, events.SourceShip, data)
+}
+
+func publish(p events.Publisher, shipID, eventType, source string, data map[string]interface{}) {
+	if p == nil {
+		return
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["ship_id"] = shipID
+	p.Publish(eventType, source, data)
+}
@@ -0,0 +1,207 @@
+package ship
+
+import (
+	"celestial/internal/config"
+	"celestial/internal/events"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+const (
+	defaultFireFuel  = 100.0
+	defaultHalon     = 4
+	defaultFoam      = 8
+	fireStartLevel   = 0.2
+	fireGrowthRate   = 0.05
+	fireStarveRate   = 0.1
+	fuelBurnRate     = 1.0
+	ignitionDecay    = 0.05
+	spreadThreshold  = 0.3
+	doorSpreadRate   = 0.1
+	bulkheadSpread   = 0.025
+	minFireOxygen    = 10.0
+	smolderingLevel  = 0.25
+	blazingLevel     = 0.6
+	suppressantHalon = "halon"
+	suppressantFoam  = "foam"
+)
+
+// suppressant is how a fire suppression agent works: it knocks a fire down
+// by rate per second for duration seconds and soaks fuel per second.
+type suppressant struct {
+	duration float64
+	rate     float64
+	soak     float64
+}
+
+// Halon knocks a fire down fast; foam works slower but soaks the fuel so
+// the compartment is slow to reignite.
+var suppressants = map[string]suppressant{
+	suppressantHalon: {duration: 4, rate: 0.3},
+	suppressantFoam:  {duration: 12, rate: 0.1, soak: 2},
+}
+
+// SuppressionSystem holds the ship's fire suppression charges by agent.
+type SuppressionSystem struct {
+	Capacity map[string]int
+	Charges  map[string]int
+}
+
+func newSuppressionSystem(cfg config.SuppressionConfig) *SuppressionSystem {
+	halon, foam := cfg.Halon, cfg.Foam
+	if halon == 0 && foam == 0 {
+		halon, foam = defaultHalon, defaultFoam
+	}
+	return &SuppressionSystem{
+		Capacity: map[string]int{suppressantHalon: halon, suppressantFoam: foam},
+		Charges:  map[string]int{suppressantHalon: halon, suppressantFoam: foam},
+	}
+}
+
+// FireLevel names a fire intensity for displays.
+func FireLevel(intensity float64) string {
+	switch {
+	case intensity <= 0:
+		return "none"
+	case intensity < smolderingLevel:
+		return "smoldering"
+	case intensity < blazingLevel:
+		return "burning"
+	default:
+		return "blazing"
+	}
+}
+
+func (c *Compartment) ignite() {
+	c.Fire = math.Max(c.Fire, fireStartLevel)
+	c.OnFire = true
+	c.Ignition = 0
+}
+
+func (c *Compartment) extinguish() {
+	c.Fire = 0
+	c.OnFire = false
+	c.Ignition = 0
+}
+
+// sectionFire is the intensity of the worst fire behind a hull section. A
+// section burning with no compartments behind it burns at full intensity.
+func (s *Ship) sectionFire(section string) float64 {
+	comps := s.sectionCompartments(section)
+	if len(comps) == 0 {
+		return 1
+	}
+	intensity := 0.0
+	for _, comp := range comps {
+		intensity = math.Max(intensity, comp.Fire)
+	}
+	return intensity
+}
+
+// Suppress discharges a suppressant into each compartment at a location,
+// one charge per compartment. The agent works over several seconds. With no
+// agent named, halon is used while it lasts and then foam.
+func (s *Ship) Suppress(location, agent string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comps := s.compartmentsAt(location)
+	if len(comps) == 0 {
+		return fmt.Errorf("unknown location: %s", location)
+	}
+	if agent == "" {
+		agent = suppressantFoam
+		if s.Suppression.Charges[suppressantHalon] >= len(comps) {
+			agent = suppressantHalon
+		}
+	}
+	if _, ok := suppressants[agent]; !ok {
+		return fmt.Errorf("unknown suppressant: %s", agent)
+	}
+	if s.Suppression.Charges[agent] < len(comps) {
+		return fmt.Errorf("not enough %s: %d charges left", agent, s.Suppression.Charges[agent])
+	}
+
+	s.Suppression.Charges[agent] -= len(comps)
+	for _, comp := range comps {
+		comp.Suppression = suppressants[agent].duration
+		comp.Suppressant = agent
+	}
+	return nil
+}
+
+// updateFires grows fires on oxygen and fuel, starves them without either,
+// works suppressant down and spreads strong fires to neighbouring
+// compartments, faster through open doors than through bulkheads.
+func (s *Ship) updateFires(dt float64) {
+	ls := s.LifeSupport
+	ids := make([]string, 0, len(ls.Compartments))
+	for id := range ls.Compartments {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	exposure := make(map[string]float64)
+	for _, id := range ids {
+		comp := ls.Compartments[id]
+		if comp.Fire < spreadThreshold {
+			continue
+		}
+		for _, adj := range comp.Adjacent {
+			rate := bulkheadSpread
+			if ls.connected(id, adj) {
+				rate = doorSpreadRate
+			}
+			exposure[adj] += comp.Fire * rate
+		}
+	}
+
+	for _, id := range ids {
+		comp := ls.Compartments[id]
+		wasBurning := comp.Fire > 0
+		ppO2 := comp.OxygenPressure()
+
+		if comp.Suppression > 0 {
+			agent := suppressants[comp.Suppressant]
+			comp.Fire -= agent.rate * dt
+			comp.Fuel = math.Max(0, comp.Fuel-agent.soak*dt)
+			comp.Ignition = 0
+			comp.Suppression = math.Max(0, comp.Suppression-dt)
+			if comp.Suppression == 0 {
+				comp.Suppressant = ""
+			}
+		} else if comp.Fire > 0 {
+			if ppO2 < minFireOxygen || comp.Fuel <= 0 {
+				comp.Fire -= fireStarveRate * dt
+			} else {
+				normal := comp.MaxPressure * comp.MaxOxygen / 100
+				oxygen := (ppO2 - minFireOxygen) / (normal - minFireOxygen)
+				comp.Fire = math.Min(1, comp.Fire+fireGrowthRate*oxygen*(comp.Fuel/comp.MaxFuel)*dt)
+			}
+			comp.Fuel = math.Max(0, comp.Fuel-fuelBurnRate*comp.Fire*dt)
+		} else if exposure[id] > 0 {
+			comp.Ignition += exposure[id] * dt
+			if comp.Ignition >= 1 && comp.Fuel > 0 && ppO2 >= minFireOxygen {
+				comp.ignite()
+				log.Printf("Fire spread to %s on ship %s", id, s.ID)
+				s.publish(events.FireStarted, map[string]interface{}{"compartment": id, "section": comp.Section})
+			}
+		} else {
+			comp.Ignition = math.Max(0, comp.Ignition-ignitionDecay*dt)
+		}
+
+		if comp.Fire <= 0 {
+			comp.Fire = 0
+			comp.OnFire = false
+			if wasBurning {
+				log.Printf("Fire in %s on ship %s is out", id, s.ID)
+				s.publish(events.FireOut, map[string]interface{}{"compartment": id, "section": comp.Section})
+			}
+		}
+		if section, ok := s.Hull.Sections[comp.Section]; ok && wasBurning != comp.OnFire {
+			s.syncSectionFire(section)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package ship
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	FlightManual    = ""
+	FlightNavigate  = "navigate"
+	FlightHold      = "hold"
+	FlightMatch     = "match"
+	FlightOrbit     = "orbit"
+	FlightIntercept = "intercept"
+
+	defaultArrivalRadius = 100.0
+	defaultOrbitRadius   = 1000.0
+	orbitSpeedFactor     = 0.5
+	holdGain             = 0.5
+	headingGain          = 2.0
+	matchTolerance       = 1.0
+)
+
+// FlightComputer flies the ship on behalf of the helm. Modes that follow a
+// contact are given its position each tick by the simulator; the others fly
+// to Point.
+type FlightComputer struct {
+	Mode     string
+	TargetID string
+	Point    Vector3
+	Radius   float64
+	Status   FlightStatus
+}
+
+// FlightStatus is the progress of the active flight mode. ETA is -1 while the
+// ship is not closing on its goal; HeadingError is in degrees.
+type FlightStatus struct {
+	Distance     float64
+	ETA          float64
+	HeadingError float64
+	Arrived      bool
+}
+
+// FlightTarget is the current state of the contact a flight mode follows.
+type FlightTarget struct {
+	Position Vector3
+	Velocity Vector3
+}
+
+// Engage hands the helm to the flight computer. Navigate and orbit fly to
+// targetID when set and to point otherwise; match and intercept need a
+// target; hold keeps the ship where it is. radius is the arrival or orbit
+// radius, 0 for the default.
+func (s *Ship) Engage(mode, targetID string, point Vector3, radius float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch mode {
+	case FlightNavigate, FlightOrbit:
+	case FlightMatch, FlightIntercept:
+		if targetID == "" {
+			return fmt.Errorf("%s requires a target", mode)
+		}
+	case FlightHold:
+		targetID = ""
+		point = s.Position
+	default:
+		return fmt.Errorf("unknown flight mode: %s", mode)
+	}
+
+	s.Flight = FlightComputer{
+		Mode:     mode,
+		TargetID: targetID,
+		Point:    point,
+		Radius:   radius,
+		Status:   FlightStatus{ETA: -1},
+	}
+	return nil
+}
+
+// Disengage returns the helm to manual with the engines at all stop.
+func (s *Ship) Disengage() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Flight = FlightComputer{Status: FlightStatus{ETA: -1}}
+	s.Throttle = 0
+	s.StrafeLateral = 0
+	s.StrafeVertical = 0
+}
+
+func (s *Ship) FlightState() FlightComputer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Flight
+}
+
+// Heading is the compass heading of the bow in degrees, measured clockwise
+// from +Z when seen from above.
+func (s *Ship) Heading() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	forward := s.Rotation.Rotate(Vector3{Z: 1})
+	heading := math.Atan2(-forward.X, forward.Z) * 180 / math.Pi
+	if heading < 0 {
+		heading += 360
+	}
+	return heading
+}
+
+// Fly sets the helm for the active flight mode and returns its progress.
+// target is the followed contact, or nil when the mode flies to a point.
+func (s *Ship) Fly(target *FlightTarget) FlightStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fc := &s.Flight
+	if fc.Mode == FlightManual {
+		return fc.Status
+	}
+
+	goal, goalVelocity := fc.Point, Vector3{}
+	if target != nil {
+		goal, goalVelocity = target.Position, target.Velocity
+	}
+
+	forward := s.Rotation.Rotate(Vector3{Z: 1})
+	toGoal := goal.Sub(s.Position)
+	distance := toGoal.Length()
+	arrival := fc.Radius
+	if arrival <= 0 {
+		arrival = defaultArrivalRadius
+	}
+
+	status := FlightStatus{Distance: distance, ETA: -1}
+	desired := goalVelocity
+	facing := forward
+	remaining := math.Max(0, distance-arrival)
+
+	switch fc.Mode {
+	case FlightNavigate:
+		status.Arrived = distance <= arrival
+		if !status.Arrived {
+			desired = desired.Add(toGoal.Scale(s.approachSpeed(remaining) / distance))
+			facing = toGoal
+		}
+
+	case FlightHold:
+		status.Arrived = distance <= arrival
+		speed := math.Min(distance*holdGain, s.approachSpeed(distance))
+		if distance > 0 {
+			desired = toGoal.Scale(speed / distance)
+		}
+		remaining = distance
+
+	case FlightMatch:
+		if distance > 0 {
+			facing = toGoal
+		}
+		closing := goalVelocity.Sub(s.Velocity).Length()
+		status.Arrived = closing <= matchTolerance
+		if s.Acceleration > 0 {
+			status.ETA = closing / s.Acceleration
+		}
+
+	case FlightOrbit:
+		radius := fc.Radius
+		if radius <= 0 {
+			radius = defaultOrbitRadius
+		}
+		radial := toGoal.Scale(-1).Normalize()
+		if radial == (Vector3{}) {
+			radial = forward
+		}
+		tangent := Vector3{Y: 1}.Cross(radial).Normalize()
+		if tangent == (Vector3{}) {
+			tangent = Vector3{X: 1}
+		}
+		correction := math.Max(-s.MaxSpeed, math.Min(s.MaxSpeed, (radius-distance)*holdGain))
+		desired = desired.
+			Add(tangent.Scale(s.MaxSpeed * orbitSpeedFactor)).
+			Add(radial.Scale(correction))
+		facing = desired.Sub(goalVelocity)
+		status.Distance = math.Abs(distance - radius)
+		status.Arrived = status.Distance <= arrival
+		closing := s.Velocity.Sub(goalVelocity).Dot(radial)
+		if distance > radius {
+			closing = -closing
+		}
+		if closing > 0.1 {
+			status.ETA = status.Distance / closing
+		}
+
+	case FlightIntercept:
+		aim, eta := interceptPoint(toGoal, goalVelocity, s.MaxSpeed)
+		status.Arrived = distance <= arrival
+		if !status.Arrived {
+			desired = aim.Normalize().Scale(s.MaxSpeed)
+			facing = aim
+			status.ETA = eta
+		}
+	}
+
+	if status.Arrived {
+		status.ETA = 0
+	} else if status.ETA < 0 && fc.Mode != FlightOrbit && distance > 0 {
+		closing := s.Velocity.Sub(goalVelocity).Dot(toGoal) / distance
+		if closing > 0.1 {
+			status.ETA = remaining / closing
+		}
+	}
+
+	status.HeadingError = s.steer(desired, facing, forward)
+	fc.Status = status
+	return status
+}
+
+// approachSpeed is the fastest speed from which the ship can still stop
+// within distance on reverse thrust alone.
+func (s *Ship) approachSpeed(distance float64) float64 {
+	brake := s.Acceleration * reverseThrustFactor
+	return math.Min(s.MaxSpeed, math.Sqrt(2*brake*distance))
+}
+
+// steer sets the helm to reach the desired world velocity and turns the bow
+// toward facing. It returns the remaining heading error in degrees.
+func (s *Ship) steer(desired, facing, forward Vector3) float64 {
+	if s.Acceleration > 0 && s.MaxSpeed > 0 {
+		toLocal := s.Rotation.Conjugate()
+		want := toLocal.Rotate(desired)
+		err := want.Sub(toLocal.Rotate(s.Velocity))
+
+		// Feed forward the thrust that holds the desired speed against drag,
+		// then close the remaining error within about a second.
+		command := want.Scale(1 / s.MaxSpeed).Add(err.Scale(1 / s.Acceleration))
+		if command.Z < 0 {
+			command.Z /= reverseThrustFactor
+		}
+		s.Throttle = clampUnit(command.Z)
+		s.StrafeLateral = clampUnit(-command.X)
+		s.StrafeVertical = clampUnit(command.Y)
+	}
+
+	dir := facing.Normalize()
+	if dir == (Vector3{}) {
+		return 0
+	}
+	angle := math.Acos(math.Max(-1, math.Min(1, forward.Dot(dir))))
+	axis := forward.Cross(dir).Normalize()
+	if axis == (Vector3{}) {
+		if angle < math.Pi/2 {
+			s.AngularVelocity = Vector3{}
+			return 0
+		}
+		axis = s.Rotation.Rotate(Vector3{Y: 1})
+	}
+	s.AngularVelocity = axis.Scale(math.Min(s.TurnRate, angle*headingGain))
+	return angle * 180 / math.Pi
+}
+
+// interceptPoint returns where a ship flying at speed meets a contact at
+// relative position rel moving with velocity, relative to the ship, and the
+// time to get there. Contacts that cannot be caught are chased directly.
+func interceptPoint(rel, velocity Vector3, speed float64) (Vector3, float64) {
+	a := velocity.Dot(velocity) - speed*speed
+	b := 2 * rel.Dot(velocity)
+	c := rel.Dot(rel)
+
+	t := -1.0
+	if math.Abs(a) < 1e-9 {
+		if b < 0 {
+			t = -c / b
+		}
+	} else if disc := b*b - 4*a*c; disc >= 0 {
+		root := math.Sqrt(disc)
+		for _, candidate := range []float64{(-b - root) / (2 * a), (-b + root) / (2 * a)} {
+			if candidate > 0 && (t < 0 || candidate < t) {
+				t = candidate
+			}
+		}
+	}
+	if t < 0 {
+		return rel, -1
+	}
+	return rel.Add(velocity.Scale(t)), t
+}
@@ -0,0 +1,160 @@
+package ship
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+const (
+	DefaultCoolant = 8
+
+	// Heat is 0..MaxHeat. Systems gain heatPerLoad per second at 100% power
+	// and shed baseCooling of their heat per second, plus coolantCooling per
+	// assigned coolant unit. An uncooled system at 100% settles warm at 48,
+	// just short of losing efficiency; above that it needs coolant.
+	MaxHeat        = 100.0
+	heatPerLoad    = 12.0
+	baseCooling    = 0.25
+	coolantCooling = 0.25
+	weaponFireHeat = 4.0
+
+	// Above efficiencyHeat a system loses up to maxHeatPenalty of its
+	// output, at fireHeat it catches fire and at MaxHeat it shuts down
+	// until it has cooled to restartHeat.
+	efficiencyHeat = 50.0
+	maxHeatPenalty = 0.5
+	fireHeat       = 90.0
+	restartHeat    = 40.0
+	overheatDamage = 0.1
+)
+
+// Thermal is the heat state of a powered system.
+type Thermal struct {
+	Heat       float64
+	Overheated bool
+}
+
+func (t Thermal) efficiency() float64 {
+	if t.Overheated {
+		return 0
+	}
+	if t.Heat <= efficiencyHeat {
+		return 1
+	}
+	return 1 - maxHeatPenalty*(t.Heat-efficiencyHeat)/(MaxHeat-efficiencyHeat)
+}
+
+// CoolantSystem is the ship's pool of coolant units, assigned by power group.
+type CoolantSystem struct {
+	Capacity int
+	Assigned map[string]int
+}
+
+func newCoolantSystem(capacity int) *CoolantSystem {
+	if capacity <= 0 {
+		capacity = DefaultCoolant
+	}
+	return &CoolantSystem{Capacity: capacity, Assigned: make(map[string]int)}
+}
+
+// Available returns the unassigned coolant units.
+func (c *CoolantSystem) Available() int {
+	available := c.Capacity
+	for _, units := range c.Assigned {
+		available -= units
+	}
+	return available
+}
+
+// AssignCoolant sets the coolant units on a power group, drawing on the
+// units not assigned elsewhere.
+func (s *Ship) AssignCoolant(group string, units int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := false
+	for _, g := range s.powerGroups() {
+		known = known || g == group
+	}
+	if !known {
+		return fmt.Errorf("unknown power group: %s", group)
+	}
+	if units < 0 {
+		return fmt.Errorf("invalid coolant units: %d", units)
+	}
+	if units-s.Coolant.Assigned[group] > s.Coolant.Available() {
+		return fmt.Errorf("not enough coolant: %d units available", s.Coolant.Available())
+	}
+
+	if units == 0 {
+		delete(s.Coolant.Assigned, group)
+	} else {
+		s.Coolant.Assigned[group] = units
+	}
+	return nil
+}
+
+// updateHeat heats each running system in proportion to its power draw and
+// cools it by its group's coolant. Hot systems lose efficiency, catch fire
+// and finally shut down with overload damage.
+func (s *Ship) updateHeat(dt float64) {
+	step := func(t *Thermal, group string, running bool, powerLevel float64, onFire *bool) bool {
+		load := 0.0
+		if running && !t.Overheated {
+			load = s.allocation(group) * powerLevel
+		}
+		cooling := baseCooling + coolantCooling*float64(s.Coolant.Assigned[group])
+		t.Heat = math.Max(0, math.Min(MaxHeat, t.Heat+(heatPerLoad*load-cooling*t.Heat)*dt))
+
+		if t.Heat >= fireHeat && onFire != nil {
+			*onFire = true
+		}
+		if t.Overheated && t.Heat <= restartHeat {
+			t.Overheated = false
+		}
+		if !t.Overheated && t.Heat >= MaxHeat {
+			t.Overheated = true
+			return true
+		}
+		return false
+	}
+
+	for _, engine := range s.Engines {
+		if step(&engine.Thermal, "engines", engine.Enabled, engine.PowerLevel, &engine.OnFire) {
+			engine.Health = math.Max(0, engine.Health-engine.MaxHealth*overheatDamage)
+			log.Printf("Ship %s engine %s overheated", s.ID, engine.ID)
+		}
+	}
+	for _, weapon := range s.Weapons {
+		if step(&weapon.Thermal, "weapons", weapon.Enabled, weapon.PowerLevel, &weapon.OnFire) {
+			weapon.Health = math.Max(0, weapon.Health-weapon.MaxHealth*overheatDamage)
+			log.Printf("Ship %s weapon %s overheated", s.ID, weapon.ID)
+		}
+	}
+
+	// The shield generator sets the first emitter alight.
+	var emitterFire *bool
+	ids := make([]string, 0, len(s.Shields.Emitters))
+	for id := range s.Shields.Emitters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if len(ids) > 0 {
+		emitterFire = &s.Shields.Emitters[ids[0]].OnFire
+	}
+	if step(&s.Shields.Thermal, "shields", s.Shields.Enabled, s.Shields.PowerLevel, emitterFire) {
+		for _, emitter := range s.Shields.Emitters {
+			emitter.Health = math.Max(0, emitter.Health-emitter.MaxHealth*overheatDamage)
+		}
+		log.Printf("Ship %s shield generator overheated", s.ID)
+	}
+
+	for _, subsystem := range s.Subsystems {
+		if step(&subsystem.Thermal, subsystem.Type, subsystem.Enabled, subsystem.PowerLevel, &subsystem.OnFire) {
+			subsystem.Health = math.Max(0, subsystem.Health-subsystem.MaxHealth*overheatDamage)
+			log.Printf("Ship %s subsystem %s overheated", s.ID, subsystem.ID)
+		}
+	}
+}
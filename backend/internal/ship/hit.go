@@ -0,0 +1,112 @@
+package ship
+
+import "sort"
+
+// Ship-local axes: +Z is forward, +Y is dorsal and +X is port.
+var facingAxes = map[string]Vector3{
+	"forward":   {Z: 1},
+	"aft":       {Z: -1},
+	"port":      {X: 1},
+	"starboard": {X: -1},
+	"dorsal":    {Y: 1},
+	"ventral":   {Y: -1},
+}
+
+// Hit is damage arriving on a facing. Frequency is the weapon's modulation;
+// zero is unmodulated and ignores the shield frequency. Section, when set,
+// names the hull section struck instead of the one covering the facing.
+type Hit struct {
+	Amount    float64
+	Facing    string
+	Section   string
+	Point     Vector3
+	Frequency float64
+}
+
+type HitResult struct {
+	Facing       string  `json:"facing"`
+	Emitter      string  `json:"emitter,omitempty"`
+	Section      string  `json:"section,omitempty"`
+	ShieldDamage float64 `json:"shield_damage"`
+	BleedThrough float64 `json:"bleed_through"`
+	HullDamage   float64 `json:"hull_damage"`
+}
+
+// FacingOf returns the facing of the ship that a world-space point lies on.
+func (s *Ship) FacingOf(point Vector3) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.facingOf(point)
+}
+
+func (s *Ship) facingOf(point Vector3) string {
+	local := s.Rotation.Conjugate().Rotate(point.Sub(s.Position))
+	return closestFacing(local, []string{"forward", "aft", "port", "starboard", "dorsal", "ventral"})
+}
+
+// closestFacing picks the candidate whose axis best matches the local
+// direction. Unknown facings are ignored; an empty result means none matched.
+func closestFacing(local Vector3, candidates []string) string {
+	best := ""
+	bestDot := -2.0
+	dir := local.Normalize()
+	for _, facing := range candidates {
+		axis, ok := facingAxes[facing]
+		if !ok {
+			continue
+		}
+		if dot := dir.Dot(axis); dot > bestDot {
+			best = facing
+			bestDot = dot
+		}
+	}
+	return best
+}
+
+// TakeHit applies damage to the shield emitter and hull section covering the
+// impacted facing. Ships without a matching emitter or section fall back to
+// the one whose facing is nearest.
+func (s *Ship) TakeHit(hit Hit) HitResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.takeHit(hit)
+}
+
+func (s *Ship) takeHit(hit Hit) HitResult {
+	if hit.Facing == "" {
+		hit.Facing = s.facingOf(hit.Point)
+	}
+
+	result := HitResult{Facing: hit.Facing}
+	axis := facingAxes[hit.Facing]
+
+	amount := hit.Amount
+	if emitter := s.emitterFacing(axis); emitter != nil {
+		result.Emitter = emitter.ID
+		if s.shieldsUp() {
+			amount, result.ShieldDamage, result.BleedThrough = s.absorbHit(emitter, amount, hit.Frequency)
+		}
+	}
+
+	sectionID := hit.Section
+	if _, ok := s.Hull.Sections[sectionID]; !ok {
+		sections := make([]string, 0, len(s.Hull.Sections))
+		for id := range s.Hull.Sections {
+			sections = append(sections, id)
+		}
+		sort.Strings(sections)
+		sectionID = closestFacing(axis, sections)
+	}
+	if section, ok := s.Hull.Sections[sectionID]; ok && amount > 0 {
+		result.Section = section.ID
+		result.HullDamage = amount
+		applyHullDamage(section, amount)
+		s.injureCrew(section.ID, amount)
+		if section.Breached {
+			s.breachSection(section.ID)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,243 @@
+package ship
+
+import (
+	"celestial/internal/config"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+const (
+	defaultReactorOutput   = 1000.0
+	defaultBatteryCapacity = 10000.0
+	breakerTripDelay       = 1.0
+	// Weapons need at least this fraction of their power to fire.
+	minFirePower = 0.5
+)
+
+// Battery stores reactor surplus and covers deficits. A zero charge or
+// discharge rate is unlimited.
+type Battery struct {
+	ID           string
+	Capacity     float64
+	Charge       float64
+	MaxCharge    float64
+	MaxDischarge float64
+}
+
+func newPowerSystem(cfg config.PowerConfig) *PowerSystem {
+	p := &PowerSystem{
+		Generation: cfg.ReactorOutput,
+		Supply:     1,
+		Allocation: make(map[string]float64),
+		Batteries:  make(map[string]*Battery),
+		Breakers:   make(map[string]*Breaker),
+	}
+	if p.Generation <= 0 {
+		p.Generation = defaultReactorOutput
+	}
+
+	batteries := cfg.Batteries
+	if len(batteries) == 0 {
+		batteries = []config.BatteryConfig{{ID: "main", Capacity: defaultBatteryCapacity}}
+	}
+	for _, batCfg := range batteries {
+		p.Batteries[batCfg.ID] = &Battery{
+			ID:           batCfg.ID,
+			Capacity:     batCfg.Capacity,
+			Charge:       batCfg.Capacity,
+			MaxCharge:    batCfg.MaxCharge,
+			MaxDischarge: batCfg.MaxDischarge,
+		}
+	}
+
+	for _, brkCfg := range cfg.Breakers {
+		p.Breakers[brkCfg.ID] = &Breaker{
+			ID:      brkCfg.ID,
+			Systems: brkCfg.Systems,
+			Rating:  brkCfg.Rating,
+			Enabled: true,
+		}
+	}
+
+	p.syncCapacity()
+	return p
+}
+
+func (b *Breaker) closed() bool {
+	return b.Enabled && !b.Tripped
+}
+
+// breakerFor returns the breaker feeding a consumer known by any of groups,
+// or nil if the consumer is fed straight from the main bus. When several
+// breakers match, the first by ID wins.
+func (p *PowerSystem) breakerFor(groups ...string) *Breaker {
+	ids := make([]string, 0, len(p.Breakers))
+	for id := range p.Breakers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		breaker := p.Breakers[id]
+		for _, system := range breaker.Systems {
+			for _, group := range groups {
+				if system == group {
+					return breaker
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// discharge draws up to power from the batteries for dt and returns what
+// they delivered.
+func (p *PowerSystem) discharge(power, dt float64) float64 {
+	delivered := 0.0
+	for _, id := range p.batteryIDs() {
+		battery := p.Batteries[id]
+		available := battery.Charge / dt
+		if battery.MaxDischarge > 0 {
+			available = math.Min(available, battery.MaxDischarge)
+		}
+		draw := math.Min(power-delivered, available)
+		battery.Charge -= draw * dt
+		delivered += draw
+	}
+	return delivered
+}
+
+// charge feeds up to power into the batteries for dt.
+func (p *PowerSystem) charge(power, dt float64) {
+	for _, id := range p.batteryIDs() {
+		battery := p.Batteries[id]
+		accepted := (battery.Capacity - battery.Charge) / dt
+		if battery.MaxCharge > 0 {
+			accepted = math.Min(accepted, battery.MaxCharge)
+		}
+		fed := math.Min(power, accepted)
+		battery.Charge += fed * dt
+		power -= fed
+	}
+	p.syncCapacity()
+}
+
+func (p *PowerSystem) batteryIDs() []string {
+	ids := make([]string, 0, len(p.Batteries))
+	for id := range p.Batteries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (p *PowerSystem) syncCapacity() {
+	p.MaxCapacity, p.CurrentCapacity = 0, 0
+	for _, battery := range p.Batteries {
+		p.MaxCapacity += battery.Capacity
+		p.CurrentCapacity += battery.Charge
+	}
+}
+
+// SetBreaker opens or closes a breaker. Closing a tripped breaker resets it.
+func (s *Ship) SetBreaker(id string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	breaker, ok := s.Power.Breakers[id]
+	if !ok {
+		return fmt.Errorf("breaker not found: %s", id)
+	}
+	breaker.Enabled = enabled
+	if enabled {
+		breaker.Tripped = false
+		breaker.Overload = 0
+	}
+	return nil
+}
+
+type powerLoad struct {
+	level   *float64
+	draw    float64
+	breaker *Breaker
+}
+
+// updatePower balances the bus: systems draw in proportion to their
+// allocation, breakers carry the draw of the systems they feed and trip
+// when held over their rating, the reactor and batteries cover what they
+// can, and every powered system browns out to the fraction of its demand
+// that was met.
+func (s *Ship) updatePower(dt float64) {
+	p := s.Power
+	var loads []powerLoad
+	add := func(level *float64, draw float64, enabled bool, groups ...string) {
+		if !enabled {
+			draw = 0
+		}
+		loads = append(loads, powerLoad{level: level, draw: draw, breaker: p.breakerFor(groups...)})
+	}
+	for _, engine := range s.Engines {
+		add(&engine.PowerLevel, engine.PowerDraw*s.allocation("engines"), engine.Enabled && !engine.Overheated, "engines", engine.Type, engine.ID)
+	}
+	for _, weapon := range s.Weapons {
+		add(&weapon.PowerLevel, weapon.PowerDraw*s.allocation("weapons"), weapon.Enabled && !weapon.Overheated, "weapons", weapon.Type, weapon.ID)
+	}
+	add(&s.Shields.PowerLevel, s.Shields.PowerDraw*s.allocation("shields"), s.Shields.Enabled && !s.Shields.Overheated, "shields")
+	for _, subsystem := range s.Subsystems {
+		add(&subsystem.PowerLevel, subsystem.PowerDraw*s.allocation(subsystem.Type), subsystem.Enabled && !subsystem.Overheated, "subsystems", subsystem.Type, subsystem.ID)
+	}
+
+	for _, breaker := range p.Breakers {
+		breaker.Load = 0
+	}
+	for _, load := range loads {
+		if load.breaker != nil && load.breaker.closed() {
+			load.breaker.Load += load.draw
+		}
+	}
+	for _, breaker := range p.Breakers {
+		if breaker.Rating > 0 && breaker.Load > breaker.Rating {
+			breaker.Overload += dt
+			if breaker.Overload >= breakerTripDelay {
+				breaker.Tripped = true
+				breaker.Load = 0
+				log.Printf("Ship %s breaker %s tripped", s.ID, breaker.ID)
+			}
+		} else {
+			breaker.Overload = 0
+		}
+	}
+
+	demand := 0.0
+	for _, load := range loads {
+		if load.breaker == nil || load.breaker.closed() {
+			demand += load.draw
+		}
+	}
+
+	supply := p.Generation
+	if demand > supply {
+		supply += p.discharge(demand-supply, dt)
+	} else {
+		p.charge(supply-demand, dt)
+	}
+
+	fraction := 1.0
+	if demand > 0 {
+		fraction = math.Min(1, supply/demand)
+	}
+	for _, load := range loads {
+		if load.breaker != nil && !load.breaker.closed() {
+			*load.level = 0
+		} else {
+			*load.level = fraction
+		}
+	}
+
+	p.Demand = demand
+	p.Consumption = demand * fraction
+	p.Supply = fraction
+	p.syncCapacity()
+}
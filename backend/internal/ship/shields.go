@@ -0,0 +1,162 @@
+package ship
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// Shield and weapon frequencies run from 0 to MaxFrequency. Shields take
+	// half damage from a weapon on their own frequency and up to half again
+	// as much from one at the far end of the band.
+	MaxFrequency     = 1000.0
+	frequencyStep    = 200.0
+	minFrequencyMult = 0.5
+
+	// Reinforcing takes this share of every other emitter's strength and can
+	// overcharge the reinforced emitter up to reinforceLimit of its maximum.
+	// Overcharge decays by overchargeDecay of the maximum per second.
+	reinforceFraction = 0.25
+	reinforceLimit    = 1.5
+	overchargeDecay   = 0.05
+)
+
+func (s *Ship) shieldsUp() bool {
+	return s.Shields.Enabled && !s.Shields.Overheated
+}
+
+// emitterFacing returns the emitter whose facing best covers the local axis.
+func (s *Ship) emitterFacing(axis Vector3) *ShieldEmitter {
+	facings := make([]string, 0, len(s.Shields.Emitters))
+	byFacing := make(map[string]*ShieldEmitter, len(s.Shields.Emitters))
+	for _, emitter := range s.Shields.Emitters {
+		facings = append(facings, emitter.Facing)
+		byFacing[emitter.Facing] = emitter
+	}
+	sort.Strings(facings)
+	return byFacing[closestFacing(axis, facings)]
+}
+
+// frequencyModifier scales damage to shields by how far the weapon's
+// frequency is from the shield's.
+func frequencyModifier(shield, weapon float64) float64 {
+	if weapon == 0 {
+		return 1
+	}
+	return minFrequencyMult + math.Abs(shield-weapon)/MaxFrequency
+}
+
+// absorbHit runs a hit through an emitter. Damage to the emitter bleeds the
+// same fraction of the hit straight through to the hull; the rest is scaled
+// by frequency and drains the emitter. It returns the damage left for the
+// hull, the shield strength lost and the bleed-through.
+func (s *Ship) absorbHit(emitter *ShieldEmitter, amount, frequency float64) (float64, float64, float64) {
+	bleed := amount
+	if emitter.MaxHealth > 0 {
+		bleed = amount * (1 - math.Max(0, emitter.Health)/emitter.MaxHealth)
+	}
+	modifier := frequencyModifier(s.Shields.Frequency, frequency)
+	passed, absorbed := absorbShieldDamage(emitter, (amount-bleed)*modifier)
+	return bleed + passed/modifier, absorbed, bleed
+}
+
+// SetShieldsRaised raises or lowers the shields.
+func (s *Ship) SetShieldsRaised(raised bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Shields.Enabled = raised
+}
+
+// SetShieldFrequency retunes the shields, clamped to the band.
+func (s *Ship) SetShieldFrequency(frequency float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Shields.Frequency = math.Max(0, math.Min(MaxFrequency, frequency))
+}
+
+// RotateShieldFrequency steps the shields to the next frequency, wrapping at
+// the top of the band, and returns it.
+func (s *Ship) RotateShieldFrequency() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Shields.Frequency = math.Mod(s.Shields.Frequency+frequencyStep, MaxFrequency)
+	return s.Shields.Frequency
+}
+
+// ReinforceShields moves strength from the other emitters onto the emitter
+// covering facing. An emitter ID is accepted too.
+func (s *Ship) ReinforceShields(facing string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.Shields.Emitters[facing]
+	if !ok {
+		for _, emitter := range s.Shields.Emitters {
+			if emitter.Facing == facing {
+				target = emitter
+			}
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("no shield emitter on %s", facing)
+	}
+	if target.Health <= 0 {
+		return fmt.Errorf("shield emitter %s is destroyed", target.ID)
+	}
+
+	ids := make([]string, 0, len(s.Shields.Emitters))
+	for id := range s.Shields.Emitters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	room := target.MaxStrength*reinforceLimit - target.Strength
+	for _, id := range ids {
+		donor := s.Shields.Emitters[id]
+		if donor == target || room <= 0 {
+			continue
+		}
+		moved := math.Min(donor.Strength*reinforceFraction, room)
+		donor.Strength -= moved
+		target.Strength += moved
+		room -= moved
+	}
+	return nil
+}
+
+// updateShields recharges the emitters. Overcharge left by reinforcing
+// drains back to maximum.
+func (s *Ship) updateShields(dt float64) {
+	if !s.Shields.Enabled {
+		return
+	}
+
+	recharge := s.Shields.RechargeRate * s.output("shields", s.Shields.PowerLevel, s.Shields.Thermal) * dt
+	for _, emitter := range s.Shields.Emitters {
+		switch {
+		case emitter.Strength > emitter.MaxStrength:
+			emitter.Strength = math.Max(emitter.MaxStrength, emitter.Strength-emitter.MaxStrength*overchargeDecay*dt)
+		case emitter.Health > 0:
+			emitter.Strength = math.Min(emitter.MaxStrength, emitter.Strength+recharge)
+		}
+	}
+}
+
+// absorbShieldDamage drains the emitter and returns the damage that passes
+// through along with the amount absorbed.
+func absorbShieldDamage(emitter *ShieldEmitter, amount float64) (float64, float64) {
+	if emitter.Strength <= 0 {
+		return amount, 0
+	}
+	if amount <= emitter.Strength {
+		emitter.Strength -= amount
+		return 0, amount
+	}
+	absorbed := emitter.Strength
+	emitter.Strength = 0
+	return amount - absorbed, absorbed
+}
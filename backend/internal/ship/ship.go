@@ -2,6 +2,7 @@ package ship
 
 import (
 	"celestial/internal/config"
+	"celestial/internal/events"
 	"math"
 	"sync"
 )
@@ -13,6 +14,7 @@ type Ship struct {
 	ClassID  string
 	Name     string
 	IsPlayer bool
+	Faction  string
 
 	Position        Vector3
 	Velocity        Vector3
@@ -23,6 +25,13 @@ type Ship struct {
 	MaxSpeed     float64
 	Acceleration float64
 	TurnRate     float64
+	Radius       float64
+	SensorRange  float64
+
+	Throttle       float64
+	StrafeLateral  float64
+	StrafeVertical float64
+	Flight         FlightComputer
 
 	Engines     map[string]*Engine
 	Weapons     map[string]*Weapon
@@ -31,14 +40,33 @@ type Ship struct {
 	Subsystems  map[string]*Subsystem
 	LaunchBays  map[string]*LaunchBay
 	Power       *PowerSystem
+	Coolant     *CoolantSystem
 	LifeSupport *LifeSupportSystem
+	Suppression *SuppressionSystem
 
-	Crew map[string]*CrewMember
+	Crew  map[string]*CrewMember
+	Teams map[string]*DamageTeam
 
-	TargetID string
-	Docked   bool
+	TargetID     string
+	Disabled     bool
+	Docked       bool
+	Dock         DockingState
+	DockingPorts map[string]*DockingPort
+
+	launches []Launch
+	events   events.Publisher
 }
 
+const (
+	DefaultRadius = 50.0
+
+	reverseThrustFactor = 0.5
+	rotationalDamping   = 3.0
+	// Fallback drag for classes without acceleration or max speed, matching
+	// the old 0.98 per tick at 60 Hz.
+	defaultLinearDamping = 1.2
+)
+
 type Vector3 struct {
 	X, Y, Z float64
 }
@@ -48,20 +76,23 @@ type Quaternion struct {
 }
 
 type Engine struct {
-	ID        string
-	Type      string
-	Thrust    float64
-	MaxHealth float64
-	Health    float64
-	Enabled   bool
-	PowerDraw float64
-	OnFire    bool
+	ID         string
+	Type       string
+	Thrust     float64
+	MaxHealth  float64
+	Health     float64
+	Enabled    bool
+	PowerDraw  float64
+	PowerLevel float64
+	Thermal
+	OnFire bool
 }
 
 type Weapon struct {
 	ID           string
 	Type         string
 	Damage       float64
+	Frequency    float64
 	Range        float64
 	CooldownTime float64
 	Cooldown     float64
@@ -69,19 +100,42 @@ type Weapon struct {
 	Health       float64
 	Enabled      bool
 	PowerDraw    float64
+	PowerLevel   float64
+	Thermal
 	OnFire       bool
 	Armed        bool
 	Loaded       bool
 	Locked       bool
 	AmmoCapacity int
 	AmmoCount    int
+
+	Facing          string
+	Speed           float64
+	TurnRate        float64
+	Fuel            float64
+	ArmingDistance  float64
+	ProximityRadius float64
+	NavConstant     float64
+	Hitpoints       float64
+	Effectiveness   float64
+	Duration        float64
+}
+
+// Launch is a munition released by FireWeapon that the simulator still has
+// to spawn.
+type Launch struct {
+	WeaponID string
+	TargetID string
 }
 
 type ShieldSystem struct {
 	Emitters     map[string]*ShieldEmitter
 	RechargeRate float64
 	PowerDraw    float64
-	Enabled      bool
+	PowerLevel   float64
+	Thermal
+	Enabled   bool
+	Frequency float64
 }
 
 type ShieldEmitter struct {
@@ -109,13 +163,15 @@ type HullSection struct {
 }
 
 type Subsystem struct {
-	ID        string
-	Type      string
-	MaxHealth float64
-	Health    float64
-	Enabled   bool
-	PowerDraw float64
-	OnFire    bool
+	ID         string
+	Type       string
+	MaxHealth  float64
+	Health     float64
+	Enabled    bool
+	PowerDraw  float64
+	PowerLevel float64
+	Thermal
+	OnFire bool
 }
 
 type LaunchBay struct {
@@ -127,40 +183,87 @@ type LaunchBay struct {
 	OnFire    bool
 }
 
+// PowerSystem is the ship's power bus. MaxCapacity and CurrentCapacity are
+// the totals across all batteries; Supply is the fraction of Demand the bus
+// delivered on the last tick. Allocation holds the setpoint of each power
+// group and Preset the preset it was last set from.
 type PowerSystem struct {
 	MaxCapacity     float64
 	CurrentCapacity float64
 	Generation      float64
 	Consumption     float64
+	Demand          float64
+	Supply          float64
+	Allocation      map[string]float64
+	Preset          string
+	Batteries       map[string]*Battery
 	Breakers        map[string]*Breaker
 }
 
+// Breaker feeds the systems named in Systems, matched against a group
+// ("engines", "weapons", "shields", "subsystems"), a type or an ID. It trips
+// when Load stays above Rating.
 type Breaker struct {
-	ID      string
-	System  string
-	Enabled bool
-	Load    float64
+	ID       string
+	Systems  []string
+	Rating   float64
+	Enabled  bool
+	Tripped  bool
+	Load     float64
+	Overload float64
 }
 
+// LifeSupportSystem is the ship's compartment graph. Compartments sit behind
+// a hull section and list the compartments they share a bulkhead with.
+// Sickbay is the compartment where injured crew are treated, if any.
 type LifeSupportSystem struct {
 	Compartments map[string]*Compartment
+	Doors        map[string]*Door
+	Sickbay      string
 }
 
 type Compartment struct {
 	ID          string
+	Section     string
+	Volume      float64
+	Adjacent    []string
 	MaxPressure float64
 	Pressure    float64
 	MaxOxygen   float64
 	Oxygen      float64
-	Temperature float64
-	OnFire      bool
-	Breached    bool
-}
+	// CarbonDioxide, like Oxygen, is a percentage of the atmosphere.
+	CarbonDioxide float64
+	Temperature   float64
+	OnFire        bool
+	Breached      bool
+	Vented        bool
+
+	// Fire is the intensity of a fire from 0 to 1, burning Fuel. Ignition
+	// is the progress of a neighbouring fire toward spreading in, and
+	// Suppression the seconds left on the agent named by Suppressant.
+	Fire        float64
+	Fuel        float64
+	MaxFuel     float64
+	Ignition    float64
+	Suppression float64
+	Suppressant string
+}
+
+// Ships whose class names no faction fly for the player or against them.
+const (
+	FactionPlayer  = "player"
+	FactionHostile = "hostile"
+)
 
-type CrewMember struct {
-	Role   string
-	Health float64
-	Status string
+func defaultFaction(class *config.ShipClass, isPlayer bool) string {
+	switch {
+	case class.Faction != "":
+		return class.Faction
+	case isPlayer:
+		return FactionPlayer
+	default:
+		return FactionHostile
+	}
 }
 
 func NewShip(id, classID, name string, class *config.ShipClass, isPlayer bool) *Ship {
@@ -169,6 +272,7 @@ func NewShip(id, classID, name string, class *config.ShipClass, isPlayer bool) *
 		ClassID:         classID,
 		Name:            name,
 		IsPlayer:        isPlayer,
+		Faction:         defaultFaction(class, isPlayer),
 		Position:        Vector3{0, 0, 0},
 		Velocity:        Vector3{0, 0, 0},
 		Rotation:        Quaternion{1, 0, 0, 0},
@@ -177,22 +281,33 @@ func NewShip(id, classID, name string, class *config.ShipClass, isPlayer bool) *
 		MaxSpeed:        class.MaxSpeed,
 		Acceleration:    class.Acceleration,
 		TurnRate:        class.TurnRate,
+		Radius:          class.Radius,
+		SensorRange:     class.SensorRange,
 		Engines:         make(map[string]*Engine),
 		Weapons:         make(map[string]*Weapon),
 		Subsystems:      make(map[string]*Subsystem),
 		LaunchBays:      make(map[string]*LaunchBay),
 		Crew:            make(map[string]*CrewMember),
+		Flight:          FlightComputer{Status: FlightStatus{ETA: -1}},
+		DockingPorts:    NewDockingPorts(class.DockingPorts),
+	}
+	if ship.Radius <= 0 {
+		ship.Radius = DefaultRadius
+	}
+	if ship.SensorRange <= 0 {
+		ship.SensorRange = DefaultSensorRange
 	}
 
 	for _, engCfg := range class.Engines {
 		ship.Engines[engCfg.ID] = &Engine{
-			ID:        engCfg.ID,
-			Type:      engCfg.Type,
-			Thrust:    engCfg.Thrust,
-			MaxHealth: engCfg.Health,
-			Health:    engCfg.Health,
-			Enabled:   true,
-			PowerDraw: engCfg.PowerDraw,
+			ID:         engCfg.ID,
+			Type:       engCfg.Type,
+			Thrust:     engCfg.Thrust,
+			MaxHealth:  engCfg.Health,
+			Health:     engCfg.Health,
+			Enabled:    true,
+			PowerDraw:  engCfg.PowerDraw,
+			PowerLevel: 1,
 		}
 	}
 
@@ -201,6 +316,7 @@ func NewShip(id, classID, name string, class *config.ShipClass, isPlayer bool) *
 			ID:           wpnCfg.ID,
 			Type:         wpnCfg.Type,
 			Damage:       wpnCfg.Damage,
+			Frequency:    wpnCfg.Frequency,
 			Range:        wpnCfg.Range,
 			CooldownTime: wpnCfg.CooldownTime,
 			Cooldown:     0,
@@ -208,8 +324,20 @@ func NewShip(id, classID, name string, class *config.ShipClass, isPlayer bool) *
 			Health:       wpnCfg.Health,
 			Enabled:      true,
 			PowerDraw:    wpnCfg.PowerDraw,
+			PowerLevel:   1,
 			AmmoCapacity: wpnCfg.AmmoCapacity,
 			AmmoCount:    wpnCfg.AmmoCapacity,
+
+			Facing:          wpnCfg.Facing,
+			Speed:           wpnCfg.Speed,
+			TurnRate:        wpnCfg.TurnRate,
+			Fuel:            wpnCfg.Fuel,
+			ArmingDistance:  wpnCfg.ArmingDistance,
+			ProximityRadius: wpnCfg.ProximityRadius,
+			NavConstant:     wpnCfg.NavConstant,
+			Hitpoints:       wpnCfg.Hitpoints,
+			Effectiveness:   wpnCfg.Effectiveness,
+			Duration:        wpnCfg.Duration,
 		}
 	}
 
@@ -217,7 +345,9 @@ func NewShip(id, classID, name string, class *config.ShipClass, isPlayer bool) *
 		Emitters:     make(map[string]*ShieldEmitter),
 		RechargeRate: class.Shields.RechargeRate,
 		PowerDraw:    class.Shields.PowerDraw,
+		PowerLevel:   1,
 		Enabled:      true,
+		Frequency:    MaxFrequency / 2,
 	}
 	for _, emCfg := range class.Shields.Emitters {
 		ship.Shields.Emitters[emCfg.ID] = &ShieldEmitter{
@@ -245,12 +375,13 @@ func NewShip(id, classID, name string, class *config.ShipClass, isPlayer bool) *
 
 	for _, subCfg := range class.Subsystems {
 		ship.Subsystems[subCfg.ID] = &Subsystem{
-			ID:        subCfg.ID,
-			Type:      subCfg.Type,
-			MaxHealth: subCfg.Health,
-			Health:    subCfg.Health,
-			Enabled:   true,
-			PowerDraw: subCfg.PowerDraw,
+			ID:         subCfg.ID,
+			Type:       subCfg.Type,
+			MaxHealth:  subCfg.Health,
+			Health:     subCfg.Health,
+			Enabled:    true,
+			PowerDraw:  subCfg.PowerDraw,
+			PowerLevel: 1,
 		}
 	}
 
@@ -264,38 +395,15 @@ func NewShip(id, classID, name string, class *config.ShipClass, isPlayer bool) *
 		}
 	}
 
-	ship.Power = &PowerSystem{
-		MaxCapacity:     10000,
-		CurrentCapacity: 10000,
-		Generation:      1000,
-		Consumption:     0,
-		Breakers:        make(map[string]*Breaker),
-	}
+	ship.Power = newPowerSystem(class.Power)
+	ship.Coolant = newCoolantSystem(class.Coolant)
 
-	ship.LifeSupport = &LifeSupportSystem{
-		Compartments: make(map[string]*Compartment),
-	}
-	compartmentNames := []string{"bridge", "engineering", "weapons_bay", "crew_quarters", "cargo_bay"}
-	for _, name := range compartmentNames {
-		ship.LifeSupport.Compartments[name] = &Compartment{
-			ID:          name,
-			MaxPressure: 101.3,
-			Pressure:    101.3,
-			MaxOxygen:   21.0,
-			Oxygen:      21.0,
-			Temperature: 20.0,
-		}
-	}
+	ship.LifeSupport = newLifeSupport(class)
+	ship.Suppression = newSuppressionSystem(class.Suppression)
 
 	if isPlayer {
-		roles := []string{"engineer", "flight", "weapons", "captain", "comms", "operations", "relay", "first_officer"}
-		for _, role := range roles {
-			ship.Crew[role] = &CrewMember{
-				Role:   role,
-				Health: 100.0,
-				Status: "healthy",
-			}
-		}
+		ship.Crew = newCrew(class, ship.LifeSupport)
+		ship.Teams = newDamageTeams(class.DamageTeams, ship.LifeSupport.station(class.Stations["damage_control"]))
 	}
 
 	return ship
@@ -305,48 +413,69 @@ func (s *Ship) Update(dt float64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.updatePhysics(dt)
 	s.updatePower(dt)
+	s.updateHeat(dt)
+	s.updatePhysics(dt)
 	s.updateShields(dt)
 	s.updateWeapons(dt)
 	s.updateDamage(dt)
+	s.updateFires(dt)
 	s.updateLifeSupport(dt)
+	s.updateCrew(dt)
 }
 
 func (s *Ship) updatePhysics(dt float64) {
-	totalThrust := Vector3{0, 0, 0}
+	var mainAvailable, mainTotal, maneuverAvailable, maneuverTotal float64
 	for _, engine := range s.Engines {
-		if engine.Enabled && engine.Health > 0 {
-			thrustFactor := engine.Health / engine.MaxHealth
-			thrust := engine.Thrust * thrustFactor
-			totalThrust.Z += thrust
+		available := 0.0
+		if engine.Enabled && engine.Health > 0 && engine.MaxHealth > 0 {
+			available = engine.Thrust * engine.Health / engine.MaxHealth * s.output("engines", engine.PowerLevel, engine.Thermal)
+		}
+		if engine.Type == "maneuvering" {
+			maneuverAvailable += available
+			maneuverTotal += engine.Thrust
+		} else {
+			mainAvailable += available
+			mainTotal += engine.Thrust
 		}
 	}
 
-	forward := s.getForwardVector()
-	thrustWorld := Vector3{
-		X: forward.X * totalThrust.Z,
-		Y: forward.Y * totalThrust.Z,
-		Z: forward.Z * totalThrust.Z,
+	mainFraction := 0.0
+	if mainTotal > 0 && !s.Disabled {
+		mainFraction = mainAvailable / mainTotal
 	}
-
-	accel := Vector3{
-		X: thrustWorld.X / s.Mass,
-		Y: thrustWorld.Y / s.Mass,
-		Z: thrustWorld.Z / s.Mass,
+	// Maneuvering thrusters deliver acceleration in proportion to their
+	// share of the main drive.
+	maneuverFraction := 0.0
+	if maneuverTotal > 0 && !s.Disabled {
+		maneuverFraction = maneuverAvailable / maneuverTotal
+		if mainTotal > 0 {
+			maneuverFraction *= maneuverTotal / mainTotal
+		}
 	}
 
-	s.Velocity.X += accel.X * dt
-	s.Velocity.Y += accel.Y * dt
-	s.Velocity.Z += accel.Z * dt
+	throttle := s.Throttle
+	if throttle < 0 {
+		throttle *= reverseThrustFactor
+	}
+	local := Vector3{
+		X: -s.StrafeLateral * maneuverFraction,
+		Y: s.StrafeVertical * maneuverFraction,
+		Z: throttle * mainFraction,
+	}
+	accel := s.Rotation.Rotate(local.Scale(s.Acceleration))
 
-	drag := 0.98
-	s.Velocity.X *= drag
-	s.Velocity.Y *= drag
-	s.Velocity.Z *= drag
+	// Linear drag sized so full throttle settles at MaxSpeed, integrated
+	// exactly so the result does not depend on the tick rate.
+	damping := defaultLinearDamping
+	if s.Acceleration > 0 && s.MaxSpeed > 0 {
+		damping = s.Acceleration / s.MaxSpeed
+	}
+	terminal := accel.Scale(1 / damping)
+	s.Velocity = terminal.Add(s.Velocity.Sub(terminal).Scale(math.Exp(-damping * dt)))
 
 	speed := math.Sqrt(s.Velocity.X*s.Velocity.X + s.Velocity.Y*s.Velocity.Y + s.Velocity.Z*s.Velocity.Z)
-	if speed > s.MaxSpeed {
+	if s.MaxSpeed > 0 && speed > s.MaxSpeed {
 		scale := s.MaxSpeed / speed
 		s.Velocity.X *= scale
 		s.Velocity.Y *= scale
@@ -357,19 +486,20 @@ func (s *Ship) updatePhysics(dt float64) {
 	s.Position.Y += s.Velocity.Y * dt
 	s.Position.Z += s.Velocity.Z * dt
 
-	rotDrag := 0.95
+	rotDrag := math.Exp(-rotationalDamping * dt)
 	s.AngularVelocity.X *= rotDrag
 	s.AngularVelocity.Y *= rotDrag
 	s.AngularVelocity.Z *= rotDrag
 
-	angle := math.Sqrt(s.AngularVelocity.X*s.AngularVelocity.X+
-		s.AngularVelocity.Y*s.AngularVelocity.Y+
-		s.AngularVelocity.Z*s.AngularVelocity.Z) * dt
-	if angle > 0.001 {
+	rate := math.Sqrt(s.AngularVelocity.X*s.AngularVelocity.X +
+		s.AngularVelocity.Y*s.AngularVelocity.Y +
+		s.AngularVelocity.Z*s.AngularVelocity.Z)
+	angle := rate * dt
+	if angle > 1e-9 {
 		axis := Vector3{
-			X: s.AngularVelocity.X / angle,
-			Y: s.AngularVelocity.Y / angle,
-			Z: s.AngularVelocity.Z / angle,
+			X: s.AngularVelocity.X / rate,
+			Y: s.AngularVelocity.Y / rate,
+			Z: s.AngularVelocity.Z / rate,
 		}
 		deltaQ := axisAngleToQuaternion(axis, angle)
 		s.Rotation = multiplyQuaternions(deltaQ, s.Rotation)
@@ -377,57 +507,10 @@ func (s *Ship) updatePhysics(dt float64) {
 	}
 }
 
-func (s *Ship) updatePower(dt float64) {
-	consumption := 0.0
-	for _, engine := range s.Engines {
-		if engine.Enabled {
-			consumption += engine.PowerDraw
-		}
-	}
-	for _, weapon := range s.Weapons {
-		if weapon.Enabled {
-			consumption += weapon.PowerDraw
-		}
-	}
-	if s.Shields.Enabled {
-		consumption += s.Shields.PowerDraw
-	}
-	for _, subsystem := range s.Subsystems {
-		if subsystem.Enabled {
-			consumption += subsystem.PowerDraw
-		}
-	}
-
-	s.Power.Consumption = consumption
-	s.Power.CurrentCapacity += (s.Power.Generation - consumption) * dt
-
-	if s.Power.CurrentCapacity > s.Power.MaxCapacity {
-		s.Power.CurrentCapacity = s.Power.MaxCapacity
-	}
-	if s.Power.CurrentCapacity < 0 {
-		s.Power.CurrentCapacity = 0
-	}
-}
-
-func (s *Ship) updateShields(dt float64) {
-	if !s.Shields.Enabled {
-		return
-	}
-
-	for _, emitter := range s.Shields.Emitters {
-		if emitter.Health > 0 && emitter.Strength < emitter.MaxStrength {
-			emitter.Strength += s.Shields.RechargeRate * dt
-			if emitter.Strength > emitter.MaxStrength {
-				emitter.Strength = emitter.MaxStrength
-			}
-		}
-	}
-}
-
 func (s *Ship) updateWeapons(dt float64) {
 	for _, weapon := range s.Weapons {
 		if weapon.Cooldown > 0 {
-			weapon.Cooldown -= dt
+			weapon.Cooldown -= dt * s.output("weapons", weapon.PowerLevel, weapon.Thermal) * s.effectiveness("weapons")
 			if weapon.Cooldown < 0 {
 				weapon.Cooldown = 0
 			}
@@ -465,7 +548,7 @@ func (s *Ship) updateDamage(dt float64) {
 
 	for _, section := range s.Hull.Sections {
 		if section.OnFire {
-			section.Health -= 5.0 * dt
+			section.Health -= 5.0 * s.sectionFire(section.ID) * dt
 			if section.Health < 0 {
 				section.Health = 0
 			}
@@ -482,38 +565,35 @@ func (s *Ship) updateDamage(dt float64) {
 	}
 }
 
-func (s *Ship) updateLifeSupport(dt float64) {
-	for _, comp := range s.LifeSupport.Compartments {
-		if comp.Breached {
-			comp.Pressure -= 10.0 * dt
-			comp.Oxygen -= 2.0 * dt
-			if comp.Pressure < 0 {
-				comp.Pressure = 0
-			}
-			if comp.Oxygen < 0 {
-				comp.Oxygen = 0
-			}
-		}
-		if comp.OnFire {
-			comp.Oxygen -= 0.5 * dt
-			comp.Temperature += 10.0 * dt
-		}
-	}
+// ApplyThrust sets the helm: x is lateral strafe (positive to starboard), y is
+// vertical strafe (positive dorsal) and z is the throttle. Each is a fraction
+// from -1 to 1 and persists until changed.
+func (s *Ship) ApplyThrust(x, y, z float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.StrafeLateral = clampUnit(x)
+	s.StrafeVertical = clampUnit(y)
+	s.Throttle = clampUnit(z)
 }
 
-func (s *Ship) getForwardVector() Vector3 {
-	return Vector3{
-		X: 2 * (s.Rotation.X*s.Rotation.Z + s.Rotation.W*s.Rotation.Y),
-		Y: 2 * (s.Rotation.Y*s.Rotation.Z - s.Rotation.W*s.Rotation.X),
-		Z: 1 - 2*(s.Rotation.X*s.Rotation.X+s.Rotation.Y*s.Rotation.Y),
-	}
+func (s *Ship) SetThrottle(throttle float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Throttle = clampUnit(throttle)
 }
 
-func (s *Ship) ApplyThrust(x, y, z float64) {
+func (s *Ship) SetStrafe(lateral, vertical float64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Applied via input, actual thrust calculated in updatePhysics
+	s.StrafeLateral = clampUnit(lateral)
+	s.StrafeVertical = clampUnit(vertical)
+}
+
+func clampUnit(v float64) float64 {
+	return math.Max(-1, math.Min(1, v))
 }
 
 func (s *Ship) ApplyRotation(pitch, yaw, roll float64) {
@@ -530,11 +610,12 @@ func (s *Ship) FireWeapon(weaponID string, targetID string) bool {
 	defer s.mu.Unlock()
 
 	weapon, ok := s.Weapons[weaponID]
-	if !ok || weapon.Health <= 0 || weapon.Cooldown > 0 {
+	if !ok || s.Disabled || weapon.Health <= 0 || weapon.Cooldown > 0 || s.output("weapons", weapon.PowerLevel, weapon.Thermal) < minFirePower {
 		return false
 	}
 
-	if weapon.Type == "torpedo" {
+	switch weapon.Type {
+	case "torpedo":
 		if !weapon.Armed || !weapon.Loaded || !weapon.Locked {
 			return false
 		}
@@ -543,47 +624,240 @@ func (s *Ship) FireWeapon(weaponID string, targetID string) bool {
 		}
 		weapon.AmmoCount--
 		weapon.Loaded = false
+		s.launches = append(s.launches, Launch{WeaponID: weaponID, TargetID: targetID})
+	case "decoy":
+		if weapon.AmmoCount <= 0 {
+			return false
+		}
+		weapon.AmmoCount--
+		s.launches = append(s.launches, Launch{WeaponID: weaponID})
 	}
 
 	weapon.Cooldown = weapon.CooldownTime
-	s.TargetID = targetID
+	weapon.Heat = math.Min(MaxHeat, weapon.Heat+weaponFireHeat)
+	if targetID != "" {
+		s.TargetID = targetID
+	}
 	return true
 }
 
+// TakeLaunches returns and clears the munitions released since the last call.
+func (s *Ship) TakeLaunches() []Launch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	launches := s.launches
+	s.launches = nil
+	return launches
+}
+
+// FacingDirection returns the world-space direction of a ship facing such as
+// "forward" or "port". Unknown facings point forward.
+func (s *Ship) FacingDirection(facing string) Vector3 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Rotation.Rotate(FacingAxis(facing))
+}
+
+// TakeDamage applies unmodulated damage at a location: a facing, or the ID
+// of a shield emitter, hull section or compartment. Sections and
+// compartments off the six facings are shielded by the forward emitter.
 func (s *Ship) TakeDamage(amount float64, location string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if location == "" {
-		location = "forward"
+	hit := Hit{Amount: amount, Facing: location}
+	if emitter, ok := s.Shields.Emitters[location]; ok {
+		hit.Facing = emitter.Facing
+	}
+	if comp, ok := s.LifeSupport.Compartments[location]; ok {
+		hit.Section = comp.Section
+	}
+	if _, ok := s.Hull.Sections[location]; ok {
+		hit.Section = location
+	}
+	if _, ok := facingAxes[hit.Section]; ok {
+		hit.Facing = hit.Section
+	}
+	if _, ok := facingAxes[hit.Facing]; !ok {
+		hit.Facing = "forward"
+	}
+	s.takeHit(hit)
+}
+
+func applyHullDamage(section *HullSection, amount float64) {
+	if section.Armor > 0 {
+		section.Armor -= amount * 0.5
+		if section.Armor < 0 {
+			section.Armor = 0
+		}
+	}
+	section.Health -= amount
+	if section.Health <= 0 {
+		section.Health = 0
+		section.Breached = true
+	}
+}
+
+func (s *Ship) Clone() *Ship {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c := &Ship{
+		ID:              s.ID,
+		ClassID:         s.ClassID,
+		Name:            s.Name,
+		IsPlayer:        s.IsPlayer,
+		Faction:         s.Faction,
+		Position:        s.Position,
+		Velocity:        s.Velocity,
+		Rotation:        s.Rotation,
+		AngularVelocity: s.AngularVelocity,
+		Mass:            s.Mass,
+		MaxSpeed:        s.MaxSpeed,
+		Acceleration:    s.Acceleration,
+		TurnRate:        s.TurnRate,
+		Radius:          s.Radius,
+		SensorRange:     s.SensorRange,
+		Throttle:        s.Throttle,
+		StrafeLateral:   s.StrafeLateral,
+		StrafeVertical:  s.StrafeVertical,
+		Flight:          s.Flight,
+		Engines:         make(map[string]*Engine, len(s.Engines)),
+		Weapons:         make(map[string]*Weapon, len(s.Weapons)),
+		Subsystems:      make(map[string]*Subsystem, len(s.Subsystems)),
+		LaunchBays:      make(map[string]*LaunchBay, len(s.LaunchBays)),
+		Crew:            make(map[string]*CrewMember, len(s.Crew)),
+		TargetID:        s.TargetID,
+		Disabled:        s.Disabled,
+		Docked:          s.Docked,
+		Dock:            s.Dock,
+		DockingPorts:    CopyDockingPorts(s.DockingPorts),
+	}
+
+	for id, engine := range s.Engines {
+		e := *engine
+		c.Engines[id] = &e
+	}
+	for id, weapon := range s.Weapons {
+		w := *weapon
+		c.Weapons[id] = &w
+	}
+	for id, subsystem := range s.Subsystems {
+		sub := *subsystem
+		c.Subsystems[id] = &sub
+	}
+	for id, bay := range s.LaunchBays {
+		b := *bay
+		c.LaunchBays[id] = &b
+	}
+	for id, member := range s.Crew {
+		m := *member
+		c.Crew[id] = &m
+	}
+
+	if s.Shields != nil {
+		c.Shields = &ShieldSystem{
+			Emitters:     make(map[string]*ShieldEmitter, len(s.Shields.Emitters)),
+			RechargeRate: s.Shields.RechargeRate,
+			PowerDraw:    s.Shields.PowerDraw,
+			PowerLevel:   s.Shields.PowerLevel,
+			Thermal:      s.Shields.Thermal,
+			Frequency:    s.Shields.Frequency,
+			Enabled:      s.Shields.Enabled,
+		}
+		for id, emitter := range s.Shields.Emitters {
+			e := *emitter
+			c.Shields.Emitters[id] = &e
+		}
 	}
 
-	emitter, hasEmitter := s.Shields.Emitters[location]
-	if hasEmitter && emitter.Strength > 0 {
-		emitter.Strength -= amount
-		if emitter.Strength < 0 {
-			overflow := -emitter.Strength
-			emitter.Strength = 0
-			amount = overflow
-		} else {
-			return
+	if s.Hull != nil {
+		c.Hull = &HullSystem{Sections: make(map[string]*HullSection, len(s.Hull.Sections))}
+		for id, section := range s.Hull.Sections {
+			sec := *section
+			c.Hull.Sections[id] = &sec
 		}
 	}
 
-	section, hasSection := s.Hull.Sections[location]
-	if hasSection {
-		if section.Armor > 0 {
-			section.Armor -= amount * 0.5
-			if section.Armor < 0 {
-				section.Armor = 0
-			}
+	if s.Power != nil {
+		c.Power = &PowerSystem{
+			MaxCapacity:     s.Power.MaxCapacity,
+			CurrentCapacity: s.Power.CurrentCapacity,
+			Generation:      s.Power.Generation,
+			Consumption:     s.Power.Consumption,
+			Demand:          s.Power.Demand,
+			Supply:          s.Power.Supply,
+			Allocation:      make(map[string]float64, len(s.Power.Allocation)),
+			Preset:          s.Power.Preset,
+			Batteries:       make(map[string]*Battery, len(s.Power.Batteries)),
+			Breakers:        make(map[string]*Breaker, len(s.Power.Breakers)),
+		}
+		for group, level := range s.Power.Allocation {
+			c.Power.Allocation[group] = level
+		}
+		for id, battery := range s.Power.Batteries {
+			b := *battery
+			c.Power.Batteries[id] = &b
+		}
+		for id, breaker := range s.Power.Breakers {
+			b := *breaker
+			b.Systems = append([]string(nil), breaker.Systems...)
+			c.Power.Breakers[id] = &b
+		}
+	}
+
+	if s.Coolant != nil {
+		c.Coolant = &CoolantSystem{
+			Capacity: s.Coolant.Capacity,
+			Assigned: make(map[string]int, len(s.Coolant.Assigned)),
+		}
+		for group, units := range s.Coolant.Assigned {
+			c.Coolant.Assigned[group] = units
+		}
+	}
+
+	if s.LifeSupport != nil {
+		c.LifeSupport = &LifeSupportSystem{
+			Compartments: make(map[string]*Compartment, len(s.LifeSupport.Compartments)),
+			Doors:        make(map[string]*Door, len(s.LifeSupport.Doors)),
+			Sickbay:      s.LifeSupport.Sickbay,
+		}
+		for id, comp := range s.LifeSupport.Compartments {
+			cc := *comp
+			cc.Adjacent = append([]string(nil), comp.Adjacent...)
+			c.LifeSupport.Compartments[id] = &cc
+		}
+		for id, door := range s.LifeSupport.Doors {
+			d := *door
+			c.LifeSupport.Doors[id] = &d
 		}
-		section.Health -= amount
-		if section.Health <= 0 {
-			section.Health = 0
-			section.Breached = true
+	}
+
+	if s.Teams != nil {
+		c.Teams = make(map[string]*DamageTeam, len(s.Teams))
+		for id, team := range s.Teams {
+			t := *team
+			t.Path = append([]string(nil), team.Path...)
+			c.Teams[id] = &t
+		}
+	}
+
+	if s.Suppression != nil {
+		c.Suppression = &SuppressionSystem{
+			Capacity: make(map[string]int, len(s.Suppression.Capacity)),
+			Charges:  make(map[string]int, len(s.Suppression.Charges)),
+		}
+		for agent, n := range s.Suppression.Capacity {
+			c.Suppression.Capacity[agent] = n
+		}
+		for agent, n := range s.Suppression.Charges {
+			c.Suppression.Charges[agent] = n
 		}
 	}
+
+	return c
 }
 
 func axisAngleToQuaternion(axis Vector3, angle float64) Quaternion {
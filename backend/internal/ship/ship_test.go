@@ -2,6 +2,7 @@ package ship
 
 import (
 	"celestial/internal/config"
+	"math"
 	"testing"
 )
 
@@ -166,3 +167,809 @@ func TestShipUpdate(t *testing.T) {
 		// Position may change due to velocity updates
 	}
 }
+
+func TestTakeHitFacing(t *testing.T) {
+	class := &config.ShipClass{
+		ID:   "test_ship",
+		Mass: 100000,
+		Shields: config.ShieldConfig{
+			Emitters: []config.EmitterConfig{
+				{ID: "forward", Facing: "forward", Strength: 100, Health: 100},
+				{ID: "aft", Facing: "aft", Strength: 100, Health: 100},
+			},
+		},
+		Hull: config.HullConfig{
+			Sections: []config.HullSectionConfig{
+				{ID: "forward", Armor: 200, Health: 500},
+				{ID: "aft", Armor: 200, Health: 500},
+				{ID: "port", Armor: 200, Health: 500},
+				{ID: "starboard", Armor: 200, Health: 500},
+			},
+		},
+	}
+
+	ship := NewShip("ship_1", "test_ship", "Test Ship", class, false)
+	// Yawed 90 degrees: forward now points along world +X.
+	ship.Rotation = axisAngleToQuaternion(Vector3{Y: 1}, math.Pi/2)
+
+	result := ship.TakeHit(Hit{Amount: 150, Point: Vector3{X: 100}})
+	if result.Facing != "forward" {
+		t.Fatalf("Expected forward facing, got %s", result.Facing)
+	}
+	if ship.Shields.Emitters["forward"].Strength != 0 || ship.Shields.Emitters["aft"].Strength != 100 {
+		t.Error("Expected only the forward emitter to absorb the hit")
+	}
+	if ship.Hull.Sections["forward"].Health != 450 {
+		t.Errorf("Expected forward section health 450, got %f", ship.Hull.Sections["forward"].Health)
+	}
+
+	result = ship.TakeHit(Hit{Amount: 150, Point: Vector3{Z: 100}})
+	if result.Facing != "starboard" || result.Section != "starboard" {
+		t.Errorf("Expected starboard hit, got facing %s section %s", result.Facing, result.Section)
+	}
+	if result.ShieldDamage != 100 || result.HullDamage != 50 {
+		t.Errorf("Expected 100 shield and 50 hull damage, got %f and %f", result.ShieldDamage, result.HullDamage)
+	}
+}
+
+// testClass returns a bare ship class with each of the given changes applied.
+func testClass(changes ...func(class *config.ShipClass)) *config.ShipClass {
+	class := &config.ShipClass{
+		ID:           "test_ship",
+		Name:         "Test Ship",
+		Mass:         100000,
+		MaxSpeed:     200,
+		Acceleration: 50,
+		TurnRate:     1.0,
+	}
+	for _, change := range changes {
+		change(class)
+	}
+	return class
+}
+
+func withHelm(class *config.ShipClass) {
+	class.Engines = []config.EngineConfig{
+		{ID: "main_1", Type: "main", Thrust: 50000, Health: 100},
+		{ID: "rcs_1", Type: "maneuvering", Thrust: 10000, Health: 100},
+	}
+}
+
+func TestThrottleFrameRateIndependent(t *testing.T) {
+	coarse := NewShip("coarse", "test_ship", "Coarse", testClass(withHelm), false)
+	fine := NewShip("fine", "test_ship", "Fine", testClass(withHelm), false)
+	coarse.SetThrottle(1)
+	fine.SetThrottle(1)
+
+	for i := 0; i < 100; i++ {
+		coarse.Update(0.1)
+	}
+	for i := 0; i < 1000; i++ {
+		fine.Update(0.01)
+	}
+
+	if math.Abs(coarse.Velocity.Z-fine.Velocity.Z) > 0.01 {
+		t.Errorf("Velocity depends on tick rate: %f vs %f", coarse.Velocity.Z, fine.Velocity.Z)
+	}
+	expected := 200 * (1 - math.Exp(-0.25*10))
+	if math.Abs(fine.Velocity.Z-expected) > 0.01 {
+		t.Errorf("Expected speed %f after 10s, got %f", expected, fine.Velocity.Z)
+	}
+
+	fine.SetThrottle(0)
+	for i := 0; i < 6000; i++ {
+		fine.Update(0.01)
+	}
+	if fine.Velocity.Z > 1 {
+		t.Errorf("Ship should coast to a stop with the throttle at zero, got %f", fine.Velocity.Z)
+	}
+}
+
+func TestHelmReverseAndStrafe(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withHelm), false)
+	sh.ApplyThrust(1, 0, -2)
+	if sh.Throttle != -1 {
+		t.Fatalf("Throttle should clamp to -1, got %f", sh.Throttle)
+	}
+
+	for i := 0; i < 6000; i++ {
+		sh.Update(0.01)
+	}
+
+	// Full reverse settles at half of MaxSpeed; maneuvering thrusters with a
+	// fifth of the main thrust strafe at a fifth of MaxSpeed to starboard.
+	if math.Abs(sh.Velocity.Z+100) > 0.5 {
+		t.Errorf("Expected reverse speed -100, got %f", sh.Velocity.Z)
+	}
+	if math.Abs(sh.Velocity.X+40) > 0.5 {
+		t.Errorf("Expected starboard strafe speed -40, got %f", sh.Velocity.X)
+	}
+
+	sh.Engines["rcs_1"].Enabled = false
+	for i := 0; i < 6000; i++ {
+		sh.Update(0.01)
+	}
+	if math.Abs(sh.Velocity.X) > 0.5 {
+		t.Errorf("Strafe should stop with maneuvering thrusters offline, got %f", sh.Velocity.X)
+	}
+}
+
+func withPower(weaponRating float64) func(class *config.ShipClass) {
+	return func(class *config.ShipClass) {
+		class.Engines = []config.EngineConfig{
+			{ID: "main_1", Type: "main", Thrust: 50000, Health: 100, PowerDraw: 80},
+		}
+		class.Weapons = []config.WeaponConfig{
+			{ID: "phaser_1", Type: "phaser", Damage: 10, Range: 1000, CooldownTime: 1, Health: 100, PowerDraw: 50},
+		}
+		class.Power = config.PowerConfig{
+			ReactorOutput: 100,
+			Batteries:     []config.BatteryConfig{{ID: "bank", Capacity: 100, MaxDischarge: 50}},
+			Breakers:      []config.BreakerConfig{{ID: "weapons", Rating: weaponRating, Systems: []string{"weapons"}}},
+		}
+	}
+}
+
+func TestPowerBus(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withPower(100)), false)
+
+	sh.Update(0.1)
+	if sh.Power.Supply != 1 || sh.Weapons["phaser_1"].PowerLevel != 1 {
+		t.Fatalf("Battery should cover the deficit, got supply %f", sh.Power.Supply)
+	}
+	if load := sh.Power.Breakers["weapons"].Load; load != 50 {
+		t.Errorf("Expected breaker load 50, got %f", load)
+	}
+
+	for i := 0; i < 100; i++ {
+		sh.Update(0.1)
+	}
+	if sh.Power.CurrentCapacity > 1e-9 {
+		t.Fatalf("Expected drained battery, got %f", sh.Power.CurrentCapacity)
+	}
+	if level := sh.Engines["main_1"].PowerLevel; math.Abs(level-100.0/130.0) > 1e-9 {
+		t.Errorf("Expected engines browned out to %f, got %f", 100.0/130.0, level)
+	}
+
+	if err := sh.SetBreaker("weapons", false); err != nil {
+		t.Fatal(err)
+	}
+	sh.Update(0.1)
+	if sh.Weapons["phaser_1"].PowerLevel != 0 {
+		t.Error("Open breaker should cut power to weapons")
+	}
+	if sh.Engines["main_1"].PowerLevel != 1 {
+		t.Error("Engines should be fully powered with weapons shed")
+	}
+	if sh.FireWeapon("phaser_1", "") {
+		t.Error("Unpowered weapon should not fire")
+	}
+	if sh.Power.CurrentCapacity <= 0 {
+		t.Error("Surplus should recharge the battery")
+	}
+}
+
+func TestBreakerTrips(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withPower(40)), false)
+
+	for i := 0; i < 5; i++ {
+		sh.Update(0.1)
+	}
+	if sh.Power.Breakers["weapons"].Tripped {
+		t.Fatal("Breaker tripped before the overload delay")
+	}
+	for i := 0; i < 6; i++ {
+		sh.Update(0.1)
+	}
+	if !sh.Power.Breakers["weapons"].Tripped || sh.Weapons["phaser_1"].PowerLevel != 0 {
+		t.Fatal("Overloaded breaker should trip and cut power")
+	}
+
+	sh.Weapons["phaser_1"].Enabled = false
+	if err := sh.SetBreaker("weapons", true); err != nil {
+		t.Fatal(err)
+	}
+	sh.Update(0.1)
+	if sh.Power.Breakers["weapons"].Tripped {
+		t.Error("Closing the breaker should reset the trip")
+	}
+}
+
+func TestPowerAllocation(t *testing.T) {
+	boosted := NewShip("boosted", "test_ship", "Boosted", testClass(withPower(0)), false)
+	nominal := NewShip("nominal", "test_ship", "Nominal", testClass(withPower(0)), false)
+	boosted.Power.Generation = 1000
+	nominal.Power.Generation = 1000
+	if err := boosted.SetAllocation("engines", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := boosted.SetAllocation("warp_core", 1); err == nil {
+		t.Error("Expected error for unknown power group")
+	}
+	boosted.SetThrottle(1)
+	nominal.SetThrottle(1)
+
+	for i := 0; i < 10; i++ {
+		boosted.Update(0.1)
+		nominal.Update(0.1)
+	}
+	if boosted.Velocity.Z <= nominal.Velocity.Z*1.5 {
+		t.Errorf("Boosted engines should outrun nominal: %f vs %f", boosted.Velocity.Z, nominal.Velocity.Z)
+	}
+	for i := 0; i < 40; i++ {
+		boosted.Update(0.1)
+		nominal.Update(0.1)
+	}
+	if heat := boosted.Engines["main_1"].Heat; heat <= 2*nominal.Engines["main_1"].Heat-1e-6 {
+		t.Errorf("Engines at 200%% should heat twice as fast as nominal, got %f", heat)
+	}
+	if heat := nominal.Engines["main_1"].Heat; heat <= 0 || heat >= efficiencyHeat {
+		t.Errorf("Nominal engines should warm without losing efficiency, got %f", heat)
+	}
+
+	if err := boosted.ApplyPowerPreset("silent_running"); err != nil {
+		t.Fatal(err)
+	}
+	boosted.Update(0.1)
+	if boosted.FireWeapon("phaser_1", "") {
+		t.Error("Weapons should be offline when running silent")
+	}
+	if boosted.Power.Preset != "silent_running" || boosted.Power.Allocation["engines"] != 0.25 {
+		t.Errorf("Preset not applied: %v", boosted.Power.Allocation)
+	}
+}
+
+func TestHeatAndCoolant(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withPower(0)), false)
+	sh.Power.Generation = 1000
+	if err := sh.SetAllocation("engines", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 60; i++ {
+		sh.Update(0.1)
+	}
+	engine := sh.Engines["main_1"]
+	if !engine.Overheated || !engine.OnFire {
+		t.Fatalf("Engines at 300%% should catch fire and shut down, heat %f", engine.Heat)
+	}
+	if engine.Health >= engine.MaxHealth {
+		t.Error("Overheating should damage the engine")
+	}
+	if engine.PowerLevel != 1 || sh.output("engines", engine.PowerLevel, engine.Thermal) != 0 {
+		t.Error("Overheated engine should produce no output")
+	}
+
+	if err := sh.AssignCoolant("engines", 9); err == nil {
+		t.Error("Expected error assigning more coolant than the ship carries")
+	}
+	if err := sh.AssignCoolant("engines", 4); err != nil {
+		t.Fatal(err)
+	}
+	if sh.Coolant.Available() != DefaultCoolant-4 {
+		t.Errorf("Expected %d coolant units left, got %d", DefaultCoolant-4, sh.Coolant.Available())
+	}
+	for i := 0; i < 30; i++ {
+		sh.Update(0.1)
+	}
+	if engine.Overheated {
+		t.Fatalf("Engine should restart once cooled, heat %f", engine.Heat)
+	}
+	heat := engine.Heat
+	sh.Update(0.1)
+	if engine.Heat > heat {
+		t.Errorf("Four coolant units should hold 300%% engines, heat rose %f -> %f", heat, engine.Heat)
+	}
+
+	weapon := sh.Weapons["phaser_1"]
+	heat = weapon.Heat
+	sh.FireWeapon("phaser_1", "")
+	if weapon.Heat != heat+weaponFireHeat {
+		t.Errorf("Expected firing to add %f heat, got %f", weaponFireHeat, weapon.Heat-heat)
+	}
+}
+
+func TestNominalHeat(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withPower(0)), false)
+	sh.Power.Generation = 1000
+
+	for i := 0; i < 600; i++ {
+		sh.Update(0.1)
+	}
+	engine := sh.Engines["main_1"]
+	if engine.Heat < 40 || engine.Heat > efficiencyHeat || engine.Overheated {
+		t.Fatalf("Engines at 100%% should run warm but efficient, heat %f", engine.Heat)
+	}
+
+	if err := sh.AssignCoolant("engines", 1); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 600; i++ {
+		sh.Update(0.1)
+	}
+	if engine.Heat > 30 {
+		t.Errorf("A coolant unit should bring engines at 100%% down to about 24, heat %f", engine.Heat)
+	}
+
+	if err := sh.AssignCoolant("engines", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.SetAllocation("engines", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 600; i++ {
+		sh.Update(0.1)
+	}
+	if engine.Heat <= efficiencyHeat {
+		t.Errorf("Uncooled engines at 150%% should lose efficiency, heat %f", engine.Heat)
+	}
+}
+
+func withFacings(class *config.ShipClass) {
+	class.Shields.Emitters = []config.EmitterConfig{
+		{ID: "forward", Facing: "forward", Strength: 100, Health: 100},
+		{ID: "aft", Facing: "aft", Strength: 100, Health: 100},
+		{ID: "port", Facing: "port", Strength: 100, Health: 100},
+	}
+	class.Hull.Sections = []config.HullSectionConfig{
+		{ID: "forward", Armor: 200, Health: 500},
+		{ID: "aft", Armor: 200, Health: 500},
+	}
+}
+
+func TestShieldFrequencyAndBleedThrough(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withFacings), false)
+	sh.SetShieldFrequency(400)
+
+	result := sh.TakeHit(Hit{Amount: 40, Facing: "forward", Frequency: 400})
+	if result.ShieldDamage != 20 || result.HullDamage != 0 {
+		t.Errorf("Matched frequency should halve shield damage, got %f shield %f hull", result.ShieldDamage, result.HullDamage)
+	}
+	result = sh.TakeHit(Hit{Amount: 40, Facing: "aft", Frequency: 900})
+	if result.ShieldDamage != 40 {
+		t.Errorf("Expected unscaled shield damage 40 at 500 apart, got %f", result.ShieldDamage)
+	}
+
+	sh.Shields.Emitters["port"].Health = 25
+	result = sh.TakeHit(Hit{Amount: 40, Facing: "port"})
+	if result.BleedThrough != 30 || result.ShieldDamage != 10 || result.HullDamage != 30 {
+		t.Errorf("Expected 30 bleed-through and 10 absorbed, got %+v", result)
+	}
+
+	sh.SetShieldsRaised(false)
+	result = sh.TakeHit(Hit{Amount: 40, Facing: "forward"})
+	if result.ShieldDamage != 0 || result.HullDamage != 40 {
+		t.Errorf("Lowered shields should not absorb, got %+v", result)
+	}
+
+	if f := sh.RotateShieldFrequency(); f != 600 {
+		t.Errorf("Expected frequency 600 after rotating, got %f", f)
+	}
+}
+
+func TestReinforceShields(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withFacings), false)
+
+	if err := sh.ReinforceShields("forward"); err != nil {
+		t.Fatal(err)
+	}
+	forward := sh.Shields.Emitters["forward"]
+	if forward.Strength != 150 || sh.Shields.Emitters["aft"].Strength != 75 || sh.Shields.Emitters["port"].Strength != 75 {
+		t.Errorf("Expected 150/75/75 after reinforcing, got %f/%f/%f",
+			forward.Strength, sh.Shields.Emitters["aft"].Strength, sh.Shields.Emitters["port"].Strength)
+	}
+	if err := sh.ReinforceShields("ventral"); err == nil {
+		t.Error("Expected error reinforcing a facing without an emitter")
+	}
+
+	sh.Update(1)
+	if forward.Strength >= 150 {
+		t.Error("Overcharge should drain back toward maximum")
+	}
+}
+
+// withCompartments lays out compartments over the sections withFacings adds.
+func withCompartments(class *config.ShipClass) {
+	class.Compartments = []config.CompartmentConfig{
+		{ID: "bridge", Section: "forward", Volume: 50},
+		{ID: "quarters", Section: "forward", Adjacent: []string{"engine_room"}},
+		{ID: "engine_room", Section: "aft", Volume: 200},
+	}
+	class.Doors = []config.DoorConfig{
+		{ID: "bridge_quarters", Connects: []string{"bridge", "quarters"}},
+		{ID: "quarters_engine_room", Connects: []string{"quarters", "engine_room"}, Closed: true},
+	}
+}
+
+func TestCompartmentGraph(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withFacings, withCompartments), false)
+
+	if got := sh.Neighbours("quarters"); len(got) != 2 || got[0] != "bridge" || got[1] != "engine_room" {
+		t.Errorf("Expected quarters to border bridge and engine_room, got %v", got)
+	}
+	if got := sh.CompartmentsAt("forward"); len(got) != 2 || got[0] != "bridge" || got[1] != "quarters" {
+		t.Errorf("Expected forward section to hold bridge and quarters, got %v", got)
+	}
+	if !sh.Connected("bridge", "quarters") || sh.Connected("quarters", "engine_room") {
+		t.Error("Only the open door should connect compartments")
+	}
+
+	sh.Shields.Enabled = false
+	sh.TakeDamage(1000, "engine_room")
+	if !sh.Hull.Sections["aft"].Breached || !sh.LifeSupport.Compartments["engine_room"].Breached {
+		t.Fatal("Destroying the aft section should breach the engine room")
+	}
+	if sh.LifeSupport.Compartments["bridge"].Breached {
+		t.Error("Bridge should not be breached by an aft hit")
+	}
+
+	sh.Update(1)
+	if p := sh.LifeSupport.Compartments["engine_room"].Pressure; p != 101.3-5 {
+		t.Errorf("Expected double-volume compartment to vent at half rate, got %f", p)
+	}
+	if p := sh.LifeSupport.Compartments["quarters"].Pressure; p != 101.3 {
+		t.Errorf("Closed door should hold pressure, got %f", p)
+	}
+
+	if err := sh.SetDoor("quarters_engine_room", true); err != nil {
+		t.Fatal(err)
+	}
+	sh.Update(1)
+	if p := sh.LifeSupport.Compartments["quarters"].Pressure; p >= 101.3 {
+		t.Errorf("Expected open door to vent quarters, got %f", p)
+	}
+
+	if err := sh.SealBreach("engine_room"); err != nil {
+		t.Fatal(err)
+	}
+	if sh.Hull.Sections["aft"].Breached || sh.LifeSupport.Compartments["engine_room"].Breached {
+		t.Error("Sealing the only breached compartment should seal its section")
+	}
+
+	if err := sh.StartFire("bridge"); err != nil {
+		t.Fatal(err)
+	}
+	if !sh.Hull.Sections["forward"].OnFire {
+		t.Error("Fire in the bridge should set its section alight")
+	}
+	if err := sh.StartFire("nowhere"); err == nil {
+		t.Error("Expected an error starting a fire at an unknown location")
+	}
+	if err := sh.SetDoor("nowhere", true); err == nil {
+		t.Error("Expected an error for an unknown door")
+	}
+}
+
+func TestDefaultCompartments(t *testing.T) {
+	class := testClass(withFacings)
+	class.Hull.Sections = append(class.Hull.Sections, config.HullSectionConfig{ID: "port", Health: 100})
+	sh := NewShip("ship_1", "test_ship", "Test Ship", class, false)
+
+	if len(sh.LifeSupport.Compartments) != 3 {
+		t.Fatalf("Expected a compartment per hull section, got %d", len(sh.LifeSupport.Compartments))
+	}
+	if got := sh.Neighbours("port"); len(got) != 2 {
+		t.Errorf("Expected port to border forward and aft, got %v", got)
+	}
+	if got := sh.Neighbours("forward"); len(got) != 1 || got[0] != "port" {
+		t.Errorf("Opposite facings should not be adjacent, got %v", got)
+	}
+	if !sh.Connected("forward", "port") {
+		t.Error("Default compartments should be joined by open doors")
+	}
+}
+
+func withLifeSupport(class *config.ShipClass) {
+	class.Subsystems = []config.SubsystemConfig{
+		{ID: "life_support", Type: "life_support", Health: 100},
+	}
+	class.Stations = map[string]string{"engineer": "engine_room"}
+}
+
+func TestAtmosphereFlow(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withFacings, withCompartments, withLifeSupport), true)
+	if sh.Crew["engineer"].Compartment != "engine_room" || sh.Crew["captain"].Compartment != "bridge" {
+		t.Fatalf("Expected crew at their stations, got %s and %s", sh.Crew["engineer"].Compartment, sh.Crew["captain"].Compartment)
+	}
+
+	bridge := sh.LifeSupport.Compartments["bridge"]
+	quarters := sh.LifeSupport.Compartments["quarters"]
+	quarters.Pressure = 50
+	before := bridge.Pressure*bridge.Volume + quarters.Pressure*quarters.Volume
+	sh.Subsystems["life_support"].Enabled = false
+	for i := 0; i < 200; i++ {
+		sh.Update(0.1)
+	}
+	after := bridge.Pressure*bridge.Volume + quarters.Pressure*quarters.Volume
+	if math.Abs(bridge.Pressure-quarters.Pressure) > 0.1 || math.Abs(before-after) > 1e-6 {
+		t.Errorf("Expected the open door to equalize without losing gas, got %f and %f kPa", bridge.Pressure, quarters.Pressure)
+	}
+	if bridge.CarbonDioxide <= 0 {
+		t.Error("Crew should have breathed out carbon dioxide")
+	}
+
+	sh.Subsystems["life_support"].Enabled = true
+	for i := 0; i < 600; i++ {
+		sh.Update(0.1)
+	}
+	if bridge.CarbonDioxide > 0.01 || bridge.Pressure < 101 {
+		t.Errorf("Life support should scrub and repressurize, got %f%% CO2 at %f kPa", bridge.CarbonDioxide, bridge.Pressure)
+	}
+}
+
+func TestCompartmentTemperature(t *testing.T) {
+	class := testClass(withFacings, withCompartments, withLifeSupport)
+	cooled := NewShip("cooled", "test_ship", "Cooled", class, false)
+	uncooled := NewShip("uncooled", "test_ship", "Uncooled", class, false)
+	vented := NewShip("vented", "test_ship", "Vented", class, false)
+	uncooled.Subsystems["life_support"].Enabled = false
+	if err := vented.VentCompartment("engine_room", true); err != nil {
+		t.Fatal(err)
+	}
+	for _, sh := range []*Ship{cooled, uncooled, vented} {
+		sh.LifeSupport.Compartments["engine_room"].Temperature = 80
+	}
+
+	for i := 0; i < 100; i++ {
+		cooled.Update(0.1)
+		uncooled.Update(0.1)
+		vented.Update(0.1)
+	}
+	hot := uncooled.LifeSupport.Compartments["engine_room"].Temperature
+	warm := cooled.LifeSupport.Compartments["engine_room"].Temperature
+	cold := vented.LifeSupport.Compartments["engine_room"].Temperature
+	if hot >= 80 || warm >= hot || cold >= warm || cold < ambientTemperature {
+		t.Errorf("Expected the hull, then life support, then venting to cool faster toward ambient, got %f, %f and %f", hot, warm, cold)
+	}
+
+	if err := cooled.StartFire("bridge"); err != nil {
+		t.Fatal(err)
+	}
+	before := cooled.LifeSupport.Compartments["bridge"].Temperature
+	cooled.Update(1)
+	if cooled.LifeSupport.Compartments["bridge"].Temperature <= before {
+		t.Error("A fire should heat its compartment")
+	}
+}
+
+func TestVentingPutsOutFire(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withFacings, withCompartments, withLifeSupport), true)
+	if err := sh.StartFire("engine_room"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.VentCompartment("engine_room", true); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 300 && sh.LifeSupport.Compartments["engine_room"].OnFire; i++ {
+		sh.Update(0.1)
+	}
+	if sh.LifeSupport.Compartments["engine_room"].OnFire || sh.Hull.Sections["aft"].OnFire {
+		t.Fatal("Venting should starve the fire of oxygen")
+	}
+
+	for i := 0; i < 20; i++ {
+		sh.Update(0.1)
+	}
+	engineer := sh.Crew["engineer"]
+	if engineer.Health >= 100 || engineer.Status == "healthy" {
+		t.Errorf("Crew in a vented compartment should be harmed, got %f (%s)", engineer.Health, engineer.Status)
+	}
+	if sh.Crew["captain"].Health != 100 {
+		t.Errorf("Crew behind a closed door should be unharmed, got %f", sh.Crew["captain"].Health)
+	}
+	if err := sh.VentCompartment("nowhere", true); err == nil {
+		t.Error("Expected an error venting an unknown compartment")
+	}
+}
+
+func TestFireSpreadAndSuppression(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withFacings, withCompartments), false)
+	quarters := sh.LifeSupport.Compartments["quarters"]
+	bridge := sh.LifeSupport.Compartments["bridge"]
+	engineRoom := sh.LifeSupport.Compartments["engine_room"]
+
+	if err := sh.StartFire("quarters"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 600 && !bridge.OnFire; i++ {
+		sh.Update(0.1)
+	}
+	if !bridge.OnFire {
+		t.Fatalf("Fire should spread through the open door, quarters at %f", quarters.Fire)
+	}
+	if engineRoom.OnFire {
+		t.Error("Fire should spread through a closed door more slowly than an open one")
+	}
+	if quarters.Fire <= fireStartLevel || quarters.Fuel >= quarters.MaxFuel {
+		t.Errorf("Fire should grow and burn fuel, got %f intensity and %f fuel", quarters.Fire, quarters.Fuel)
+	}
+
+	if err := sh.Suppress("forward", "halon"); err != nil {
+		t.Fatal(err)
+	}
+	if sh.Suppression.Charges["halon"] != defaultHalon-2 {
+		t.Errorf("Expected a halon charge per compartment, %d left", sh.Suppression.Charges["halon"])
+	}
+	for i := 0; i < 50; i++ {
+		sh.Update(0.1)
+	}
+	if quarters.OnFire || bridge.OnFire || sh.Hull.Sections["forward"].OnFire {
+		t.Errorf("Halon should put out the forward fires, got %f and %f", quarters.Fire, bridge.Fire)
+	}
+	if quarters.Suppression != 0 || quarters.Suppressant != "" {
+		t.Error("Suppressant should be spent")
+	}
+
+	if err := sh.Suppress("quarters", "water"); err == nil {
+		t.Error("Expected an error for an unknown suppressant")
+	}
+	sh.Suppression.Charges["foam"] = 0
+	if err := sh.Suppress("quarters", "foam"); err == nil {
+		t.Error("Expected an error with no foam left")
+	}
+	if err := sh.Suppress("quarters", ""); err != nil || sh.Suppression.Charges["halon"] != defaultHalon-3 {
+		t.Errorf("Expected the default suppressant to be halon, got %v", err)
+	}
+}
+
+func TestDamageTeamRoute(t *testing.T) {
+	sh := NewShip("ship_1", "test_ship", "Test Ship", testClass(withFacings, withCompartments), true)
+	if len(sh.Teams) != defaultDamageTeams || sh.Teams["alpha"].Compartment != "bridge" {
+		t.Fatalf("Expected %d teams on the bridge, got %v", defaultDamageTeams, sh.Teams)
+	}
+
+	path, err := sh.Route("bridge", "engine_room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 2 || path[0] != "quarters" || path[1] != "engine_room" {
+		t.Errorf("Expected a route through quarters and the closed door, got %v", path)
+	}
+	if path, _ := sh.Route("bridge", "bridge"); len(path) != 0 {
+		t.Errorf("Expected an empty route in place, got %v", path)
+	}
+	if _, err := sh.Route("bridge", "nowhere"); err == nil {
+		t.Error("Expected an error routing to an unknown compartment")
+	}
+
+	clone := sh.Clone()
+	clone.Teams["alpha"].Compartment = "quarters"
+	if sh.Teams["alpha"].Compartment != "bridge" {
+		t.Error("Clone should deep-copy damage control teams")
+	}
+	if npc := NewShip("ship_2", "test_ship", "NPC", testClass(withFacings, withCompartments), false); len(npc.Teams) != 0 {
+		t.Error("Only player ships should have damage control teams")
+	}
+}
+
+func TestCrewCasualtiesAndSickbay(t *testing.T) {
+	class := testClass(withFacings, withCompartments, withLifeSupport)
+	class.Crew = map[string]int{"weapons": 2, "medical": 1}
+	class.Stations = map[string]string{"engineer": "engine_room", "weapons": "engine_room", "sickbay": "bridge"}
+	sh := NewShip("ship_1", "test_ship", "Test Ship", class, true)
+	if len(sh.Crew) != 10 || sh.Crew["weapons_2"] == nil || sh.Crew["medical"] == nil {
+		t.Fatalf("Expected a complement of 10 with two weapons crew and a medic, got %d", len(sh.Crew))
+	}
+	if sh.LifeSupport.Sickbay != "bridge" {
+		t.Fatalf("Expected the bridge as sickbay, got %q", sh.LifeSupport.Sickbay)
+	}
+
+	sh.Shields.Enabled = false
+	sh.TakeHit(Hit{Amount: 450, Section: "aft"})
+	weapons, weapons2 := sh.Crew["weapons"], sh.Crew["weapons_2"]
+	if weapons.Health != 55 || weapons.Status != "injured" || sh.Crew["captain"].Health != 100 {
+		t.Fatalf("Expected a hull hit to injure only the crew behind it, got %f (%s)", weapons.Health, weapons.Status)
+	}
+	if e := sh.Effectiveness("weapons"); e != 1 {
+		t.Errorf("Injured crew above half health should keep the station running, got %f", e)
+	}
+
+	weapons2.Health = 0
+	if e := sh.Effectiveness("weapons"); e != 0.5 {
+		t.Errorf("Expected half effectiveness with one of two crew lost, got %f", e)
+	}
+
+	weapons.Health = 30
+	sh.Update(0.1)
+	if weapons.Compartment != "bridge" || weapons.Status != "in_sickbay" {
+		t.Fatalf("Expected the badly injured to go to sickbay, got %s (%s)", weapons.Compartment, weapons.Status)
+	}
+	if e := sh.Effectiveness("weapons"); e != minStationEffectiveness {
+		t.Errorf("Expected an empty station to fall back to %f, got %f", minStationEffectiveness, e)
+	}
+
+	for i := 0; i < 400; i++ {
+		sh.Update(0.1)
+	}
+	if weapons.Health != 100 || weapons.Compartment != "engine_room" || weapons.Status != "healthy" {
+		t.Errorf("Expected treatment to return the crew to duty, got %f in %s (%s)", weapons.Health, weapons.Compartment, weapons.Status)
+	}
+	if weapons2.Health != 0 || weapons2.Compartment != "engine_room" {
+		t.Error("The dead should stay where they fell")
+	}
+}
+
+func TestAssessDestruction(t *testing.T) {
+	class := testClass(withFacings)
+	class.Engines = []config.EngineConfig{{ID: "main", Type: "main", Thrust: 1000, Health: 100}}
+	class.Subsystems = []config.SubsystemConfig{{ID: "core", Type: "reactor", Health: 100}}
+	rules := config.DestructionConfig{CoreSections: []string{"aft"}, DisabledHull: 0.5}
+	sh := NewShip("ship_1", "test_ship", "Test Ship", class, false)
+
+	if destroyed, disabled := sh.Assess(rules); destroyed != "" || disabled != "" {
+		t.Fatalf("An intact ship should fight on, got %q %q", destroyed, disabled)
+	}
+
+	sh.Hull.Sections["forward"].Health = 0
+	if destroyed, disabled := sh.Assess(rules); destroyed != "" || disabled != CauseHull {
+		t.Errorf("Expected half the hull gone to disable the ship, got %q %q", destroyed, disabled)
+	}
+	sh.Hull.Sections["forward"].Health = 500
+
+	sh.Engines["main"].Health = 0
+	if _, disabled := sh.Assess(rules); disabled != CauseEngines {
+		t.Errorf("Expected losing the main drive to disable the ship, got %q", disabled)
+	}
+	sh.Engines["main"].Health = 100
+	sh.SetDisabled(true)
+	sh.ApplyThrust(0, 0, 1)
+	sh.Update(1)
+	if sh.Velocity.Length() != 0 {
+		t.Errorf("A disabled ship should not thrust, got %+v", sh.Velocity)
+	}
+
+	sh.Subsystems["core"].Health = 0
+	if destroyed, _ := sh.Assess(rules); destroyed != CauseReactor {
+		t.Errorf("Expected a reactor breach, got %q", destroyed)
+	}
+	if damage, radius := Explosion(rules, CauseReactor); damage != 2*defaultExplosionDamage || radius != 2*defaultExplosionRadius {
+		t.Errorf("Expected a reactor breach to double the blast, got %f over %f", damage, radius)
+	}
+
+	sh.Subsystems["core"].Health = 100
+	sh.Hull.Sections["aft"].Health = 0
+	if destroyed, _ := sh.Assess(rules); destroyed != CauseCoreSection {
+		t.Errorf("Expected losing a core section to destroy the ship, got %q", destroyed)
+	}
+}
+
+func TestSetSystem(t *testing.T) {
+	class := testClass(withFacings)
+	class.Engines = []config.EngineConfig{{ID: "main", Type: "main", Thrust: 1000, Health: 100}}
+	sh := NewShip("ship_1", "test_ship", "Test Ship", class, false)
+
+	if err := sh.SetSystem("engine", "main", "health", 40); err != nil || sh.Engines["main"].Health != 40 {
+		t.Errorf("Expected engine health 40, got %f (%v)", sh.Engines["main"].Health, err)
+	}
+	if err := sh.SetSystem("engine", "main", "enabled", 0); err != nil || sh.Engines["main"].Enabled {
+		t.Errorf("Expected the engine switched off (%v)", err)
+	}
+	if err := sh.SetSystem("shield", "aft", "strength", 500); err != nil || sh.Shields.Emitters["aft"].Strength != 100 {
+		t.Errorf("Expected shield strength held at its maximum, got %f (%v)", sh.Shields.Emitters["aft"].Strength, err)
+	}
+	if err := sh.SetSystem("hull", "forward", "armor", -5); err != nil || sh.Hull.Sections["forward"].Armor != 0 {
+		t.Errorf("Expected armor held at zero, got %f (%v)", sh.Hull.Sections["forward"].Armor, err)
+	}
+	if err := sh.SetSystem("hull", "forward", "enabled", 1); err == nil {
+		t.Error("Expected hull sections to have no enabled switch")
+	}
+	if err := sh.SetSystem("weapon", "missing", "health", 1); err == nil {
+		t.Error("Expected an unknown weapon to be refused")
+	}
+}
+
+func TestTakeDamageNamedSection(t *testing.T) {
+	class := testClass(withFacings)
+	class.Hull.Sections = append(class.Hull.Sections, config.HullSectionConfig{ID: "bridge", Health: 300})
+	sh := NewShip("ship_1", "test_ship", "Test Ship", class, false)
+	sh.SetShieldsRaised(false)
+
+	sh.TakeDamage(40, "bridge")
+	if sh.Hull.Sections["bridge"].Health != 260 {
+		t.Errorf("Expected bridge health 260, got %f", sh.Hull.Sections["bridge"].Health)
+	}
+	if sh.Hull.Sections["aft"].Health != 500 || sh.Hull.Sections["forward"].Health != 500 {
+		t.Error("Damage aimed at the bridge should not land on a facing section")
+	}
+}
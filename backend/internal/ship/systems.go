@@ -0,0 +1,71 @@
+package ship
+
+import (
+	"fmt"
+	"math"
+)
+
+// SetSystem sets a property of one of the ship's systems: health on any of
+// them, enabled on engines, weapons and subsystems, strength on shield
+// emitters and armor on hull sections. Values are held between zero and
+// the system's maximum, and enabled is on for any non-zero value.
+func (s *Ship) SetSystem(systemType, systemID, property string, value float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var health, maxHealth *float64
+	var enabled *bool
+	var level, maxLevel *float64
+	found := false
+
+	switch systemType {
+	case "engine":
+		if engine, ok := s.Engines[systemID]; ok {
+			found = true
+			health, maxHealth, enabled = &engine.Health, &engine.MaxHealth, &engine.Enabled
+		}
+	case "weapon":
+		if weapon, ok := s.Weapons[systemID]; ok {
+			found = true
+			health, maxHealth, enabled = &weapon.Health, &weapon.MaxHealth, &weapon.Enabled
+		}
+	case "subsystem":
+		if subsystem, ok := s.Subsystems[systemID]; ok {
+			found = true
+			health, maxHealth, enabled = &subsystem.Health, &subsystem.MaxHealth, &subsystem.Enabled
+		}
+	case "shield":
+		if emitter, ok := s.Shields.Emitters[systemID]; ok {
+			found = true
+			health, maxHealth = &emitter.Health, &emitter.MaxHealth
+			if property == "strength" {
+				level, maxLevel = &emitter.Strength, &emitter.MaxStrength
+			}
+		}
+	case "hull":
+		if section, ok := s.Hull.Sections[systemID]; ok {
+			found = true
+			health, maxHealth = &section.Health, &section.MaxHealth
+			if property == "armor" {
+				level, maxLevel = &section.Armor, &section.MaxArmor
+			}
+		}
+	default:
+		return fmt.Errorf("unknown system type: %s", systemType)
+	}
+	if !found {
+		return fmt.Errorf("%s not found: %s", systemType, systemID)
+	}
+
+	switch {
+	case property == "health":
+		*health = math.Max(0, math.Min(*maxHealth, value))
+	case property == "enabled" && enabled != nil:
+		*enabled = value != 0
+	case level != nil:
+		*level = math.Max(0, math.Min(*maxLevel, value))
+	default:
+		return fmt.Errorf("%s has no property %s", systemType, property)
+	}
+	return nil
+}
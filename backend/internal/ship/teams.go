@@ -0,0 +1,112 @@
+package ship
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	defaultDamageTeams = 3
+
+	TeamStandingBy   = "standing_by"
+	TeamMoving       = "moving"
+	TeamFirefighting = "firefighting"
+	TeamSealing      = "sealing"
+	TeamRepairing    = "repairing"
+	TeamLost         = "lost"
+)
+
+var teamNames = []string{"alpha", "beta", "gamma", "delta", "epsilon", "zeta"}
+
+// TeamName returns the ID of the i'th damage control team.
+func TeamName(i int) string {
+	if i >= 0 && i < len(teamNames) {
+		return teamNames[i]
+	}
+	return fmt.Sprintf("team_%d", i+1)
+}
+
+// DamageTeam is a damage control team. It walks Path one compartment at a
+// time toward Target, then fights fire, seals breaches and repairs there.
+// SystemType and SystemID name a system it was sent to repair as well as
+// the hull. Progress is the time spent on the current step or task and ETA
+// the estimated time to finish.
+type DamageTeam struct {
+	ID          string
+	Home        string
+	Compartment string
+	Target      string
+	SystemType  string
+	SystemID    string
+	Path        []string
+	Status      string
+	Health      float64
+	Progress    float64
+	ETA         float64
+}
+
+func newDamageTeams(count int, home string) map[string]*DamageTeam {
+	if count <= 0 {
+		count = defaultDamageTeams
+	}
+	teams := make(map[string]*DamageTeam, count)
+	for i := 0; i < count; i++ {
+		id := TeamName(i)
+		teams[id] = &DamageTeam{
+			ID:          id,
+			Home:        home,
+			Compartment: home,
+			Status:      TeamStandingBy,
+			Health:      100,
+		}
+	}
+	return teams
+}
+
+// Route returns the compartments a team passes through, door by door, from
+// one compartment to another, not counting the first. Teams pass closed
+// doors as well as open ones and leave them as they found them.
+func (s *Ship) Route(from, to string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.route(from, to)
+}
+
+func (s *Ship) route(from, to string) ([]string, error) {
+	ls := s.LifeSupport
+	if ls.Compartments[from] == nil || ls.Compartments[to] == nil {
+		return nil, fmt.Errorf("no route from %s to %s", from, to)
+	}
+
+	doors := make(map[string][]string)
+	for _, door := range ls.Doors {
+		doors[door.A] = append(doors[door.A], door.B)
+		doors[door.B] = append(doors[door.B], door.A)
+	}
+	for _, next := range doors {
+		sort.Strings(next)
+	}
+
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 && queue[0] != to {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range doors[current] {
+			if _, seen := prev[next]; !seen {
+				prev[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	if _, ok := prev[to]; !ok {
+		return nil, fmt.Errorf("no route from %s to %s", from, to)
+	}
+
+	var path []string
+	for at := to; at != from; at = prev[at] {
+		path = append([]string{at}, path...)
+	}
+	return path, nil
+}
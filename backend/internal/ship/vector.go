@@ -0,0 +1,50 @@
+package ship
+
+import "math"
+
+func (v Vector3) Add(o Vector3) Vector3 {
+	return Vector3{X: v.X + o.X, Y: v.Y + o.Y, Z: v.Z + o.Z}
+}
+
+func (v Vector3) Sub(o Vector3) Vector3 {
+	return Vector3{X: v.X - o.X, Y: v.Y - o.Y, Z: v.Z - o.Z}
+}
+
+func (v Vector3) Scale(f float64) Vector3 {
+	return Vector3{X: v.X * f, Y: v.Y * f, Z: v.Z * f}
+}
+
+func (v Vector3) Dot(o Vector3) float64 {
+	return v.X*o.X + v.Y*o.Y + v.Z*o.Z
+}
+
+func (v Vector3) Cross(o Vector3) Vector3 {
+	return Vector3{
+		X: v.Y*o.Z - v.Z*o.Y,
+		Y: v.Z*o.X - v.X*o.Z,
+		Z: v.X*o.Y - v.Y*o.X,
+	}
+}
+
+func (v Vector3) Length() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+func (v Vector3) Normalize() Vector3 {
+	length := v.Length()
+	if length < 1e-9 {
+		return Vector3{}
+	}
+	return v.Scale(1 / length)
+}
+
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// Rotate applies the rotation q to v.
+func (q Quaternion) Rotate(v Vector3) Vector3 {
+	u := Vector3{X: q.X, Y: q.Y, Z: q.Z}
+	t := u.Cross(v).Scale(2)
+	return v.Add(t.Scale(q.W)).Add(u.Cross(t))
+}
@@ -0,0 +1,185 @@
+package simulation
+
+import (
+	"celestial/internal/config"
+	"celestial/internal/events"
+	"celestial/internal/ship"
+	"celestial/internal/spatial"
+	"log"
+)
+
+const (
+	collisionRestitution    = 0.3
+	collisionDamagePerSpeed = 0.5
+	minCollisionDamageSpeed = 5.0
+	collisionDamageCooldown = 1.0
+)
+
+type objectBody struct {
+	Radius       float64
+	Mass         float64
+	DockingPorts []config.DockingPortConfig
+}
+
+// objectBodies gives collidable object types their default size, mass and
+// docking ports. Types not listed, such as waypoints and decoys, do not
+// collide.
+var objectBodies = map[string]objectBody{
+	"asteroid": {Radius: 40, Mass: 5e6},
+	"debris":   {Radius: 10, Mass: 1e4},
+	"station": {Radius: 250, DockingPorts: []config.DockingPortConfig{
+		{ID: "forward_port", Facing: "forward"},
+		{ID: "aft_port", Facing: "aft"},
+	}},
+}
+
+// body is one side of a contact. An inverse mass of zero is immovable.
+type body struct {
+	id       string
+	position *ship.Vector3
+	velocity *ship.Vector3
+	radius   float64
+	invMass  float64
+	ship     *ship.Ship
+}
+
+func shipBody(sh *ship.Ship) body {
+	b := body{
+		id:       sh.ID,
+		position: &sh.Position,
+		velocity: &sh.Velocity,
+		radius:   sh.Radius,
+		ship:     sh,
+	}
+	if b.radius <= 0 {
+		b.radius = ship.DefaultRadius
+	}
+	if sh.Mass > 0 {
+		b.invMass = 1 / sh.Mass
+	}
+	return b
+}
+
+func objectBodyOf(obj *Object) body {
+	b := body{
+		id:       obj.ID,
+		position: &obj.Position,
+		velocity: &obj.Velocity,
+		radius:   obj.Radius,
+	}
+	if obj.Mass > 0 {
+		b.invMass = 1 / obj.Mass
+	}
+	return b
+}
+
+func (s *Simulator) checkCollisions() {
+	s.index.Pairs(func(e *spatial.Entry) bool {
+		return e.Kind == spatial.KindShip || (e.Kind == spatial.KindObject && e.Radius > 0)
+	}, func(a, b *spatial.Entry) {
+		if a.Kind == spatial.KindObject && b.Kind == spatial.KindObject {
+			return
+		}
+		if a.Kind == spatial.KindObject {
+			a, b = b, a
+		}
+
+		shipA, ok := s.Ships[a.ID]
+		if !ok || s.dockedWith(shipA, b.ID) {
+			return
+		}
+		if b.Kind == spatial.KindShip {
+			if shipB, ok := s.Ships[b.ID]; ok && !s.dockedWith(shipB, a.ID) {
+				s.resolveContact(shipBody(shipA), shipBody(shipB))
+			}
+			return
+		}
+		if obj, ok := s.Objects[b.ID]; ok {
+			s.resolveContact(shipBody(shipA), objectBodyOf(obj))
+		}
+	})
+
+	for key, until := range s.collisionCooldowns {
+		if s.CurrentTime >= until {
+			delete(s.collisionCooldowns, key)
+		}
+	}
+}
+
+// dockedWith reports whether sh is docking with, or docked to, hostID. Ships
+// cleared to dock do not collide with their host.
+func (s *Simulator) dockedWith(sh *ship.Ship, hostID string) bool {
+	dock := sh.DockingState()
+	return dock.Status != "" && dock.HostID == hostID
+}
+
+// resolveContact separates two overlapping bodies, exchanges momentum along
+// the contact normal and damages ships by closing speed.
+func (s *Simulator) resolveContact(a, b body) {
+	delta := b.position.Sub(*a.position)
+	dist := delta.Length()
+	minDist := a.radius + b.radius
+	if dist >= minDist {
+		return
+	}
+
+	totalInvMass := a.invMass + b.invMass
+	if totalInvMass == 0 {
+		return
+	}
+
+	normal := ship.Vector3{Z: 1}
+	if dist > 1e-6 {
+		normal = delta.Scale(1 / dist)
+	}
+
+	penetration := minDist - dist
+	*a.position = a.position.Sub(normal.Scale(penetration * a.invMass / totalInvMass))
+	*b.position = b.position.Add(normal.Scale(penetration * b.invMass / totalInvMass))
+
+	closing := a.velocity.Sub(*b.velocity).Dot(normal)
+	if closing <= 0 {
+		return
+	}
+
+	impulse := (1 + collisionRestitution) * closing / totalInvMass
+	*a.velocity = a.velocity.Sub(normal.Scale(impulse * a.invMass))
+	*b.velocity = b.velocity.Add(normal.Scale(impulse * b.invMass))
+
+	if closing < minCollisionDamageSpeed {
+		return
+	}
+
+	key := a.id + "|" + b.id
+	if b.id < a.id {
+		key = b.id + "|" + a.id
+	}
+	if until, ok := s.collisionCooldowns[key]; ok && s.CurrentTime < until {
+		return
+	}
+	s.collisionCooldowns[key] = s.CurrentTime + collisionDamageCooldown
+
+	// The lighter body absorbs more of the impact; an immovable body
+	// takes none.
+	damage := collisionDamagePerSpeed * closing * 2
+	data := map[string]interface{}{
+		"a_id":          a.id,
+		"b_id":          b.id,
+		"closing_speed": closing,
+	}
+	if a.ship != nil {
+		point := a.position.Add(normal.Scale(a.radius))
+		result := a.ship.TakeHit(ship.Hit{Amount: damage * a.invMass / totalInvMass, Point: point})
+		data["a_facing"] = result.Facing
+		data["a_damage"] = damage * a.invMass / totalInvMass
+	}
+	if b.ship != nil {
+		point := b.position.Sub(normal.Scale(b.radius))
+		result := b.ship.TakeHit(ship.Hit{Amount: damage * b.invMass / totalInvMass, Point: point})
+		data["b_facing"] = result.Facing
+		data["b_damage"] = damage * b.invMass / totalInvMass
+	}
+
+	log.Printf("Collision between %s and %s at %.1f u/s", a.id, b.id, closing)
+	s.emit(events.Collision, data)
+}
@@ -0,0 +1,42 @@
+package simulation
+
+import (
+	"celestial/internal/damage"
+	"fmt"
+)
+
+// updateDamageControl moves and works the damage control teams of every
+// ship that has them.
+func (s *Simulator) updateDamageControl() {
+	for _, sh := range s.Ships {
+		if len(sh.Teams) > 0 {
+			damage.NewDamageController(sh).Update(s.dt)
+		}
+	}
+}
+
+// DeployTeam sends a ship's damage control team to a compartment or hull
+// section, optionally to repair a system there. An empty teamID sends the
+// nearest team standing by. It returns the team sent.
+func (s *Simulator) DeployTeam(shipID, teamID, location, systemType, systemID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh, ok := s.Ships[shipID]
+	if !ok {
+		return "", fmt.Errorf("ship not found: %s", shipID)
+	}
+	return damage.NewDamageController(sh).Deploy(teamID, location, systemType, systemID)
+}
+
+// RecallTeam sends a ship's damage control team back to its station.
+func (s *Simulator) RecallTeam(shipID, teamID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh, ok := s.Ships[shipID]
+	if !ok {
+		return fmt.Errorf("ship not found: %s", shipID)
+	}
+	return damage.NewDamageController(sh).Recall(teamID)
+}
@@ -0,0 +1,177 @@
+package simulation
+
+import (
+	"celestial/internal/config"
+	"celestial/internal/events"
+	"celestial/internal/ship"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+const (
+	// A stripped wreck breaks up wreckBreakupTime seconds after the last of
+	// its stores is salvaged.
+	wreckBreakupTime = 60.0
+)
+
+// Wrecks have a single airlock for boarding parties and salvage.
+var wreckPorts = []config.DockingPortConfig{
+	{ID: "airlock", Facing: "dorsal"},
+}
+
+func (s *Simulator) destructionRules(sh *ship.Ship) config.DestructionConfig {
+	if class, ok := s.ShipClasses[sh.ClassID]; ok {
+		return class.Destruction
+	}
+	return config.DestructionConfig{}
+}
+
+// updateDestruction checks every ship against its class's destruction rules
+// after the tick's damage is done, blowing up the lost and disabling or
+// restoring the rest.
+func (s *Simulator) updateDestruction() {
+	ids := make([]string, 0, len(s.Ships))
+	for id := range s.Ships {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		sh, ok := s.Ships[id]
+		if !ok {
+			continue
+		}
+		destroyed, disabled := sh.Assess(s.destructionRules(sh))
+		if destroyed != "" {
+			s.destroyShip(sh, destroyed)
+			continue
+		}
+		if (disabled != "") != sh.Disabled {
+			sh.SetDisabled(disabled != "")
+			if disabled != "" {
+				s.emit(events.ShipDisabled, map[string]interface{}{
+					"ship_id": sh.ID,
+					"cause":   disabled,
+				})
+			}
+		}
+	}
+}
+
+// DestroyShip blows up a ship at once, as if it had been shot to pieces.
+func (s *Simulator) DestroyShip(id, cause string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh, ok := s.Ships[id]
+	if !ok {
+		return fmt.Errorf("ship not found: %s", id)
+	}
+	s.destroyShip(sh, cause)
+	return nil
+}
+
+// destroyShip replaces a ship with its wreck and damages everything caught
+// in the explosion.
+func (s *Simulator) destroyShip(sh *ship.Ship, cause string) {
+	s.vacatePort(sh.ID, sh.DockingState())
+	delete(s.Ships, sh.ID)
+	delete(s.AIControllers, sh.ID)
+
+	wreck := &Object{
+		ID:       s.wreckID(sh.ID),
+		Type:     "wreck",
+		Position: sh.Position,
+		Velocity: sh.Velocity,
+		Rotation: sh.Rotation,
+		Radius:   s.hitRadius(sh),
+		Mass:     sh.Mass,
+		Data: map[string]interface{}{
+			"ship_id":  sh.ID,
+			"class_id": sh.ClassID,
+			"name":     sh.Name,
+			"cause":    cause,
+			"salvage":  float64(sh.Stores()),
+		},
+		DockingPorts: ship.NewDockingPorts(wreckPorts),
+	}
+	if sh.Stores() == 0 {
+		wreck.Data["expires_at"] = s.CurrentTime + wreckBreakupTime
+	}
+	s.Objects[wreck.ID] = wreck
+
+	damage, radius := ship.Explosion(s.destructionRules(sh), cause)
+	log.Printf("Ship %s destroyed (%s)", sh.ID, cause)
+	s.emit(events.ShipDestroyed, map[string]interface{}{
+		"ship_id":   sh.ID,
+		"name":      sh.Name,
+		"class_id":  sh.ClassID,
+		"is_player": sh.IsPlayer,
+		"cause":     cause,
+		"wreck_id":  wreck.ID,
+		"position":  map[string]float64{"x": sh.Position.X, "y": sh.Position.Y, "z": sh.Position.Z},
+	})
+	s.explode(sh.ID, sh.Position, damage, radius)
+}
+
+// wreckID names the wreck of a ship wreck_<id>, numbering it if an earlier
+// ship by that ID left a wreck that is still there.
+func (s *Simulator) wreckID(shipID string) string {
+	id := "wreck_" + shipID
+	for n := 2; s.Objects[id] != nil; n++ {
+		id = fmt.Sprintf("wreck_%s_%d", shipID, n)
+	}
+	return id
+}
+
+// explode damages every ship within radius of a blast, falling off linearly
+// from the centre to nothing at the edge.
+func (s *Simulator) explode(sourceID string, at ship.Vector3, damage, radius float64) {
+	ids := make([]string, 0, len(s.Ships))
+	for id := range s.Ships {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		sh := s.Ships[id]
+		d := math.Max(0, distance(sh.Position, at)-s.hitRadius(sh))
+		if d >= radius {
+			continue
+		}
+		amount := damage * (1 - d/radius)
+		result := sh.TakeHit(ship.Hit{Amount: amount, Point: at})
+		log.Printf("Explosion of %s hit ship %s on %s facing for %.1f damage", sourceID, id, result.Facing, amount)
+	}
+	s.emit(events.Explosion, map[string]interface{}{
+		"source_id": sourceID,
+		"damage":    damage,
+		"radius":    radius,
+		"position":  map[string]float64{"x": at.X, "y": at.Y, "z": at.Z},
+	})
+}
+
+// salvage moves stores from a wreck to a ship docked with it. A stripped
+// wreck breaks up a while later.
+func (s *Simulator) salvage(sh *ship.Ship, wreck *Object) {
+	left, _ := wreck.Data["salvage"].(float64)
+	if left <= 0 {
+		return
+	}
+	taken := sh.Salvage(s.dt, int(left))
+	if taken == 0 {
+		return
+	}
+	left -= float64(taken)
+	wreck.Data["salvage"] = left
+	if left <= 0 {
+		wreck.Data["expires_at"] = s.CurrentTime + wreckBreakupTime
+		log.Printf("Ship %s stripped wreck %s", sh.ID, wreck.ID)
+		s.emit(events.WreckSalvaged, map[string]interface{}{
+			"ship_id":  sh.ID,
+			"wreck_id": wreck.ID,
+		})
+	}
+}
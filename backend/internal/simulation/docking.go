@@ -0,0 +1,239 @@
+package simulation
+
+import (
+	"celestial/internal/events"
+	"celestial/internal/ship"
+	"fmt"
+	"log"
+	"math"
+)
+
+const (
+	dockingGap    = 5.0
+	captureRadius = 15.0
+	clampDuration = 5.0
+	releaseSpeed  = 5.0
+)
+
+// dockingHost is a ship or object that other ships can dock with.
+type dockingHost struct {
+	position ship.Vector3
+	velocity ship.Vector3
+	rotation ship.Quaternion
+	radius   float64
+	ports    map[string]*ship.DockingPort
+}
+
+func (s *Simulator) dockingHost(id string) (dockingHost, bool) {
+	if sh, ok := s.Ships[id]; ok {
+		return dockingHost{sh.Position, sh.Velocity, sh.Rotation, sh.Radius, sh.DockingPorts}, true
+	}
+	if obj, ok := s.Objects[id]; ok {
+		return dockingHost{obj.Position, obj.Velocity, obj.Rotation, obj.Radius, obj.DockingPorts}, true
+	}
+	return dockingHost{}, false
+}
+
+// berth returns where the centre of a docked ship of the given radius sits
+// and the outward axis of the port.
+func (h dockingHost) berth(port *ship.DockingPort, radius float64) (ship.Vector3, ship.Vector3) {
+	axis := h.rotation.Rotate(ship.FacingAxis(port.Facing))
+	return h.position.Add(axis.Scale(h.radius + radius + dockingGap)), axis
+}
+
+// RequestDocking clears a ship to approach a docking port on hostID. An empty
+// portID assigns the nearest free port.
+func (s *Simulator) RequestDocking(shipID, hostID, portID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh, ok := s.Ships[shipID]
+	if !ok {
+		return fmt.Errorf("ship not found: %s", shipID)
+	}
+	if sh.DockingState().Status != "" {
+		return fmt.Errorf("ship %s is already docking", shipID)
+	}
+	host, ok := s.dockingHost(hostID)
+	if !ok || hostID == shipID {
+		return fmt.Errorf("docking host not found: %s", hostID)
+	}
+
+	var port *ship.DockingPort
+	if portID != "" {
+		port = host.ports[portID]
+		if port == nil {
+			return fmt.Errorf("no docking port %s on %s", portID, hostID)
+		}
+		if port.OccupiedBy != "" {
+			return fmt.Errorf("docking port %s is occupied", portID)
+		}
+	} else {
+		best := math.Inf(1)
+		for _, candidate := range host.ports {
+			if candidate.OccupiedBy != "" {
+				continue
+			}
+			berth, _ := host.berth(candidate, sh.Radius)
+			if d := distance(sh.Position, berth); d < best || (d == best && candidate.ID < port.ID) {
+				best, port = d, candidate
+			}
+		}
+		if port == nil {
+			return fmt.Errorf("no free docking port on %s", hostID)
+		}
+	}
+
+	port.OccupiedBy = shipID
+	sh.SetDockingState(ship.DockingState{Status: ship.DockingApproach, HostID: hostID, PortID: port.ID})
+	log.Printf("Ship %s cleared to dock at %s port %s", shipID, hostID, port.ID)
+	return nil
+}
+
+// ReleaseDocking opens the clamps of a docked ship and pushes it clear, or
+// cancels an approach.
+func (s *Simulator) ReleaseDocking(shipID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh, ok := s.Ships[shipID]
+	if !ok {
+		return fmt.Errorf("ship not found: %s", shipID)
+	}
+	dock := sh.DockingState()
+	if dock.Status == "" {
+		return fmt.Errorf("ship %s is not docked", shipID)
+	}
+
+	if host, ok := s.dockingHost(dock.HostID); ok {
+		if port := host.ports[dock.PortID]; port != nil && dock.Status != ship.DockingApproach {
+			_, axis := host.berth(port, sh.Radius)
+			sh.HoldAt(sh.Position, host.velocity.Add(axis.Scale(releaseSpeed)))
+		}
+	}
+	s.endDocking(sh, dock, "released")
+	return nil
+}
+
+// endDocking frees the port and reports the ship as undocked, or the
+// approach as aborted if the clamps never closed.
+func (s *Simulator) endDocking(sh *ship.Ship, dock ship.DockingState, reason string) {
+	s.vacatePort(sh.ID, dock)
+	sh.SetDockingState(ship.DockingState{})
+
+	data := map[string]interface{}{
+		"ship_id": sh.ID,
+		"host_id": dock.HostID,
+		"port_id": dock.PortID,
+		"reason":  reason,
+	}
+	if dock.Status == ship.DockingDocked {
+		s.emit(events.Undocked, data)
+		log.Printf("Ship %s undocked from %s (%s)", sh.ID, dock.HostID, reason)
+	} else {
+		s.emit(events.DockingAborted, data)
+		log.Printf("Ship %s aborted docking with %s (%s)", sh.ID, dock.HostID, reason)
+	}
+}
+
+func (s *Simulator) vacatePort(shipID string, dock ship.DockingState) {
+	if dock.Status == "" {
+		return
+	}
+	if host, ok := s.dockingHost(dock.HostID); ok {
+		if port := host.ports[dock.PortID]; port != nil && port.OccupiedBy == shipID {
+			port.OccupiedBy = ""
+		}
+	}
+}
+
+// updateDocking runs the docking procedure after ship physics: ships on
+// approach are captured once inside the berth from within the corridor and
+// the port's speed and alignment tolerances, clamped ships are drawn onto the berth, and docked
+// ships ride along with their host and are resupplied.
+func (s *Simulator) updateDocking() {
+	for _, sh := range s.Ships {
+		dock := sh.DockingState()
+		if dock.Status == "" {
+			continue
+		}
+
+		host, ok := s.dockingHost(dock.HostID)
+		var port *ship.DockingPort
+		if ok {
+			port = host.ports[dock.PortID]
+		}
+		if port == nil {
+			s.endDocking(sh, dock, "host_lost")
+			continue
+		}
+		berth, axis := host.berth(port, sh.Radius)
+
+		switch dock.Status {
+		case ship.DockingApproach:
+			offset := sh.Position.Sub(berth)
+			along := offset.Dot(axis)
+			lateral := offset.Sub(axis.Scale(along)).Length()
+			relative := sh.Velocity.Sub(host.velocity)
+			forward := sh.Rotation.Rotate(ship.Vector3{Z: 1})
+			tolerance := port.AlignmentTolerance * math.Pi / 180
+
+			dock.Distance = offset.Length()
+			dock.ClosingSpeed = -relative.Dot(offset.Normalize())
+			dock.AlignmentError = math.Acos(math.Max(-1, math.Min(1, -forward.Dot(axis)))) * 180 / math.Pi
+			// The corridor is a cone opening outward from the berth, so the
+			// capture sphere only counts when it is entered from the front.
+			dock.InCorridor = along >= -dockingGap && along <= port.ApproachLength &&
+				lateral <= dockingGap+math.Max(0, along)*math.Tan(tolerance)
+
+			if dock.Distance <= captureRadius {
+				if !dock.InCorridor {
+					s.endDocking(sh, dock, "out_of_corridor")
+					continue
+				}
+				if relative.Length() > port.MaxApproachSpeed {
+					s.endDocking(sh, dock, "too_fast")
+					continue
+				}
+				if dock.AlignmentError > port.AlignmentTolerance {
+					s.endDocking(sh, dock, "misaligned")
+					continue
+				}
+				dock.Status = ship.DockingClamping
+				dock.Timer = clampDuration
+				sh.Disengage()
+				s.emit(events.DockingClamping, map[string]interface{}{
+					"ship_id": sh.ID,
+					"host_id": dock.HostID,
+					"port_id": dock.PortID,
+				})
+			}
+			sh.SetDockingState(dock)
+
+		case ship.DockingClamping:
+			remaining := math.Max(0, dock.Timer-s.dt)
+			sh.HoldAt(berth.Add(sh.Position.Sub(berth).Scale(remaining/dock.Timer)), host.velocity)
+			dock.Timer = remaining
+			dock.Distance = distance(sh.Position, berth)
+			dock.ClosingSpeed = 0
+			if remaining == 0 {
+				dock.Status = ship.DockingDocked
+				s.emit(events.Docked, map[string]interface{}{
+					"ship_id": sh.ID,
+					"host_id": dock.HostID,
+					"port_id": dock.PortID,
+				})
+				log.Printf("Ship %s docked at %s port %s", sh.ID, dock.HostID, dock.PortID)
+			}
+			sh.SetDockingState(dock)
+
+		case ship.DockingDocked:
+			sh.HoldAt(berth, host.velocity)
+			if obj, ok := s.Objects[dock.HostID]; ok && obj.Type == "wreck" {
+				s.salvage(sh, obj)
+			} else {
+				sh.Resupply(s.dt)
+			}
+		}
+	}
+}
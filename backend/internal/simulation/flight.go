@@ -0,0 +1,53 @@
+package simulation
+
+import (
+	"celestial/internal/events"
+	"celestial/internal/ship"
+)
+
+// updateFlight runs every engaged flight computer ahead of the ship physics
+// so the helm it sets takes effect this tick.
+func (s *Simulator) updateFlight() {
+	for id, sh := range s.Ships {
+		flight := sh.FlightState()
+		if flight.Mode == ship.FlightManual {
+			continue
+		}
+
+		var target *ship.FlightTarget
+		if flight.TargetID != "" {
+			position, velocity, ok := s.contactState(flight.TargetID)
+			if !ok {
+				sh.Disengage()
+				s.emit(events.AutopilotDisengaged, map[string]interface{}{
+					"ship_id":   id,
+					"mode":      flight.Mode,
+					"target_id": flight.TargetID,
+					"reason":    "target_lost",
+				})
+				continue
+			}
+			target = &ship.FlightTarget{Position: position, Velocity: velocity}
+		}
+
+		status := sh.Fly(target)
+		if status.Arrived && !flight.Status.Arrived {
+			s.emit(events.AutopilotArrived, map[string]interface{}{
+				"ship_id":   id,
+				"mode":      flight.Mode,
+				"target_id": flight.TargetID,
+			})
+		}
+	}
+}
+
+// contactState returns the position and velocity of any ship or object.
+func (s *Simulator) contactState(id string) (ship.Vector3, ship.Vector3, bool) {
+	if sh, ok := s.Ships[id]; ok {
+		return sh.Position, sh.Velocity, true
+	}
+	if obj, ok := s.Objects[id]; ok {
+		return obj.Position, obj.Velocity, true
+	}
+	return ship.Vector3{}, ship.Vector3{}, false
+}
@@ -0,0 +1,333 @@
+package simulation
+
+import (
+	"celestial/internal/events"
+	"celestial/internal/ship"
+	"celestial/internal/spatial"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+)
+
+const (
+	defaultTorpedoSpeed     = 500.0
+	defaultTorpedoTurnRate  = 1.5
+	defaultTorpedoFuel      = 20.0
+	defaultArmingDistance   = 200.0
+	defaultProximityRadius  = 30.0
+	defaultNavConstant      = 4.0
+	defaultTorpedoHitpoints = 10.0
+	torpedoCoastTime        = 10.0
+
+	defaultDecoyDuration      = 8.0
+	defaultDecoyEffectiveness = 0.6
+	decoyEjectSpeed           = 50.0
+	decoyRadius               = 10.0
+	decoySeekerRange          = 3000.0
+	countermeasureRange       = 1500.0
+)
+
+// chance maps a pair of IDs to a fixed number in [0, 1), so an engagement
+// plays out the same way every run.
+func chance(a, b string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(a))
+	h.Write([]byte{0})
+	h.Write([]byte(b))
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
+
+func orDefault(value, fallback float64) float64 {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// processLaunches spawns the munitions ships released during the previous
+// tick. Launches are queued on the ship because weapons are fired from code
+// that already holds the simulator lock, such as the AI.
+func (s *Simulator) processLaunches() {
+	for _, sh := range s.Ships {
+		for _, launch := range sh.TakeLaunches() {
+			weapon, ok := sh.Weapons[launch.WeaponID]
+			if !ok {
+				continue
+			}
+
+			switch weapon.Type {
+			case "torpedo":
+				s.launchTorpedo(sh, weapon, launch.TargetID)
+			case "decoy":
+				s.deployDecoy(sh, weapon)
+			}
+		}
+	}
+}
+
+func (s *Simulator) launchTorpedo(sh *ship.Ship, weapon *ship.Weapon, targetID string) {
+	speed := orDefault(weapon.Speed, defaultTorpedoSpeed)
+	fuel := orDefault(weapon.Fuel, defaultTorpedoFuel)
+	dir := sh.FacingDirection(weapon.Facing)
+	velocity := sh.Velocity.Add(dir.Scale(speed))
+
+	id := fmt.Sprintf("torpedo_%s_%s_%d", sh.ID, weapon.ID, s.TickCount)
+	s.Projectiles[id] = &Projectile{
+		ID:          id,
+		Type:        "torpedo",
+		Position:    sh.Position,
+		Velocity:    velocity,
+		Damage:      weapon.Damage,
+		Frequency:   weapon.Frequency,
+		SourceID:    sh.ID,
+		TargetID:    targetID,
+		MaxLifetime: fuel + torpedoCoastTime,
+
+		Guided:          true,
+		TrackID:         targetID,
+		Speed:           orDefault(velocity.Length(), speed),
+		TurnRate:        orDefault(weapon.TurnRate, defaultTorpedoTurnRate),
+		Fuel:            fuel,
+		NavConstant:     orDefault(weapon.NavConstant, defaultNavConstant),
+		ArmingDistance:  orDefault(weapon.ArmingDistance, defaultArmingDistance),
+		ProximityRadius: orDefault(weapon.ProximityRadius, defaultProximityRadius),
+		Health:          orDefault(weapon.Hitpoints, defaultTorpedoHitpoints),
+	}
+
+	log.Printf("Ship %s launched torpedo %s at %s", sh.ID, id, targetID)
+	s.emit(events.TorpedoLaunched, map[string]interface{}{
+		"projectile_id": id,
+		"source_id":     sh.ID,
+		"weapon_id":     weapon.ID,
+		"target_id":     targetID,
+	})
+}
+
+// guideTorpedo steers a torpedo at its tracked contact with proportional
+// navigation, limited by its turn rate. Once the fuel is spent it coasts.
+func (s *Simulator) guideTorpedo(proj *Projectile) {
+	if proj.Fuel <= 0 {
+		return
+	}
+	proj.Fuel -= s.dt
+
+	targetPos, targetVel, ok := s.trackState(proj.TrackID)
+	if !ok {
+		proj.TrackID = ""
+		return
+	}
+
+	r := targetPos.Sub(proj.Position)
+	r2 := r.Dot(r)
+	if r2 < 1e-6 {
+		return
+	}
+
+	vel := proj.Velocity
+	maxAccel := proj.Speed * proj.TurnRate
+	var accel ship.Vector3
+	if r.Dot(vel) <= 0 {
+		// Target behind the seeker: the line of sight barely rotates, so
+		// turn hard towards it until PN can take over.
+		dir := vel.Normalize()
+		lateral := r.Sub(dir.Scale(r.Dot(dir)))
+		if lateral.Length() < 1e-6 {
+			lateral = dir.Cross(ship.Vector3{Y: 1})
+			if lateral.Length() < 1e-6 {
+				lateral = dir.Cross(ship.Vector3{X: 1})
+			}
+		}
+		accel = lateral.Normalize().Scale(maxAccel)
+	} else {
+		omega := r.Cross(targetVel.Sub(vel)).Scale(1 / r2)
+		accel = omega.Cross(vel).Scale(proj.NavConstant)
+		if accel.Length() > maxAccel {
+			accel = accel.Normalize().Scale(maxAccel)
+		}
+	}
+
+	proj.Velocity = vel.Add(accel.Scale(s.dt)).Normalize().Scale(proj.Speed)
+}
+
+// trackState returns the position and velocity of whatever a torpedo is
+// homing on: a ship or a decoy.
+func (s *Simulator) trackState(id string) (ship.Vector3, ship.Vector3, bool) {
+	if id == "" {
+		return ship.Vector3{}, ship.Vector3{}, false
+	}
+	if sh, ok := s.Ships[id]; ok {
+		return sh.Position, sh.Velocity, true
+	}
+	if obj, ok := s.Objects[id]; ok && obj.Type == "decoy" {
+		return obj.Position, obj.Velocity, true
+	}
+	return ship.Vector3{}, ship.Vector3{}, false
+}
+
+// checkProximity detonates an armed torpedo that passed within its proximity
+// radius of the contact it is tracking this tick.
+func (s *Simulator) checkProximity(id string, proj *Projectile, start ship.Vector3) bool {
+	if target, ok := s.Ships[proj.TrackID]; ok {
+		targetStart := target.Position.Sub(target.Velocity.Scale(s.dt))
+		t, dist := closestApproach(start.Sub(targetStart), proj.Position.Sub(target.Position))
+		if dist > s.hitRadius(target)+proj.ProximityRadius {
+			return false
+		}
+		point := start.Add(proj.Position.Sub(start).Scale(t))
+		s.applyProjectileHit(id, proj, target, point, t, true)
+		return true
+	}
+
+	if decoy, ok := s.Objects[proj.TrackID]; ok && decoy.Type == "decoy" {
+		decoyStart := decoy.Position.Sub(decoy.Velocity.Scale(s.dt))
+		_, dist := closestApproach(start.Sub(decoyStart), proj.Position.Sub(decoy.Position))
+		if dist > decoyRadius+proj.ProximityRadius {
+			return false
+		}
+		delete(s.Objects, decoy.ID)
+		log.Printf("Torpedo %s detonated on decoy %s", id, decoy.ID)
+		s.emit(events.TorpedoDetonated, map[string]interface{}{
+			"projectile_id": id,
+			"source_id":     proj.SourceID,
+			"target_id":     proj.TargetID,
+			"decoy_id":      decoy.ID,
+		})
+		return true
+	}
+
+	return false
+}
+
+// closestApproach returns the fraction along the segment a-b nearest the
+// origin and the distance at that point.
+func closestApproach(a, b ship.Vector3) (float64, float64) {
+	d := b.Sub(a)
+	t := 0.0
+	if dd := d.Dot(d); dd > 1e-12 {
+		t = math.Max(0, math.Min(1, -a.Dot(d)/dd))
+	}
+	return t, a.Add(d.Scale(t)).Length()
+}
+
+func (s *Simulator) deployDecoy(sh *ship.Ship, weapon *ship.Weapon) {
+	dir := sh.FacingDirection(weapon.Facing)
+	if weapon.Facing == "" {
+		dir = sh.FacingDirection("aft")
+	}
+
+	id := fmt.Sprintf("decoy_%s_%d", sh.ID, s.TickCount)
+	s.Objects[id] = &Object{
+		ID:       id,
+		Type:     "decoy",
+		Position: sh.Position,
+		Velocity: sh.Velocity.Add(dir.Scale(decoyEjectSpeed)),
+		Rotation: ship.Quaternion{W: 1},
+		Data: map[string]interface{}{
+			"owner_id":   sh.ID,
+			"expires_at": s.CurrentTime + orDefault(weapon.Duration, defaultDecoyDuration),
+		},
+	}
+
+	log.Printf("Ship %s deployed decoy %s", sh.ID, id)
+	s.emit(events.DecoyDeployed, map[string]interface{}{
+		"decoy_id":  id,
+		"source_id": sh.ID,
+		"weapon_id": weapon.ID,
+	})
+
+	effectiveness := orDefault(weapon.Effectiveness, defaultDecoyEffectiveness)
+	for projID, proj := range s.Projectiles {
+		if !proj.Guided || proj.TrackID != sh.ID || proj.Fuel <= 0 {
+			continue
+		}
+		if distance(proj.Position, sh.Position) > decoySeekerRange {
+			continue
+		}
+		if chance(projID, id) >= effectiveness {
+			continue
+		}
+		proj.TrackID = id
+		log.Printf("Torpedo %s seduced by decoy %s", projID, id)
+		s.emit(events.TorpedoDecoyed, map[string]interface{}{
+			"projectile_id": projID,
+			"target_id":     sh.ID,
+			"decoy_id":      id,
+		})
+	}
+}
+
+// updateObjects moves free-floating objects and removes expired ones such as
+// spent decoys.
+func (s *Simulator) updateObjects() {
+	for id, obj := range s.Objects {
+		obj.Position = obj.Position.Add(obj.Velocity.Scale(s.dt))
+		if expires, ok := obj.Data["expires_at"].(float64); ok && s.CurrentTime >= expires {
+			delete(s.Objects, id)
+		}
+	}
+}
+
+// updatePointDefense lets each ship's point-defense mounts shoot at the
+// nearest torpedo homing on it.
+func (s *Simulator) updatePointDefense() {
+	for _, sh := range s.Ships {
+		for weaponID, weapon := range sh.Weapons {
+			if weapon.Type != "point_defense" || !weapon.Enabled || weapon.Health <= 0 || weapon.Cooldown > 0 {
+				continue
+			}
+
+			entry, found := s.index.Nearest(sh.Position, weapon.Range, func(e *spatial.Entry) bool {
+				if e.Kind != spatial.KindProjectile {
+					return false
+				}
+				proj, ok := s.Projectiles[e.ID]
+				return ok && proj.Guided && proj.TrackID == sh.ID && proj.SourceID != sh.ID
+			})
+			var nearest *Projectile
+			if found {
+				nearest = s.Projectiles[entry.ID]
+			}
+			if nearest == nil || !sh.FireWeapon(weaponID, "") {
+				continue
+			}
+
+			nearest.Health -= weapon.Damage
+			if nearest.Health > 0 {
+				continue
+			}
+
+			delete(s.Projectiles, nearest.ID)
+			log.Printf("Ship %s intercepted torpedo %s with %s", sh.ID, nearest.ID, weaponID)
+			s.emit(events.TorpedoIntercepted, map[string]interface{}{
+				"projectile_id": nearest.ID,
+				"source_id":     nearest.SourceID,
+				"target_id":     sh.ID,
+				"weapon_id":     weaponID,
+			})
+		}
+	}
+}
+
+// updateCountermeasures has AI ships release decoys when a torpedo homing on
+// them closes inside reaction range.
+func (s *Simulator) updateCountermeasures() {
+	for _, proj := range s.Projectiles {
+		if !proj.Guided {
+			continue
+		}
+		if _, aiControlled := s.AIControllers[proj.TrackID]; !aiControlled {
+			continue
+		}
+		sh, ok := s.Ships[proj.TrackID]
+		if !ok || distance(proj.Position, sh.Position) > countermeasureRange {
+			continue
+		}
+
+		for weaponID, weapon := range sh.Weapons {
+			if weapon.Type == "decoy" && sh.FireWeapon(weaponID, "") {
+				break
+			}
+		}
+	}
+}
@@ -3,8 +3,12 @@ package simulation
 import (
 	"celestial/internal/ai"
 	"celestial/internal/config"
+	"celestial/internal/events"
 	"celestial/internal/ship"
+	"celestial/internal/spatial"
+	"fmt"
 	"log"
+	"math"
 	"sync"
 	"time"
 )
@@ -18,30 +22,74 @@ type Simulator struct {
 	paused    bool
 	stopChan  chan struct{}
 	pauseChan chan bool
+	stepChan  chan struct{}
+
+	timeScale       float64
+	accumulator     float64
+	maxCatchUpTicks int
 
 	Ships       map[string]*ship.Ship
 	Projectiles map[string]*Projectile
 	Objects     map[string]*Object
+	Triggers    map[string]*Trigger
 
 	ShipClasses map[string]*config.ShipClass
 
 	AIControllers map[string]*ai.Controller
 
 	CurrentTime   float64
+	TickCount     uint64
 	Snapshots     []*Snapshot
 	SnapshotIndex int
+
+	collisionCooldowns map[string]float64
+
+	index         *spatial.Grid
+	indexMaxSpeed float64
+
+	tickHooks []func(time float64)
+	bus       *events.Bus
+
+	missionState      MissionStateProvider
+	snapshotStore     *SnapshotStore
+	snapshotRetention SnapshotRetention
+	nextSnapshotID    int
 }
 
+const (
+	MinTimeScale = 0.25
+	MaxTimeScale = 4.0
+
+	defaultMaxCatchUpTicks = 5
+)
+
+// Event is something notable that happened in the world. Events are queued
+// on the simulator's bus while locks are held and delivered once the tick
+// completes.
+type Event = events.Event
+
 type Projectile struct {
 	ID          string
 	Type        string
 	Position    ship.Vector3
 	Velocity    ship.Vector3
 	Damage      float64
+	Frequency   float64
 	SourceID    string
 	TargetID    string
 	Lifetime    float64
 	MaxLifetime float64
+
+	Guided          bool
+	TrackID         string
+	Speed           float64
+	TurnRate        float64
+	Fuel            float64
+	NavConstant     float64
+	ArmingDistance  float64
+	ProximityRadius float64
+	Travelled       float64
+	Health          float64
 }
 
 type Object struct {
@@ -50,28 +98,94 @@ type Object struct {
 	Position ship.Vector3
 	Velocity ship.Vector3
 	Rotation ship.Quaternion
+	Radius   float64
+	Mass     float64
 	Data     map[string]interface{}
+
+	DockingPorts map[string]*ship.DockingPort
 }
 
 type Snapshot struct {
-	Time        float64
-	Ships       map[string]*ship.Ship
-	Projectiles map[string]*Projectile
-	Objects     map[string]*Object
+	ID            int
+	Label         string
+	Pinned        bool
+	CreatedAt     time.Time
+	Time          float64
+	TickCount     uint64
+	Ships         map[string]*ship.Ship
+	Projectiles   map[string]*Projectile
+	Objects       map[string]*Object
+	Triggers      map[string]*Trigger
+	AIControllers map[string]*ai.Controller
+	Mission       *MissionState
+}
+
+// SnapshotInfo is the lightweight view of a snapshot used for GM listings.
+type SnapshotInfo struct {
+	ID                  int              `json:"id"`
+	Index               int              `json:"index"`
+	Label               string           `json:"label"`
+	Pinned              bool             `json:"pinned"`
+	CreatedAt           time.Time        `json:"created_at"`
+	Time                float64          `json:"time"`
+	ShipCount           int              `json:"ship_count"`
+	MissionID           string           `json:"mission_id"`
+	Objectives          []ObjectiveState `json:"objectives"`
+	ObjectivesCompleted int              `json:"objectives_completed"`
+	File                string           `json:"file,omitempty"`
+	MissionDropped      []string         `json:"mission_dropped,omitempty"`
+}
+
+// SnapshotRetention bounds the snapshot history, in memory and on disk.
+// Pinned snapshots are exempt from both limits.
+type SnapshotRetention struct {
+	MaxCount int
+	MaxAge   float64
+}
+
+// MissionState is the mission script's part of a snapshot. Dropped
+// describes script state the snapshot could not keep, for the GM to see
+// before restoring it.
+type MissionState struct {
+	MissionID  string
+	Objectives []ObjectiveState
+	Variables  map[string]interface{}
+	Dropped    []string
+}
+
+type ObjectiveState struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Completed   bool   `json:"completed"`
+}
+
+// MissionStateProvider lets the mission engine contribute its script state
+// to snapshots without the simulator depending on the mission package.
+type MissionStateProvider interface {
+	SaveMissionState() *MissionState
+	RestoreMissionState(state *MissionState) error
 }
 
 func NewSimulator(tickRate int, shipClasses map[string]*config.ShipClass) *Simulator {
 	return &Simulator{
-		tickRate:      tickRate,
-		dt:            1.0 / float64(tickRate),
-		Ships:         make(map[string]*ship.Ship),
-		Projectiles:   make(map[string]*Projectile),
-		Objects:       make(map[string]*Object),
-		ShipClasses:   shipClasses,
-		AIControllers: make(map[string]*ai.Controller),
-		stopChan:      make(chan struct{}),
-		pauseChan:     make(chan bool),
-		Snapshots:     make([]*Snapshot, 0),
+		tickRate:           tickRate,
+		dt:                 1.0 / float64(tickRate),
+		Ships:              make(map[string]*ship.Ship),
+		Projectiles:        make(map[string]*Projectile),
+		collisionCooldowns: make(map[string]float64),
+		index:              spatial.NewGrid(spatialCellSize),
+		Objects:            make(map[string]*Object),
+		Triggers:           make(map[string]*Trigger),
+		ShipClasses:        shipClasses,
+		AIControllers:      make(map[string]*ai.Controller),
+		stopChan:           make(chan struct{}),
+		pauseChan:          make(chan bool),
+		stepChan:           make(chan struct{}),
+		timeScale:          1.0,
+		maxCatchUpTicks:    defaultMaxCatchUpTicks,
+		Snapshots:          make([]*Snapshot, 0),
+		nextSnapshotID:     1,
+		bus:                events.NewBus(),
 	}
 }
 
@@ -80,11 +194,12 @@ func (s *Simulator) Start() {
 	s.running = true
 	s.mu.Unlock()
 
-	ticker := time.NewTicker(time.Duration(1000/s.tickRate) * time.Millisecond)
+	ticker := time.NewTicker(time.Second / time.Duration(s.tickRate))
 	defer ticker.Stop()
 
 	log.Println("Simulator started")
 
+	last := time.Now()
 	for {
 		select {
 		case <-s.stopChan:
@@ -93,22 +208,65 @@ func (s *Simulator) Start() {
 		case pauseState := <-s.pauseChan:
 			s.mu.Lock()
 			s.paused = pauseState
+			s.accumulator = 0
 			s.mu.Unlock()
+			last = time.Now()
 			if pauseState {
 				log.Println("Simulator paused")
 			} else {
 				log.Println("Simulator resumed")
 			}
-		case <-ticker.C:
+		case <-s.stepChan:
 			s.mu.RLock()
 			paused := s.paused
 			s.mu.RUnlock()
 
-			if !paused {
+			if paused {
 				s.Tick()
+				log.Printf("Simulator stepped to time %.3f", s.GetTime())
 			}
+		case now := <-ticker.C:
+			elapsed := now.Sub(last)
+			last = now
+
+			s.mu.RLock()
+			paused := s.paused
+			s.mu.RUnlock()
+
+			if !paused {
+				s.advance(elapsed)
+			} else {
+				// Deliver events published while paused, such as GM triggers.
+				s.bus.Flush()
+			}
+		}
+	}
+}
+
+// advance feeds elapsed wall time into the fixed-step accumulator and runs
+// as many whole ticks as it covers, up to the catch-up budget. Time beyond
+// the budget is discarded so a stalled host does not spiral.
+func (s *Simulator) advance(elapsed time.Duration) int {
+	s.mu.Lock()
+	s.accumulator += elapsed.Seconds() * s.timeScale
+	steps := int(s.accumulator/s.dt + 1e-9)
+	if steps > s.maxCatchUpTicks {
+		dropped := steps - s.maxCatchUpTicks
+		log.Printf("Simulator falling behind, dropping %d ticks", dropped)
+		steps = s.maxCatchUpTicks
+		s.accumulator = 0
+	} else {
+		s.accumulator -= float64(steps) * s.dt
+		if s.accumulator < 0 {
+			s.accumulator = 0
 		}
 	}
+	s.mu.Unlock()
+
+	for i := 0; i < steps; i++ {
+		s.Tick()
+	}
+	return steps
 }
 
 func (s *Simulator) Stop() {
@@ -129,45 +287,144 @@ func (s *Simulator) Resume() {
 	s.pauseChan <- false
 }
 
-func (s *Simulator) Tick() {
+// Step advances a paused simulation by exactly one tick.
+func (s *Simulator) Step() {
+	s.stepChan <- struct{}{}
+}
+
+func (s *Simulator) IsPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.paused
+}
+
+func (s *Simulator) SetTimeScale(scale float64) {
+	if scale < MinTimeScale {
+		scale = MinTimeScale
+	}
+	if scale > MaxTimeScale {
+		scale = MaxTimeScale
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.timeScale = scale
+	s.mu.Unlock()
+
+	log.Printf("Simulator time scale set to %.2fx", scale)
+}
+
+func (s *Simulator) TimeScale() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	s.CurrentTime += s.dt
+	return s.timeScale
+}
+
+func (s *Simulator) SetMaxCatchUpTicks(ticks int) {
+	if ticks < 1 {
+		ticks = 1
+	}
 
+	s.mu.Lock()
+	s.maxCatchUpTicks = ticks
+	s.mu.Unlock()
+}
+
+func (s *Simulator) GetTime() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.CurrentTime
+}
+
+func (s *Simulator) Tick() {
+	s.mu.Lock()
+	s.TickCount++
+	s.CurrentTime = float64(s.TickCount) * s.dt
+	s.bus.SetTime(s.CurrentTime)
+
+	s.updateFlight()
 	for _, sh := range s.Ships {
 		sh.Update(s.dt)
 	}
+	s.updateDamageControl()
+	s.updateDocking()
 
+	s.processLaunches()
+	s.rebuildIndex()
+	s.updatePointDefense()
 	s.updateProjectiles()
+	s.updateObjects()
+	s.updateTriggers()
 	s.updateAI()
+	s.updateCountermeasures()
 	s.checkCollisions()
+	s.updateDestruction()
+
+	now := s.CurrentTime
+	hooks := s.tickHooks
+	s.mu.Unlock()
+
+	s.bus.Flush()
+	for _, hook := range hooks {
+		hook(now)
+	}
+}
+
+// Events returns the bus the simulator, ships, AI and missions publish to.
+func (s *Simulator) Events() *events.Bus {
+	return s.bus
+}
+
+// AddEventHandler registers fn to receive every event on the bus. Handlers
+// run outside the simulator lock.
+func (s *Simulator) AddEventHandler(fn func(event Event)) {
+	s.bus.Subscribe(fn)
+}
+
+// emit queues a simulation event for delivery at the end of the tick.
+func (s *Simulator) emit(eventType string, data map[string]interface{}) {
+	s.bus.Publish(eventType, events.SourceSimulation, data)
+}
+
+// AddTickHook registers fn to run after every tick. Hooks run outside the
+// simulator lock, so they may call back into the simulator.
+func (s *Simulator) AddTickHook(fn func(time float64)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tickHooks = append(s.tickHooks, fn)
 }
 
 func (s *Simulator) updateProjectiles() {
 	toDelete := make([]string, 0)
 
 	for id, proj := range s.Projectiles {
-		proj.Position.X += proj.Velocity.X * s.dt
-		proj.Position.Y += proj.Velocity.Y * s.dt
-		proj.Position.Z += proj.Velocity.Z * s.dt
+		start := proj.Position
+		if proj.Guided {
+			s.guideTorpedo(proj)
+		}
+		proj.Position = start.Add(proj.Velocity.Scale(s.dt))
+		proj.Travelled += proj.Velocity.Length() * s.dt
+
+		armed := !proj.Guided || proj.Travelled >= proj.ArmingDistance
+		if armed {
+			if target, t := s.sweepProjectile(proj, start, proj.Position); target != nil {
+				point := start.Add(proj.Position.Sub(start).Scale(t))
+				s.applyProjectileHit(id, proj, target, point, t, false)
+				toDelete = append(toDelete, id)
+				continue
+			}
+			if proj.Guided && s.checkProximity(id, proj, start) {
+				toDelete = append(toDelete, id)
+				continue
+			}
+		}
 
 		proj.Lifetime += s.dt
 		if proj.Lifetime > proj.MaxLifetime {
 			toDelete = append(toDelete, id)
-			continue
-		}
-
-		if proj.TargetID != "" {
-			target, ok := s.Ships[proj.TargetID]
-			if ok {
-				dist := distance(proj.Position, target.Position)
-				if dist < 50.0 {
-					target.TakeDamage(proj.Damage, "forward")
-					log.Printf("Projectile %s hit ship %s for %.1f damage", id, proj.TargetID, proj.Damage)
-					toDelete = append(toDelete, id)
-				}
-			}
 		}
 	}
 
@@ -176,32 +433,114 @@ func (s *Simulator) updateProjectiles() {
 	}
 }
 
-func (s *Simulator) updateAI() {
-	for shipID, controller := range s.AIControllers {
-		sh, ok := s.Ships[shipID]
+// applyProjectileHit damages target at the impact point, reached at fraction
+// t of the current tick.
+func (s *Simulator) applyProjectileHit(id string, proj *Projectile, target *ship.Ship, point ship.Vector3, t float64, proximity bool) {
+	// Facing is judged against where the target was at the moment of
+	// impact, not where it ended the tick.
+	offset := target.Velocity.Scale(s.dt * (1 - t))
+	result := target.TakeHit(ship.Hit{
+		Amount:    proj.Damage,
+		Facing:    target.FacingOf(point.Add(offset)),
+		Point:     point,
+		Frequency: proj.Frequency,
+	})
+	log.Printf("Projectile %s hit ship %s on %s facing for %.1f damage", id, target.ID, result.Facing, proj.Damage)
+	s.emit(events.ProjectileHit, map[string]interface{}{
+		"projectile_id": id,
+		"type":          proj.Type,
+		"source_id":     proj.SourceID,
+		"target_id":     target.ID,
+		"damage":        proj.Damage,
+		"facing":        result.Facing,
+		"emitter":       result.Emitter,
+		"section":       result.Section,
+		"shield_damage": result.ShieldDamage,
+		"hull_damage":   result.HullDamage,
+		"proximity":     proximity,
+		"point":         map[string]float64{"x": point.X, "y": point.Y, "z": point.Z},
+	})
+}
+
+// sweepProjectile finds the first ship the projectile passed through this
+// tick. Ships have already moved, so the sweep is done in each ship's frame
+// of reference to avoid tunnelling past fast targets. It returns the ship and
+// the fraction of the tick at which the hit happened.
+func (s *Simulator) sweepProjectile(proj *Projectile, start, end ship.Vector3) (*ship.Ship, float64) {
+	var hit *ship.Ship
+	first := math.Inf(1)
+
+	// Ships have moved since the index was built only by what their own
+	// velocity allows, so widen the query by the fastest ship's travel.
+	mid := start.Add(end).Scale(0.5)
+	reach := end.Sub(start).Length()/2 + s.indexMaxSpeed*s.dt
+	candidates := s.index.QueryRange(mid, reach, func(e *spatial.Entry) bool {
+		return e.Kind == spatial.KindShip && e.ID != proj.SourceID
+	})
+
+	for _, candidate := range candidates {
+		sh, ok := s.Ships[candidate.ID]
 		if !ok {
 			continue
 		}
-		controller.Update(s.dt, sh, s.Ships)
+
+		shipStart := sh.Position.Sub(sh.Velocity.Scale(s.dt))
+		relStart := start.Sub(shipStart)
+		relEnd := end.Sub(sh.Position)
+
+		if t, ok := sweepSphere(relStart, relEnd, s.hitRadius(sh)); ok && t < first {
+			hit = sh
+			first = t
+		}
 	}
+
+	if hit == nil {
+		return nil, 0
+	}
+	return hit, first
 }
 
-func (s *Simulator) checkCollisions() {
-	// Basic collision detection for ships
-	ships := make([]*ship.Ship, 0, len(s.Ships))
-	for _, sh := range s.Ships {
-		ships = append(ships, sh)
+func (s *Simulator) hitRadius(sh *ship.Ship) float64 {
+	if sh.Radius > 0 {
+		return sh.Radius
 	}
+	return ship.DefaultRadius
+}
 
-	for i := 0; i < len(ships); i++ {
-		for j := i + 1; j < len(ships); j++ {
-			dist := distance(ships[i].Position, ships[j].Position)
-			if dist < 100.0 {
-				ships[i].TakeDamage(10.0, "forward")
-				ships[j].TakeDamage(10.0, "forward")
-				log.Printf("Collision between %s and %s", ships[i].ID, ships[j].ID)
-			}
+// sweepSphere intersects the segment from a to b with a sphere of the given
+// radius at the origin and returns the earliest hit fraction along it.
+func sweepSphere(a, b ship.Vector3, radius float64) (float64, bool) {
+	d := b.Sub(a)
+	c := a.Dot(a) - radius*radius
+	if c <= 0 {
+		return 0, true
+	}
+
+	dd := d.Dot(d)
+	if dd < 1e-12 {
+		return 0, false
+	}
+
+	bq := a.Dot(d)
+	disc := bq*bq - dd*c
+	if disc < 0 {
+		return 0, false
+	}
+
+	t := (-bq - math.Sqrt(disc)) / dd
+	if t < 0 || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+func (s *Simulator) updateAI() {
+	for shipID, controller := range s.AIControllers {
+		sh, ok := s.Ships[shipID]
+		if !ok || sh.Disabled {
+			continue
 		}
+		controller.Update(s.dt, sh, aiWorld{s})
 	}
 }
 
@@ -217,6 +556,7 @@ func (s *Simulator) SpawnShip(id, classID, name string, isPlayer bool, position
 
 	sh := ship.NewShip(id, classID, name, class, isPlayer)
 	sh.Position = position
+	sh.SetPublisher(s.bus)
 	s.Ships[id] = sh
 
 	if !isPlayer {
@@ -231,6 +571,9 @@ func (s *Simulator) RemoveShip(id string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if sh, ok := s.Ships[id]; ok {
+		s.vacatePort(id, sh.DockingState())
+	}
 	delete(s.Ships, id)
 	delete(s.AIControllers, id)
 	log.Printf("Removed ship: %s", id)
@@ -260,17 +603,128 @@ func (s *Simulator) SpawnObject(id, objType string, position ship.Vector3) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	body := objectBodies[objType]
 	obj := &Object{
 		ID:       id,
 		Type:     objType,
 		Position: position,
-		Velocity: ship.Vector3{0, 0, 0},
-		Rotation: ship.Quaternion{1, 0, 0, 0},
+		Velocity: ship.Vector3{X: 0, Y: 0, Z: 0},
+		Rotation: ship.Quaternion{W: 1, X: 0, Y: 0, Z: 0},
+		Radius:   body.Radius,
+		Mass:     body.Mass,
 		Data:     make(map[string]interface{}),
+
+		DockingPorts: ship.NewDockingPorts(body.DockingPorts),
 	}
 
 	s.Objects[id] = obj
 	log.Printf("Spawned object: %s (%s) at position (%.1f, %.1f, %.1f)", id, objType, position.X, position.Y, position.Z)
+
+	// Waypoints tell the mission when a player ship reaches them.
+	if objType == "waypoint" {
+		s.addTrigger(&Trigger{ID: id, Kind: TriggerWaypoint, ObjectID: id, Radius: defaultWaypointRadius})
+	}
+}
+
+// ObjectBody returns the collision radius and mass of an object.
+func (s *Simulator) ObjectBody(id string) (radius, mass float64, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.Objects[id]
+	if !ok {
+		return 0, 0, false
+	}
+	return obj.Radius, obj.Mass, true
+}
+
+// SetObjectBody overrides the collision radius and mass of an object. A mass
+// of zero makes the object immovable.
+func (s *Simulator) SetObjectBody(id string, radius, mass float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if obj, ok := s.Objects[id]; ok {
+		obj.Radius = radius
+		obj.Mass = mass
+	}
+}
+
+// SetFaction changes the side a ship flies for.
+func (s *Simulator) SetFaction(id, faction string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh, ok := s.Ships[id]
+	if !ok {
+		return fmt.Errorf("ship not found: %s", id)
+	}
+	sh.Faction = faction
+	return nil
+}
+
+// SetAIOrders orders an AI ship into a state against a target.
+func (s *Simulator) SetAIOrders(id, state, targetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	controller, ok := s.AIControllers[id]
+	if !ok {
+		return fmt.Errorf("no AI controls ship %s", id)
+	}
+	if _, ok := s.Ships[targetID]; targetID != "" && !ok {
+		return fmt.Errorf("ship not found: %s", targetID)
+	}
+	return controller.SetOrders(state, targetID)
+}
+
+// SetAITacticalMode sets how aggressively an AI ship fights.
+func (s *Simulator) SetAITacticalMode(id, mode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	controller, ok := s.AIControllers[id]
+	if !ok {
+		return fmt.Errorf("no AI controls ship %s", id)
+	}
+	switch mode {
+	case "aggressive", "defensive", "balanced":
+	default:
+		return fmt.Errorf("unknown tactical mode: %s", mode)
+	}
+	controller.SetTacticalMode(mode)
+	return nil
+}
+
+// Teleport moves a ship or object to a position at once, keeping its
+// velocity.
+func (s *Simulator) Teleport(id string, position ship.Vector3) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sh, ok := s.Ships[id]; ok {
+		sh.Position = position
+		return nil
+	}
+	if obj, ok := s.Objects[id]; ok {
+		obj.Position = position
+		return nil
+	}
+	return fmt.Errorf("no ship or object %s", id)
+}
+
+// Position returns where a ship or object is.
+func (s *Simulator) Position(id string) (ship.Vector3, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if sh, ok := s.Ships[id]; ok {
+		return sh.Position, true
+	}
+	if obj, ok := s.Objects[id]; ok {
+		return obj.Position, true
+	}
+	return ship.Vector3{}, false
 }
 
 func (s *Simulator) RemoveObject(id string) {
@@ -288,6 +742,22 @@ func (s *Simulator) GetShip(id string) *ship.Ship {
 	return s.Ships[id]
 }
 
+// GetProjectiles returns copies of the projectiles in flight.
+func (s *Simulator) GetProjectiles() map[string]*Projectile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyProjectiles(s.Projectiles)
+}
+
+// GetObjects returns copies of the world objects.
+func (s *Simulator) GetObjects() map[string]*Object {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyObjects(s.Objects)
+}
+
 func (s *Simulator) GetAllShips() map[string]*ship.Ship {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -299,69 +769,389 @@ func (s *Simulator) GetAllShips() map[string]*ship.Ship {
 	return ships
 }
 
-func (s *Simulator) CreateSnapshot() {
+func (s *Simulator) SetMissionStateProvider(provider MissionStateProvider) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	snapshot := &Snapshot{
-		Time:        s.CurrentTime,
-		Ships:       s.copyShips(),
-		Projectiles: s.copyProjectiles(),
-		Objects:     s.copyObjects(),
+	s.missionState = provider
+}
+
+func (s *Simulator) SetSnapshotStore(store *SnapshotStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshotStore = store
+}
+
+func (s *Simulator) SetSnapshotRetention(retention SnapshotRetention) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshotRetention = retention
+	s.pruneSnapshots()
+}
+
+func (s *Simulator) CreateSnapshot() {
+	s.CreateNamedSnapshot("", false)
+}
+
+func (s *Simulator) CreateNamedSnapshot(label string, pinned bool) *Snapshot {
+	s.mu.RLock()
+	provider := s.missionState
+	s.mu.RUnlock()
+
+	var missionState *MissionState
+	if provider != nil {
+		missionState = provider.SaveMissionState()
 	}
 
+	s.mu.Lock()
+	snapshot := s.captureSnapshot(missionState)
+	snapshot.ID = s.nextSnapshotID
+	snapshot.Label = label
+	snapshot.Pinned = pinned
+	s.nextSnapshotID++
+
 	s.Snapshots = append(s.Snapshots, snapshot)
-	log.Printf("Created snapshot at time %.2f (total: %d)", s.CurrentTime, len(s.Snapshots))
+	s.pruneSnapshots()
+	store := s.snapshotStore
+	retention := s.snapshotRetention
+	log.Printf("Created snapshot %d at time %.2f (total: %d)", snapshot.ID, s.CurrentTime, len(s.Snapshots))
+	s.mu.Unlock()
+
+	if store != nil {
+		if _, err := store.Save(snapshot); err != nil {
+			log.Printf("Failed to persist snapshot: %v", err)
+		} else if retention.MaxCount > 0 || retention.MaxAge > 0 {
+			if err := store.Prune(retention, time.Now()); err != nil {
+				log.Printf("Failed to prune snapshot store: %v", err)
+			}
+		}
+	}
+
+	return snapshot
 }
 
-func (s *Simulator) RestoreSnapshot(index int) error {
+// CaptureSnapshot returns a deep copy of the current world without adding it
+// to the snapshot history.
+func (s *Simulator) CaptureSnapshot() *Snapshot {
+	s.mu.RLock()
+	provider := s.missionState
+	s.mu.RUnlock()
+
+	var missionState *MissionState
+	if provider != nil {
+		missionState = provider.SaveMissionState()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.captureSnapshot(missionState)
+}
+
+func (s *Simulator) captureSnapshot(missionState *MissionState) *Snapshot {
+	return &Snapshot{
+		CreatedAt:     time.Now(),
+		Time:          s.CurrentTime,
+		TickCount:     s.TickCount,
+		Ships:         copyShips(s.Ships),
+		Projectiles:   copyProjectiles(s.Projectiles),
+		Objects:       copyObjects(s.Objects),
+		Triggers:      copyTriggers(s.Triggers),
+		AIControllers: copyAIControllers(s.AIControllers),
+		Mission:       missionState,
+	}
+}
+
+// pruneSnapshots evicts the oldest unpinned snapshots that fall outside the
+// retention window. Callers must hold s.mu.
+func (s *Simulator) pruneSnapshots() {
+	retention := s.snapshotRetention
+	kept := make([]*Snapshot, 0, len(s.Snapshots))
+	for _, snap := range s.Snapshots {
+		if !snap.Pinned && retention.MaxAge > 0 && s.CurrentTime-snap.Time > retention.MaxAge {
+			continue
+		}
+		kept = append(kept, snap)
+	}
+
+	if retention.MaxCount > 0 {
+		unpinned := 0
+		for _, snap := range kept {
+			if !snap.Pinned {
+				unpinned++
+			}
+		}
+		excess := unpinned - retention.MaxCount
+		if excess > 0 {
+			trimmed := make([]*Snapshot, 0, len(kept)-excess)
+			for _, snap := range kept {
+				if !snap.Pinned && excess > 0 {
+					excess--
+					continue
+				}
+				trimmed = append(trimmed, snap)
+			}
+			kept = trimmed
+		}
+	}
+
+	if evicted := len(s.Snapshots) - len(kept); evicted > 0 {
+		log.Printf("Evicted %d snapshots by retention policy", evicted)
+	}
+	s.Snapshots = kept
+}
+
+func (s *Simulator) PinSnapshot(id int, pinned bool) error {
+	return s.updateSnapshot(id, func(snap *Snapshot) {
+		snap.Pinned = pinned
+		log.Printf("Snapshot %d pinned: %v", id, pinned)
+	})
+}
+
+func (s *Simulator) LabelSnapshot(id int, label string) error {
+	return s.updateSnapshot(id, func(snap *Snapshot) {
+		snap.Label = label
+	})
+}
+
+func (s *Simulator) updateSnapshot(id int, update func(snap *Snapshot)) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	var target *Snapshot
+	for _, snap := range s.Snapshots {
+		if snap.ID == id {
+			target = snap
+			break
+		}
+	}
+	if target == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("snapshot not found: %d", id)
+	}
+	update(target)
+	s.pruneSnapshots()
+	store := s.snapshotStore
+	s.mu.Unlock()
+
+	if store != nil {
+		if err := store.UpdateInfo(target); err != nil {
+			log.Printf("Failed to update persisted snapshot metadata: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *Simulator) ListSnapshots() []SnapshotInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]SnapshotInfo, 0, len(s.Snapshots))
+	for i, snap := range s.Snapshots {
+		info := snap.Info()
+		info.Index = i
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// StoredSnapshots lists the snapshots persisted to the snapshot store,
+// including those from earlier sessions, by their metadata alone.
+func (s *Simulator) StoredSnapshots() ([]SnapshotInfo, error) {
+	s.mu.RLock()
+	store := s.snapshotStore
+	s.mu.RUnlock()
+
+	if store == nil {
+		return nil, nil
+	}
+	return store.ListInfo()
+}
+
+// RestoreStoredSnapshot loads a persisted snapshot by file name and restores
+// it.
+func (s *Simulator) RestoreStoredSnapshot(file string) error {
+	s.mu.RLock()
+	store := s.snapshotStore
+	s.mu.RUnlock()
+
+	if store == nil {
+		return fmt.Errorf("no snapshot store configured")
+	}
+	snapshot, err := store.LoadFile(file)
+	if err != nil {
+		return err
+	}
+	return s.RestoreFromSnapshot(snapshot)
+}
+
+func (s *Simulator) SnapshotCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.Snapshots)
+}
+
+func (snap *Snapshot) Info() SnapshotInfo {
+	info := SnapshotInfo{
+		ID:        snap.ID,
+		Label:     snap.Label,
+		Pinned:    snap.Pinned,
+		CreatedAt: snap.CreatedAt,
+		Time:      snap.Time,
+		ShipCount: len(snap.Ships),
+	}
+
+	if snap.Mission != nil {
+		info.MissionID = snap.Mission.MissionID
+		info.Objectives = append([]ObjectiveState(nil), snap.Mission.Objectives...)
+		info.MissionDropped = append([]string(nil), snap.Mission.Dropped...)
+		for _, obj := range snap.Mission.Objectives {
+			if obj.Completed {
+				info.ObjectivesCompleted++
+			}
+		}
+	}
+
+	return info
+}
 
+func (s *Simulator) RestoreSnapshot(index int) error {
+	s.mu.RLock()
 	if index < 0 || index >= len(s.Snapshots) {
+		s.mu.RUnlock()
 		log.Printf("Invalid snapshot index: %d", index)
 		return nil
 	}
-
 	snapshot := s.Snapshots[index]
+	s.mu.RUnlock()
+
+	return s.RestoreFromSnapshot(snapshot)
+}
+
+func (s *Simulator) RestoreSnapshotByID(id int) error {
+	s.mu.RLock()
+	var snapshot *Snapshot
+	for _, snap := range s.Snapshots {
+		if snap.ID == id {
+			snapshot = snap
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if snapshot == nil {
+		return fmt.Errorf("snapshot not found: %d", id)
+	}
+	return s.RestoreFromSnapshot(snapshot)
+}
+
+// RestoreFromSnapshot replaces the world with deep copies of the snapshot's
+// contents, leaving the snapshot itself untouched so it can be restored again.
+func (s *Simulator) RestoreFromSnapshot(snapshot *Snapshot) error {
+	s.mu.Lock()
 	s.CurrentTime = snapshot.Time
-	s.Ships = snapshot.Ships
-	s.Projectiles = snapshot.Projectiles
-	s.Objects = snapshot.Objects
+	s.TickCount = snapshot.TickCount
+	if s.TickCount == 0 && snapshot.Time > 0 {
+		s.TickCount = uint64(math.Round(snapshot.Time / s.dt))
+	}
+	s.accumulator = 0
+	if snapshot.ID >= s.nextSnapshotID {
+		s.nextSnapshotID = snapshot.ID + 1
+	}
+	s.Ships = copyShips(snapshot.Ships)
+	for _, sh := range s.Ships {
+		sh.SetPublisher(s.bus)
+	}
+	s.bus.SetTime(s.CurrentTime)
+	s.Projectiles = copyProjectiles(snapshot.Projectiles)
+	s.collisionCooldowns = make(map[string]float64)
+	s.Objects = copyObjects(snapshot.Objects)
+	s.Triggers = copyTriggers(snapshot.Triggers)
+	s.AIControllers = copyAIControllers(snapshot.AIControllers)
+	for id, sh := range s.Ships {
+		if _, ok := s.AIControllers[id]; !ok && !sh.IsPlayer {
+			s.AIControllers[id] = ai.NewController()
+		}
+	}
+	provider := s.missionState
+	s.mu.Unlock()
 
 	log.Printf("Restored snapshot from time %.2f", snapshot.Time)
+
+	if provider != nil && snapshot.Mission != nil {
+		if err := provider.RestoreMissionState(snapshot.Mission); err != nil {
+			return fmt.Errorf("restoring mission state: %w", err)
+		}
+	}
 	return nil
 }
 
-func (s *Simulator) copyShips() map[string]*ship.Ship {
-	ships := make(map[string]*ship.Ship)
-	for k, v := range s.Ships {
-		ships[k] = v
+func copyShips(src map[string]*ship.Ship) map[string]*ship.Ship {
+	ships := make(map[string]*ship.Ship, len(src))
+	for k, v := range src {
+		ships[k] = v.Clone()
 	}
 	return ships
 }
 
-func (s *Simulator) copyProjectiles() map[string]*Projectile {
-	projectiles := make(map[string]*Projectile)
-	for k, v := range s.Projectiles {
+func copyProjectiles(src map[string]*Projectile) map[string]*Projectile {
+	projectiles := make(map[string]*Projectile, len(src))
+	for k, v := range src {
 		projCopy := *v
 		projectiles[k] = &projCopy
 	}
 	return projectiles
 }
 
-func (s *Simulator) copyObjects() map[string]*Object {
-	objects := make(map[string]*Object)
-	for k, v := range s.Objects {
+func copyObjects(src map[string]*Object) map[string]*Object {
+	objects := make(map[string]*Object, len(src))
+	for k, v := range src {
 		objCopy := *v
+		objCopy.Data = copyData(v.Data)
+		objCopy.DockingPorts = ship.CopyDockingPorts(v.DockingPorts)
 		objects[k] = &objCopy
 	}
 	return objects
 }
 
+func copyAIControllers(src map[string]*ai.Controller) map[string]*ai.Controller {
+	controllers := make(map[string]*ai.Controller, len(src))
+	for k, v := range src {
+		controllers[k] = v.Clone()
+	}
+	return controllers
+}
+
+func copyData(src map[string]interface{}) map[string]interface{} {
+	if src == nil {
+		return nil
+	}
+	data := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			data[k] = copyData(val)
+		case []interface{}:
+			items := make([]interface{}, len(val))
+			for i, item := range val {
+				if m, ok := item.(map[string]interface{}); ok {
+					items[i] = copyData(m)
+				} else {
+					items[i] = item
+				}
+			}
+			data[k] = items
+		default:
+			data[k] = v
+		}
+	}
+	return data
+}
+
 func distance(a, b ship.Vector3) float64 {
 	dx := a.X - b.X
 	dy := a.Y - b.Y
 	dz := a.Z - b.Z
-	return (dx*dx + dy*dy + dz*dz)
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
 }
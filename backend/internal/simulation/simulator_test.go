@@ -2,7 +2,10 @@ package simulation
 
 import (
 	"celestial/internal/config"
+	"celestial/internal/events"
 	"celestial/internal/ship"
+	"fmt"
+	"math"
 	"testing"
 	"time"
 )
@@ -150,3 +153,1133 @@ func TestPauseResume(t *testing.T) {
 	sim.Stop()
 	time.Sleep(50 * time.Millisecond)
 }
+
+func TestFixedStepAdvance(t *testing.T) {
+	sim := NewSimulator(60, make(map[string]*config.ShipClass))
+
+	steps := sim.advance(50 * time.Millisecond)
+	if steps != 3 {
+		t.Errorf("Expected 3 ticks for 50ms at 60Hz, got %d", steps)
+	}
+
+	expected := 3.0 / 60.0
+	if math.Abs(sim.CurrentTime-expected) > 1e-9 {
+		t.Errorf("Expected time %.6f, got %.6f", expected, sim.CurrentTime)
+	}
+
+	// A partial tick stays in the accumulator until it completes.
+	if sim.advance(10*time.Millisecond) != 0 {
+		t.Error("Partial tick should not advance the simulation")
+	}
+	if sim.advance(10*time.Millisecond) != 1 {
+		t.Error("Accumulated time should produce a tick")
+	}
+}
+
+func TestFixedStepCatchUpBudget(t *testing.T) {
+	sim := NewSimulator(60, make(map[string]*config.ShipClass))
+	sim.SetMaxCatchUpTicks(4)
+
+	steps := sim.advance(time.Second)
+	if steps != 4 {
+		t.Errorf("Expected catch-up to be capped at 4 ticks, got %d", steps)
+	}
+
+	if sim.advance(0) != 0 {
+		t.Error("Dropped time should not be replayed on the next advance")
+	}
+}
+
+func TestTimeScale(t *testing.T) {
+	sim := NewSimulator(60, make(map[string]*config.ShipClass))
+
+	sim.SetTimeScale(2.0)
+	if steps := sim.advance(25 * time.Millisecond); steps != 3 {
+		t.Errorf("Expected 3 ticks at 2x, got %d", steps)
+	}
+
+	sim.SetTimeScale(100)
+	if sim.TimeScale() != MaxTimeScale {
+		t.Errorf("Time scale should clamp to %.2f, got %.2f", MaxTimeScale, sim.TimeScale())
+	}
+
+	sim.SetTimeScale(0)
+	if sim.TimeScale() != MinTimeScale {
+		t.Errorf("Time scale should clamp to %.2f, got %.2f", MinTimeScale, sim.TimeScale())
+	}
+}
+
+func TestStepWhilePaused(t *testing.T) {
+	sim := NewSimulator(60, make(map[string]*config.ShipClass))
+
+	go sim.Start()
+	sim.Pause()
+
+	before := sim.GetTime()
+	sim.Step()
+	sim.Step()
+	sim.Resume()
+
+	if sim.GetTime() < before+2.0/60.0-1e-9 {
+		t.Error("Stepping while paused should advance the simulation")
+	}
+
+	sim.Stop()
+}
+
+// testClasses returns the plain test_ship class, with each of the given
+// changes applied to the set.
+func testClasses(changes ...func(classes map[string]*config.ShipClass)) map[string]*config.ShipClass {
+	classes := map[string]*config.ShipClass{
+		"test_ship": {
+			ID:           "test_ship",
+			Name:         "Test Ship",
+			Mass:         100000,
+			MaxSpeed:     200,
+			Acceleration: 50,
+			TurnRate:     1.0,
+			Shields: config.ShieldConfig{
+				RechargeRate: 10,
+				Emitters: []config.EmitterConfig{
+					{ID: "forward", Facing: "forward", Strength: 500, Health: 100},
+				},
+			},
+			Hull: config.HullConfig{
+				Sections: []config.HullSectionConfig{
+					{ID: "forward", Armor: 200, Health: 500},
+				},
+			},
+		},
+	}
+	for _, change := range changes {
+		change(classes)
+	}
+	return classes
+}
+
+func TestSnapshotIsDeepCopy(t *testing.T) {
+	sim := NewSimulator(60, testClasses())
+	sim.SpawnShip("ship_1", "test_ship", "Test Ship", false, ship.Vector3{X: 100})
+	sim.CreateSnapshot()
+
+	live := sim.GetShip("ship_1")
+	live.Position.X = 999
+	live.Hull.Sections["forward"].Health = 1
+	sim.AIControllers["ship_1"].State = "retreat"
+
+	if sim.Snapshots[0].Ships["ship_1"].Hull.Sections["forward"].Health != 500 {
+		t.Fatal("Snapshot should not share hull sections with the live ship")
+	}
+
+	sim.RestoreSnapshot(0)
+	restored := sim.GetShip("ship_1")
+	if restored.Position.X != 100 || restored.Hull.Sections["forward"].Health != 500 {
+		t.Error("Restored ship should match the snapshot state")
+	}
+	if sim.AIControllers["ship_1"].State != "patrol" {
+		t.Errorf("Expected AI state patrol after restore, got %s", sim.AIControllers["ship_1"].State)
+	}
+
+	restored.Position.X = 5
+	if sim.Snapshots[0].Ships["ship_1"].Position.X != 100 {
+		t.Error("Mutating a restored ship should not alter the snapshot")
+	}
+}
+
+func TestSnapshotStoreRoundTrip(t *testing.T) {
+	store, err := NewSnapshotStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	sim := NewSimulator(60, testClasses())
+	sim.SetSnapshotStore(store)
+	sim.SpawnShip("ship_1", "test_ship", "Test Ship", false, ship.Vector3{Y: 42})
+	sim.Tick()
+	sim.CreateSnapshot()
+
+	loaded, err := store.Latest()
+	if err != nil {
+		t.Fatalf("Failed to load latest snapshot: %v", err)
+	}
+
+	resumed := NewSimulator(60, testClasses())
+	if err := resumed.RestoreFromSnapshot(loaded); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+
+	sh := resumed.GetShip("ship_1")
+	if sh == nil {
+		t.Fatal("Ship should be restored from disk")
+	}
+	if sh.Shields.Emitters["forward"].MaxStrength != 500 {
+		t.Error("Shield state should survive serialization")
+	}
+	if resumed.TickCount != 1 {
+		t.Errorf("Expected tick count 1, got %d", resumed.TickCount)
+	}
+	if _, ok := resumed.AIControllers["ship_1"]; !ok {
+		t.Error("AI controller should be restored")
+	}
+}
+
+func TestSnapshotRetentionKeepsPinned(t *testing.T) {
+	sim := NewSimulator(60, make(map[string]*config.ShipClass))
+	sim.SetSnapshotRetention(SnapshotRetention{MaxCount: 3})
+
+	pinned := sim.CreateNamedSnapshot("before boarding", true)
+	for i := 0; i < 5; i++ {
+		sim.Tick()
+		sim.CreateSnapshot()
+	}
+
+	infos := sim.ListSnapshots()
+	if len(infos) != 4 {
+		t.Fatalf("Expected 3 unpinned plus 1 pinned snapshot, got %d", len(infos))
+	}
+	if infos[0].ID != pinned.ID || !infos[0].Pinned || infos[0].Label != "before boarding" {
+		t.Error("Pinned snapshot should survive eviction with its metadata")
+	}
+	if infos[1].ID != 4 {
+		t.Errorf("Oldest unpinned snapshots should be evicted first, got id %d", infos[1].ID)
+	}
+}
+
+func TestSnapshotRetentionByAge(t *testing.T) {
+	sim := NewSimulator(60, make(map[string]*config.ShipClass))
+	sim.SetSnapshotRetention(SnapshotRetention{MaxAge: 0.5})
+
+	sim.CreateSnapshot()
+	for i := 0; i < 60; i++ {
+		sim.Tick()
+	}
+	sim.CreateSnapshot()
+
+	infos := sim.ListSnapshots()
+	if len(infos) != 1 || infos[0].Time < 0.9 {
+		t.Errorf("Snapshots older than the max age should be evicted, got %d", len(infos))
+	}
+}
+
+func TestSnapshotStoreRetentionByAge(t *testing.T) {
+	store, err := NewSnapshotStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	// The old unpinned snapshot comes from an earlier session that ran
+	// further than this one has, so only its creation time shows its age.
+	now := time.Now()
+	store.Save(&Snapshot{ID: 1, TickCount: 1, Pinned: true, CreatedAt: now.Add(-time.Hour)})
+	store.Save(&Snapshot{ID: 2, TickCount: 2, Time: 500, CreatedAt: now.Add(-time.Hour)})
+	store.Save(&Snapshot{ID: 3, TickCount: 3, CreatedAt: now})
+
+	if err := store.Prune(SnapshotRetention{MaxAge: 60}, now); err != nil {
+		t.Fatal(err)
+	}
+	infos, err := store.ListInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].ID != 1 || infos[1].ID != 3 {
+		t.Errorf("Expected the pinned and the new snapshot on disk, got %+v", infos)
+	}
+}
+
+func TestRestoreStoredSnapshot(t *testing.T) {
+	store, err := NewSnapshotStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	sim := NewSimulator(60, testClasses())
+	sim.SetSnapshotStore(store)
+	sim.SpawnShip("ship_1", "test_ship", "Test Ship", false, ship.Vector3{X: 100})
+	sim.CreateNamedSnapshot("patrol", false)
+
+	next := NewSimulator(60, testClasses())
+	next.SetSnapshotStore(store)
+	stored, err := next.StoredSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].Label != "patrol" || stored[0].File == "" {
+		t.Fatalf("Expected the earlier session's snapshot in the store, got %+v", stored)
+	}
+	if err := next.RestoreStoredSnapshot(stored[0].File); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if sh := next.GetShip("ship_1"); sh == nil || sh.Position.X != 100 {
+		t.Error("Restoring a stored snapshot should bring back its ships")
+	}
+	if err := next.RestoreStoredSnapshot("../" + stored[0].File); err == nil {
+		t.Error("Stored snapshots should only be loaded from the snapshot directory")
+	}
+}
+
+func TestProjectileBreachesCompartments(t *testing.T) {
+	classes := testClasses()
+	class := classes["test_ship"]
+	class.Hull.Sections = append(class.Hull.Sections, config.HullSectionConfig{ID: "aft", Health: 500})
+	class.Compartments = []config.CompartmentConfig{
+		{ID: "bridge", Section: "forward"},
+		{ID: "engine_room", Section: "aft"},
+	}
+	class.Doors = []config.DoorConfig{{ID: "hatch", Connects: []string{"bridge", "engine_room"}, Closed: true}}
+	sim := NewSimulator(60, classes)
+	sim.SpawnShip("shooter", "test_ship", "Shooter", true, ship.Vector3{X: 5000})
+	sim.SpawnShip("target", "test_ship", "Target", true, ship.Vector3{})
+	target := sim.GetShip("target")
+	target.SetShieldsRaised(false)
+
+	sim.SpawnProjectile("proj_1", "torpedo", "shooter", "target", ship.Vector3{Z: -1000}, ship.Vector3{Z: 12000}, 600)
+	for i := 0; i < 60; i++ {
+		sim.Tick()
+	}
+
+	compartments := target.LifeSupport.Compartments
+	if !compartments["engine_room"].Breached || compartments["bridge"].Breached {
+		t.Fatal("A hit from astern should breach only the compartment behind the aft section")
+	}
+	if compartments["engine_room"].Pressure >= 101.3 || compartments["bridge"].Pressure < 101.3 {
+		t.Errorf("Expected the engine room to vent behind a closed hatch, got %f and %f kPa",
+			compartments["engine_room"].Pressure, compartments["bridge"].Pressure)
+	}
+}
+
+func TestProjectileSweptHit(t *testing.T) {
+	sim := NewSimulator(60, testClasses())
+	sim.SpawnShip("shooter", "test_ship", "Shooter", true, ship.Vector3{Z: -2000})
+	sim.SpawnShip("bystander", "test_ship", "Bystander", true, ship.Vector3{})
+	sim.SpawnShip("target", "test_ship", "Target", true, ship.Vector3{Z: 2000})
+
+	var events []Event
+	sim.AddEventHandler(func(event Event) {
+		events = append(events, event)
+	})
+
+	// At 12000 units/s the projectile covers 200 units per tick, more than
+	// the bystander's diameter, so only a swept test can catch it.
+	sim.SpawnProjectile("proj_1", "torpedo", "shooter", "target", ship.Vector3{Z: -1000}, ship.Vector3{Z: 12000}, 100)
+	for i := 0; i < 10 && len(events) == 0; i++ {
+		sim.Tick()
+	}
+
+	if len(events) != 1 || events[0].Type != "projectile_hit" {
+		t.Fatalf("Expected one projectile_hit event, got %+v", events)
+	}
+	if events[0].Data["target_id"] != "bystander" {
+		t.Errorf("Expected bystander to be hit, got %v", events[0].Data["target_id"])
+	}
+	if events[0].Data["facing"] != "aft" {
+		t.Errorf("Expected aft facing, got %v", events[0].Data["facing"])
+	}
+	point := events[0].Data["point"].(map[string]float64)
+	if math.Abs(point["z"]+ship.DefaultRadius) > 1e-6 {
+		t.Errorf("Expected impact on the sphere surface at z=%f, got %f", -ship.DefaultRadius, point["z"])
+	}
+	if sim.GetShip("bystander").Shields.Emitters["forward"].Strength != 400 {
+		t.Errorf("Expected forward emitter to absorb the hit as the nearest facing, got %f", sim.GetShip("bystander").Shields.Emitters["forward"].Strength)
+	}
+	if len(sim.Projectiles) != 0 {
+		t.Error("Projectile should be removed after the hit")
+	}
+}
+
+func withTorpedoShip(classes map[string]*config.ShipClass) {
+	classes["torpedo_ship"] = &config.ShipClass{
+		ID:       "torpedo_ship",
+		Name:     "Torpedo Ship",
+		Mass:     100000,
+		MaxSpeed: 200,
+		Weapons: []config.WeaponConfig{
+			{ID: "bay_1", Type: "torpedo", Damage: 100, CooldownTime: 5, Health: 100, AmmoCapacity: 4, Facing: "port", Speed: 400, TurnRate: 2, Fuel: 20, ArmingDistance: 200, ProximityRadius: 30},
+			{ID: "decoys", Type: "decoy", CooldownTime: 1, Health: 100, AmmoCapacity: 2, Effectiveness: 1, Duration: 20},
+			{ID: "pd_1", Type: "point_defense", Damage: 5, Range: 800, CooldownTime: 0.1, Health: 100},
+		},
+		Hull: config.HullConfig{
+			Sections: []config.HullSectionConfig{
+				{ID: "forward", Armor: 200, Health: 500},
+			},
+		},
+	}
+}
+
+func fireTorpedo(t *testing.T, sim *Simulator, shooterID, targetID string) {
+	t.Helper()
+	bay := sim.GetShip(shooterID).Weapons["bay_1"]
+	bay.Armed, bay.Loaded, bay.Locked = true, true, true
+	if !sim.GetShip(shooterID).FireWeapon("bay_1", targetID) {
+		t.Fatal("Torpedo failed to fire")
+	}
+}
+
+func TestTorpedoGuidance(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withTorpedoShip))
+	sim.SpawnShip("shooter", "torpedo_ship", "Shooter", true, ship.Vector3{})
+	sim.SpawnShip("target", "test_ship", "Target", true, ship.Vector3{Z: 3000})
+	sim.GetShip("target").Velocity = ship.Vector3{X: 60}
+
+	var hits []Event
+	sim.AddEventHandler(func(event Event) {
+		if event.Type == "projectile_hit" {
+			hits = append(hits, event)
+		}
+	})
+
+	fireTorpedo(t, sim, "shooter", "target")
+	sim.Tick()
+
+	var torpedo *Projectile
+	for _, proj := range sim.Projectiles {
+		torpedo = proj
+	}
+	if torpedo == nil {
+		t.Fatal("Expected torpedo to be spawned on the next tick")
+	}
+	// The bay faces port (+X) while the target lies dead ahead.
+	if torpedo.Velocity.X <= 0 || math.Abs(torpedo.Velocity.Length()-400) > 1e-6 {
+		t.Errorf("Expected torpedo launched along the port bay at 400 u/s, got %+v", torpedo.Velocity)
+	}
+
+	for i := 0; i < 20*60 && len(hits) == 0; i++ {
+		sim.Tick()
+	}
+
+	if len(hits) != 1 || hits[0].Data["target_id"] != "target" {
+		t.Fatalf("Expected the guided torpedo to hit the target, got %+v", hits)
+	}
+}
+
+func TestTorpedoDecoy(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withTorpedoShip))
+	sim.SpawnShip("shooter", "torpedo_ship", "Shooter", true, ship.Vector3{})
+	sim.SpawnShip("target", "torpedo_ship", "Target", true, ship.Vector3{Z: 2500})
+	sim.GetShip("target").Weapons["pd_1"].Enabled = false
+
+	var events []string
+	sim.AddEventHandler(func(event Event) {
+		events = append(events, event.Type)
+	})
+
+	fireTorpedo(t, sim, "shooter", "target")
+	sim.Tick()
+	if !sim.GetShip("target").FireWeapon("decoys", "") {
+		t.Fatal("Decoy failed to deploy")
+	}
+	for i := 0; i < 20*60 && len(sim.Projectiles) > 0; i++ {
+		sim.Tick()
+	}
+
+	want := []string{"torpedo_launched", "decoy_deployed", "torpedo_decoyed", "torpedo_detonated"}
+	if len(events) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("Expected event %d to be %s, got %s", i, want[i], events[i])
+		}
+	}
+	if sim.GetShip("target").Hull.Sections["forward"].Health != 500 {
+		t.Error("Decoyed torpedo should not damage the target")
+	}
+}
+
+func TestDecoyChance(t *testing.T) {
+	if chance("torpedo_1", "decoy_1") != chance("torpedo_1", "decoy_1") {
+		t.Error("The same torpedo and decoy should always roll the same")
+	}
+	seduced := 0
+	for i := 0; i < 1000; i++ {
+		c := chance(fmt.Sprintf("torpedo_%d", i), "decoy_1")
+		if c < 0 || c >= 1 {
+			t.Fatalf("Chance out of range: %f", c)
+		}
+		if c < 0.7 {
+			seduced++
+		}
+	}
+	if seduced < 600 || seduced > 800 {
+		t.Errorf("Expected about 700 of 1000 torpedoes seduced at 0.7, got %d", seduced)
+	}
+}
+
+func TestPointDefenseIntercept(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withTorpedoShip))
+	sim.SpawnShip("shooter", "torpedo_ship", "Shooter", true, ship.Vector3{})
+	sim.SpawnShip("target", "torpedo_ship", "Target", true, ship.Vector3{Z: 3000})
+
+	intercepted := false
+	sim.AddEventHandler(func(event Event) {
+		if event.Type == "torpedo_intercepted" {
+			intercepted = true
+		}
+	})
+
+	fireTorpedo(t, sim, "shooter", "target")
+	for i := 0; i < 20*60 && !intercepted; i++ {
+		sim.Tick()
+	}
+
+	if !intercepted {
+		t.Fatal("Expected point defense to intercept the torpedo")
+	}
+	if len(sim.Projectiles) != 0 {
+		t.Error("Intercepted torpedo should be removed")
+	}
+}
+
+func TestShipCollisionImpulse(t *testing.T) {
+	sim := NewSimulator(60, testClasses())
+	sim.SpawnShip("a", "test_ship", "A", true, ship.Vector3{Z: -60})
+	sim.SpawnShip("b", "test_ship", "B", true, ship.Vector3{Z: 60})
+	a, b := sim.GetShip("a"), sim.GetShip("b")
+	a.Velocity = ship.Vector3{Z: 100}
+	b.Velocity = ship.Vector3{Z: -100}
+
+	var collisions []Event
+	sim.AddEventHandler(func(event Event) {
+		if event.Type == "collision" {
+			collisions = append(collisions, event)
+		}
+	})
+
+	for i := 0; i < 30; i++ {
+		sim.Tick()
+	}
+
+	if len(collisions) != 1 {
+		t.Fatalf("Expected one damaging collision, got %d", len(collisions))
+	}
+	if a.Velocity.Z >= 0 || b.Velocity.Z <= 0 {
+		t.Errorf("Expected ships to bounce apart, got %+v and %+v", a.Velocity, b.Velocity)
+	}
+	if gap := b.Position.Z - a.Position.Z; gap < 2*ship.DefaultRadius {
+		t.Errorf("Expected ships to be separated, gap is %f", gap)
+	}
+	data := collisions[0].Data
+	facings := map[string]interface{}{data["a_id"].(string): data["a_facing"], data["b_id"].(string): data["b_facing"]}
+	if facings["a"] != "forward" || facings["b"] != "aft" {
+		t.Errorf("Expected a to be hit forward and b aft, got %v", facings)
+	}
+	if data["a_damage"].(float64) <= 0 || data["a_damage"] != data["b_damage"] {
+		t.Errorf("Expected equal damage for equal masses, got %v and %v", data["a_damage"], data["b_damage"])
+	}
+}
+
+func TestShipStationCollision(t *testing.T) {
+	sim := NewSimulator(60, testClasses())
+	sim.SpawnShip("a", "test_ship", "A", true, ship.Vector3{Z: -320})
+	sim.SpawnObject("station", "station", ship.Vector3{})
+	a := sim.GetShip("a")
+	a.Velocity = ship.Vector3{Z: 150}
+
+	for i := 0; i < 60; i++ {
+		sim.Tick()
+	}
+
+	if a.Velocity.Z >= 0 {
+		t.Errorf("Expected ship to rebound off the station, got %+v", a.Velocity)
+	}
+	if sim.Objects["station"].Position != (ship.Vector3{}) {
+		t.Error("Station should be immovable")
+	}
+	if dist := a.Position.Length(); dist < 250+ship.DefaultRadius-1e-6 {
+		t.Errorf("Expected ship outside the station, distance %f", dist)
+	}
+}
+
+func TestSpatialQueries(t *testing.T) {
+	sim := NewSimulator(60, testClasses())
+	sim.SpawnShip("player", "test_ship", "Player", true, ship.Vector3{})
+	sim.SpawnShip("near", "test_ship", "Near", false, ship.Vector3{X: 1000})
+	sim.SpawnShip("far", "test_ship", "Far", false, ship.Vector3{Z: 3000})
+	sim.SpawnObject("rock", "asteroid", ship.Vector3{Z: 1500})
+	sim.Tick()
+
+	if got := sim.NearestShip(ship.Vector3{}, 0, func(sh *ship.Ship) bool { return !sh.IsPlayer }); got == nil || got.ID != "near" {
+		t.Errorf("Expected nearest hostile to be near, got %v", got)
+	}
+	if got := sim.ShipsInRange(ship.Vector3{}, 500); len(got) != 1 || got[0].ID != "player" {
+		t.Errorf("Expected only the player within 500 units, got %d ships", len(got))
+	}
+	if !sim.LineOfSight("player", "near") {
+		t.Error("Expected clear line of sight to near")
+	}
+	if sim.LineOfSight("player", "far") {
+		t.Error("Expected the asteroid to block line of sight to far")
+	}
+}
+
+func withHelmShip(classes map[string]*config.ShipClass) {
+	classes["helm_ship"] = &config.ShipClass{
+		ID:           "helm_ship",
+		Name:         "Helm Ship",
+		Mass:         100000,
+		MaxSpeed:     200,
+		Acceleration: 50,
+		TurnRate:     1.0,
+		Engines: []config.EngineConfig{
+			{ID: "main_1", Type: "main", Thrust: 50000, Health: 100},
+			{ID: "rcs_1", Type: "maneuvering", Thrust: 25000, Health: 100},
+		},
+	}
+}
+
+func TestFlightNavigate(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withHelmShip))
+	sim.SpawnShip("player", "helm_ship", "Player", true, ship.Vector3{})
+
+	arrived := 0
+	sim.AddEventHandler(func(event Event) {
+		if event.Type == "autopilot_arrived" {
+			arrived++
+		}
+	})
+
+	sh := sim.GetShip("player")
+	if err := sh.Engage(ship.FlightNavigate, "", ship.Vector3{X: 3000, Z: 1000}, 0); err != nil {
+		t.Fatal(err)
+	}
+	sim.Tick()
+	if status := sh.FlightState().Status; status.HeadingError < 60 || status.Arrived {
+		t.Fatalf("Expected a large heading error before turning, got %+v", status)
+	}
+
+	for i := 0; i < 60*120 && arrived == 0; i++ {
+		sim.Tick()
+	}
+	if arrived != 1 {
+		t.Fatal("Expected the ship to arrive within two minutes")
+	}
+	for i := 0; i < 60*10; i++ {
+		sim.Tick()
+	}
+
+	goal := ship.Vector3{X: 3000, Z: 1000}
+	if d := sh.Position.Sub(goal).Length(); d > 100 {
+		t.Errorf("Expected the ship to stop near the waypoint, %.0f away", d)
+	}
+	if speed := sh.Velocity.Length(); speed > 5 {
+		t.Errorf("Expected the ship to come to rest, speed %.1f", speed)
+	}
+	// +X is to port, so the bow swings to a heading west of north.
+	if math.Abs(sh.Heading()-288.4) > 10 {
+		t.Errorf("Expected a heading of about 288, got %.1f", sh.Heading())
+	}
+}
+
+func TestFlightInterceptLosesTarget(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withHelmShip))
+	sim.SpawnShip("player", "helm_ship", "Player", true, ship.Vector3{})
+	sim.SpawnShip("target", "test_ship", "Target", false, ship.Vector3{X: -2000, Z: 4000})
+	sim.GetShip("target").Velocity = ship.Vector3{X: 40}
+
+	var events []Event
+	sim.AddEventHandler(func(event Event) {
+		if event.Type == "autopilot_arrived" || event.Type == "autopilot_disengaged" {
+			events = append(events, event)
+		}
+	})
+
+	sh := sim.GetShip("player")
+	if err := sh.Engage(ship.FlightIntercept, "", ship.Vector3{}, 0); err == nil {
+		t.Fatal("Expected intercept without a target to fail")
+	}
+	if err := sh.Engage(ship.FlightIntercept, "target", ship.Vector3{}, 300); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 60*120 && len(events) == 0; i++ {
+		sim.Tick()
+		target := sim.GetShip("target")
+		target.Velocity = ship.Vector3{X: 40}
+	}
+	if len(events) != 1 || events[0].Type != "autopilot_arrived" {
+		t.Fatalf("Expected to intercept the target, got %v", events)
+	}
+
+	sim.RemoveShip("target")
+	sim.Tick()
+	if len(events) != 2 || events[1].Data["reason"] != "target_lost" {
+		t.Fatalf("Expected the autopilot to disengage, got %v", events)
+	}
+	if flight := sh.FlightState(); flight.Mode != ship.FlightManual || sh.Throttle != 0 {
+		t.Errorf("Expected manual helm at all stop, got mode %q throttle %.2f", flight.Mode, sh.Throttle)
+	}
+}
+
+func dockingEvents(sim *Simulator) *[]Event {
+	var events []Event
+	sim.AddEventHandler(func(event Event) {
+		switch event.Type {
+		case "docking_clamping", "docked", "undocked", "docking_aborted":
+			events = append(events, event)
+		}
+	})
+	return &events
+}
+
+func TestDockingProcedure(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withTorpedoShip))
+	sim.SpawnObject("starbase", "station", ship.Vector3{})
+	// The forward port berth of a 250 unit station for a 50 unit ship is at Z 305.
+	sim.SpawnShip("player", "torpedo_ship", "Player", true, ship.Vector3{Z: 400})
+	sh := sim.GetShip("player")
+	sh.Rotation = ship.Quaternion{Y: 1}
+	sh.Weapons["bay_1"].AmmoCount = 0
+	events := dockingEvents(sim)
+
+	if err := sim.RequestDocking("player", "starbase", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.RequestDocking("player", "starbase", "aft_port"); err == nil {
+		t.Error("Expected a second docking request to be refused")
+	}
+	if dock := sh.DockingState(); dock.PortID != "forward_port" {
+		t.Fatalf("Expected the nearest port to be assigned, got %q", dock.PortID)
+	}
+
+	for i := 0; i < 60*60 && len(*events) < 2; i++ {
+		sh.Velocity = ship.Vector3{Z: -5}
+		sim.Tick()
+		if i == 0 && !sh.DockingState().InCorridor {
+			t.Fatal("Expected the ship to be inside the approach corridor")
+		}
+	}
+	if len(*events) != 2 || (*events)[0].Type != "docking_clamping" || (*events)[1].Type != "docked" {
+		t.Fatalf("Expected clamping then docked, got %v", *events)
+	}
+	if !sh.Docked {
+		t.Fatal("Expected the ship to be docked")
+	}
+	if d := distance(sh.Position, ship.Vector3{Z: 305}); d > 0.01 {
+		t.Errorf("Expected the ship on the berth, %.2f away", d)
+	}
+
+	sh.Hull.Sections["forward"].Health = 400
+	for i := 0; i < 60*11; i++ {
+		sim.Tick()
+	}
+	if sh.Weapons["bay_1"].AmmoCount != 1 {
+		t.Errorf("Expected one torpedo reloaded, got %d", sh.Weapons["bay_1"].AmmoCount)
+	}
+	if sh.Hull.Sections["forward"].Health <= 400 {
+		t.Error("Expected hull repairs while docked")
+	}
+
+	if err := sim.ReleaseDocking("player"); err != nil {
+		t.Fatal(err)
+	}
+	sim.Tick()
+	if len(*events) != 3 || (*events)[2].Type != "undocked" {
+		t.Fatalf("Expected an undocked event, got %v", *events)
+	}
+	if sh.Docked || sh.Velocity.Z <= 0 {
+		t.Errorf("Expected the ship released and pushed clear, velocity %+v", sh.Velocity)
+	}
+	if err := sim.RequestDocking("player", "starbase", "forward_port"); err != nil {
+		t.Errorf("Expected the port to be free again: %v", err)
+	}
+}
+
+func TestDockingAbortsWhenTooFast(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withTorpedoShip))
+	sim.SpawnObject("starbase", "station", ship.Vector3{})
+	sim.SpawnShip("player", "torpedo_ship", "Player", true, ship.Vector3{Z: 400})
+	sh := sim.GetShip("player")
+	sh.Rotation = ship.Quaternion{Y: 1}
+	events := dockingEvents(sim)
+
+	if err := sim.RequestDocking("player", "starbase", "forward_port"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 60*5 && len(*events) == 0; i++ {
+		sh.Velocity = ship.Vector3{Z: -40}
+		sim.Tick()
+	}
+	if len(*events) != 1 || (*events)[0].Data["reason"] != "too_fast" {
+		t.Fatalf("Expected the approach to abort as too fast, got %v", *events)
+	}
+	if sh.DockingState().Status != "" || sh.Docked {
+		t.Error("Expected the ship to be clear of the docking procedure")
+	}
+}
+
+func TestDockingAbortsOutOfCorridor(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withTorpedoShip))
+	sim.SpawnObject("starbase", "station", ship.Vector3{})
+	// Slow and aligned, but sliding onto the berth at Z 305 from the side.
+	sim.SpawnShip("player", "torpedo_ship", "Player", true, ship.Vector3{X: 100, Z: 305})
+	sh := sim.GetShip("player")
+	sh.Rotation = ship.Quaternion{Y: 1}
+	events := dockingEvents(sim)
+
+	if err := sim.RequestDocking("player", "starbase", "forward_port"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 60*30 && len(*events) == 0; i++ {
+		sh.Velocity = ship.Vector3{X: -5}
+		sim.Tick()
+	}
+	if len(*events) != 1 || (*events)[0].Data["reason"] != "out_of_corridor" {
+		t.Fatalf("Expected the approach to abort outside the corridor, got %v", *events)
+	}
+	if sh.DockingState().Status != "" || sh.Docked {
+		t.Error("Expected the ship to be clear of the docking procedure")
+	}
+}
+
+func withDamageControl(classes map[string]*config.ShipClass) {
+	class := classes["test_ship"]
+	class.Hull.Sections = []config.HullSectionConfig{
+		{ID: "forward", Armor: 200, Health: 500},
+		{ID: "port", Armor: 200, Health: 500},
+		{ID: "aft", Armor: 200, Health: 500},
+	}
+	class.Stations = map[string]string{"damage_control": "forward"}
+	class.DamageTeams = 2
+}
+
+func TestDamageControlTeams(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withDamageControl))
+	sim.SpawnShip("player", "test_ship", "Player", true, ship.Vector3{})
+	sh := sim.GetShip("player")
+	if len(sh.Teams) != 2 || sh.Teams["alpha"].Compartment != "forward" {
+		t.Fatalf("Expected two teams at their station, got %v", sh.Teams)
+	}
+
+	if err := sh.StartFire("aft"); err != nil {
+		t.Fatal(err)
+	}
+	sh.Hull.Sections["aft"].Health = 400
+	team, err := sim.DeployTeam("player", "", "aft", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha := sh.Teams[team]
+	if team != "alpha" || alpha.Status != ship.TeamMoving || len(alpha.Path) != 2 || alpha.Path[0] != "port" {
+		t.Fatalf("Expected alpha to head aft through port, got %s on %v", team, alpha.Path)
+	}
+
+	sim.Tick()
+	if alpha.ETA <= 9 || alpha.ETA > 10 {
+		t.Errorf("Expected an ETA of about two steps, got %f", alpha.ETA)
+	}
+
+	for i := 0; i < 60*120 && alpha.Status != ship.TeamStandingBy; i++ {
+		sim.Tick()
+	}
+	if alpha.Compartment != "aft" || alpha.Status != ship.TeamStandingBy {
+		t.Fatalf("Expected alpha to finish in aft, got %s (%s)", alpha.Compartment, alpha.Status)
+	}
+	if sh.LifeSupport.Compartments["aft"].OnFire || sh.Hull.Sections["aft"].OnFire {
+		t.Error("Expected the team to put the fire out")
+	}
+	if sh.Hull.Sections["aft"].Health != 500 {
+		t.Errorf("Expected the team to repair the hull, got %f", sh.Hull.Sections["aft"].Health)
+	}
+	if alpha.Health >= 100 {
+		t.Error("Expected fighting the fire to injure the team")
+	}
+
+	if err := sim.RecallTeam("player", "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 60*11; i++ {
+		sim.Tick()
+	}
+	if alpha.Compartment != "forward" || alpha.Status != ship.TeamStandingBy {
+		t.Errorf("Expected alpha back at its station, got %s (%s)", alpha.Compartment, alpha.Status)
+	}
+
+	sh.SetDoor("forward_port", false)
+	sh.VentCompartment("forward", true)
+	for i := 0; i < 60*30; i++ {
+		sim.Tick()
+	}
+	if alpha.Status != ship.TeamLost {
+		t.Errorf("Expected a team caught in vacuum to be lost, got %s at %f", alpha.Status, alpha.Health)
+	}
+	if _, err := sim.DeployTeam("player", "alpha", "aft", "", ""); err == nil {
+		t.Error("Expected a lost team to refuse orders")
+	}
+}
+
+func TestShipDestructionAndSalvage(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withTorpedoShip))
+	sim.SpawnShip("target", "torpedo_ship", "Target", false, ship.Vector3{})
+	sim.SpawnShip("player", "torpedo_ship", "Player", true, ship.Vector3{Z: 200})
+	player := sim.GetShip("player")
+	var events []Event
+	sim.AddEventHandler(func(event Event) {
+		switch event.Type {
+		case "ship_destroyed", "explosion", "wreck_salvaged":
+			events = append(events, event)
+		}
+	})
+
+	sim.GetShip("target").Hull.Sections["forward"].Health = 0
+	sim.Tick()
+	if len(events) != 2 || events[0].Type != "ship_destroyed" || events[0].Data["cause"] != ship.CauseHull {
+		t.Fatalf("Expected the target destroyed by hull loss, got %v", events)
+	}
+	if sim.GetShip("target") != nil {
+		t.Error("A destroyed ship should leave the simulation")
+	}
+	wreck, ok := sim.Objects["wreck_target"]
+	if !ok || wreck.Type != "wreck" || wreck.Data["salvage"] != 18.0 {
+		t.Fatalf("Expected a wreck holding the target's 18 stores, got %+v", wreck)
+	}
+	if health := player.Hull.Sections["forward"].Health; health != 400 {
+		t.Errorf("Expected the blast to deal 100 damage 150 m from its edge, got %f left", health)
+	}
+
+	player.Weapons["bay_1"].AmmoCount = 2
+	player.Weapons["decoys"].AmmoCount = 0
+	wreck.DockingPorts["airlock"].OccupiedBy = "player"
+	player.SetDockingState(ship.DockingState{Status: ship.DockingDocked, HostID: "wreck_target", PortID: "airlock"})
+	for i := 0; i < 60*11; i++ {
+		sim.Tick()
+	}
+	if player.Weapons["bay_1"].AmmoCount != 3 || player.Weapons["decoys"].AmmoCount != 1 {
+		t.Errorf("Expected a round salvaged for each weapon, got %d and %d", player.Weapons["bay_1"].AmmoCount, player.Weapons["decoys"].AmmoCount)
+	}
+	if wreck.Data["salvage"] != 16.0 {
+		t.Errorf("Expected 16 stores left on the wreck, got %v", wreck.Data["salvage"])
+	}
+
+	if err := sim.DestroyShip("player", "gm"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sim.Objects["wreck_player"]; !ok || sim.GetShip("player") != nil {
+		t.Error("Expected the player ship replaced by its wreck")
+	}
+
+	sim.SpawnShip("target", "torpedo_ship", "Target", false, ship.Vector3{X: 5000})
+	empty := sim.GetShip("target")
+	for _, weapon := range empty.Weapons {
+		weapon.AmmoCount = 0
+	}
+	for agent := range empty.Suppression.Charges {
+		empty.Suppression.Charges[agent] = 0
+	}
+	sim.DestroyShip("target", "gm")
+	if _, ok := sim.Objects["wreck_target_2"]; !ok {
+		t.Fatal("A second wreck of the same ship ID should not replace the first")
+	}
+	for i := 0; i < 61*60; i++ {
+		sim.Tick()
+	}
+	if _, ok := sim.Objects["wreck_target_2"]; ok {
+		t.Error("A wreck left with no stores should break up")
+	}
+	if _, ok := sim.Objects["wreck_target"]; !ok {
+		t.Error("A wreck with stores left should stay")
+	}
+}
+
+func TestEventBus(t *testing.T) {
+	sim := NewSimulator(60, testClasses(withDamageControl))
+	sim.SpawnShip("player", "test_ship", "Player", true, ship.Vector3{})
+	sh := sim.GetShip("player")
+
+	var damage, all []Event
+	cancel := sim.Events().Subscribe(func(event Event) {
+		damage = append(damage, event)
+	}, events.FireStarted, events.TeamDeployed)
+	sim.AddEventHandler(func(event Event) {
+		all = append(all, event)
+		if event.Type == events.FireStarted {
+			sim.Events().Publish("fire_alarm", events.SourceGM, nil)
+		}
+	})
+
+	sim.Tick()
+	started := sim.GetTime()
+	if err := sh.StartFire("aft"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sim.DeployTeam("player", "alpha", "aft", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(damage) != 0 {
+		t.Fatal("Expected events to wait for the end of the tick")
+	}
+
+	sim.Tick()
+	if len(damage) != 2 {
+		t.Fatalf("Expected a fire and a deployment, got %v", damage)
+	}
+	fire, deployed := damage[0], damage[1]
+	if fire.Type != events.FireStarted || fire.Source != events.SourceShip || fire.Data["ship_id"] != "player" || fire.Data["compartment"] != "aft" {
+		t.Errorf("Unexpected fire event %+v", fire)
+	}
+	if deployed.Type != events.TeamDeployed || deployed.Source != events.SourceDamage || deployed.Data["team"] != "alpha" {
+		t.Errorf("Unexpected deployment event %+v", deployed)
+	}
+	if fire.Time != started {
+		t.Errorf("Expected the fire stamped with the time it started %f, got %f", started, fire.Time)
+	}
+	if len(all) != 3 || all[2].Type != "fire_alarm" {
+		t.Errorf("Expected an event published by a handler in the same flush, got %v", all)
+	}
+
+	cancel()
+	sh.StartFire("port")
+	sim.Tick()
+	if len(damage) != 2 {
+		t.Errorf("Expected no events after cancelling, got %v", damage[2:])
+	}
+	if all[len(all)-1].Type != "fire_alarm" {
+		t.Error("Expected other subscribers to keep receiving events")
+	}
+}
+
+func TestTriggerVolumes(t *testing.T) {
+	sim := NewSimulator(60, testClasses())
+	sim.SpawnShip("player", "test_ship", "Player", true, ship.Vector3{})
+	sim.SpawnShip("other", "test_ship", "Other", false, ship.Vector3{Z: -5000})
+	delete(sim.AIControllers, "other")
+	player, other := sim.GetShip("player"), sim.GetShip("other")
+
+	counts := make(map[string]int)
+	var reached Event
+	sim.AddEventHandler(func(event Event) {
+		if id, ok := event.Data["trigger_id"].(string); ok {
+			counts[event.Type+":"+id]++
+		}
+		if event.Type == events.WaypointReached {
+			counts[event.Type]++
+			reached = event
+		}
+	})
+
+	sim.SpawnObject("wp", "waypoint", ship.Vector3{X: 1000})
+	if err := sim.AddTrigger(&Trigger{
+		ID:       "zone",
+		Shape:    TriggerBox,
+		Position: ship.Vector3{Z: 5000},
+		Extents:  ship.Vector3{X: 100, Y: 100, Z: 100},
+		Factions: []string{ship.FactionHostile},
+		Dwell:    1,
+		Repeat:   true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.AddTrigger(&Trigger{ID: "bad", Shape: TriggerBox}); err == nil {
+		t.Error("Expected a box without extents to be refused")
+	}
+
+	player.Position = ship.Vector3{X: 1100}
+	sim.Tick()
+	if counts["trigger_enter:wp"] != 1 || counts[events.WaypointReached] != 1 || reached.Data["waypoint"] != "wp" {
+		t.Fatalf("Expected the player to reach the waypoint, got %v", counts)
+	}
+	player.Position = ship.Vector3{}
+	sim.Tick()
+	player.Position = ship.Vector3{X: 1000}
+	sim.Tick()
+	if counts["trigger_exit:wp"] != 1 || counts["trigger_enter:wp"] != 1 {
+		t.Errorf("Expected a one-shot waypoint to fire once, got %v", counts)
+	}
+
+	player.Position = ship.Vector3{Z: 5000}
+	other.Position = ship.Vector3{Z: 5050, X: 90}
+	for i := 0; i < 90; i++ {
+		sim.Tick()
+	}
+	if counts["trigger_enter:zone"] != 1 || counts["trigger_dwell:zone"] != 1 {
+		t.Fatalf("Expected only the hostile ship to enter and dwell, got %v", counts)
+	}
+	other.Position = ship.Vector3{}
+	sim.Tick()
+	other.Position = ship.Vector3{Z: 5000}
+	sim.Tick()
+	if counts["trigger_exit:zone"] != 1 || counts["trigger_enter:zone"] != 2 {
+		t.Errorf("Expected a repeating trigger to fire again, got %v", counts)
+	}
+
+	sim.Objects["wp"].Position = ship.Vector3{X: -3000}
+	sim.Tick()
+	if got := sim.GetTriggers()["wp"].Position; got.X != -3000 {
+		t.Errorf("Expected the waypoint trigger to follow its object, got %v", got)
+	}
+	snapshot := sim.CaptureSnapshot()
+	sim.RemoveObject("wp")
+	sim.Tick()
+	if _, ok := sim.GetTriggers()["wp"]; ok {
+		t.Error("Expected the trigger to go with its object")
+	}
+	if err := sim.RestoreFromSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if trigger := sim.GetTriggers()["wp"]; trigger == nil || !trigger.Fired[events.TriggerEnter+"/player"] {
+		t.Error("Expected a snapshot to keep the triggers and what they have fired")
+	}
+}
+
+func TestOneShotTriggerPerShip(t *testing.T) {
+	sim := NewSimulator(60, testClasses())
+	sim.SpawnShip("alpha", "test_ship", "Alpha", true, ship.Vector3{})
+	sim.SpawnShip("beta", "test_ship", "Beta", true, ship.Vector3{X: 5000})
+	alpha, beta := sim.GetShip("alpha"), sim.GetShip("beta")
+
+	entered := make(map[string]int)
+	sim.AddEventHandler(func(event Event) {
+		if event.Type == events.TriggerEnter {
+			entered[event.Data["ship_id"].(string)]++
+		}
+	})
+	if err := sim.AddTrigger(&Trigger{ID: "gate", Position: ship.Vector3{Z: 2000}, Radius: 100, Ships: []string{"alpha", "beta"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	alpha.Position = ship.Vector3{Z: 2000}
+	sim.Tick()
+	alpha.Position = ship.Vector3{}
+	sim.Tick()
+	alpha.Position = ship.Vector3{Z: 2000}
+	beta.Position = ship.Vector3{Z: 2050}
+	sim.Tick()
+	if entered["alpha"] != 1 || entered["beta"] != 1 {
+		t.Errorf("Expected a one-shot trigger to fire once for each ship, got %v", entered)
+	}
+}
+
+func TestScriptControls(t *testing.T) {
+	sim := NewSimulator(60, testClasses())
+	sim.SpawnShip("player", "test_ship", "Player", true, ship.Vector3{})
+	sim.SpawnShip("enemy", "test_ship", "Enemy", false, ship.Vector3{X: 5000})
+	sim.SpawnObject("beacon", "waypoint", ship.Vector3{Z: 100})
+
+	if err := sim.SetAIOrders("enemy", "combat", "player"); err != nil {
+		t.Fatal(err)
+	}
+	if c := sim.AIControllers["enemy"]; c.State != "combat" || c.TargetID != "player" {
+		t.Errorf("Expected the enemy ordered into combat, got %s on %s", c.State, c.TargetID)
+	}
+	if err := sim.SetAIOrders("enemy", "combat", ""); err == nil {
+		t.Error("Expected combat orders without a target to be refused")
+	}
+	if err := sim.SetAIOrders("player", "patrol", ""); err == nil {
+		t.Error("Expected a player ship to refuse AI orders")
+	}
+	if err := sim.SetAITacticalMode("enemy", "aggressive"); err != nil || sim.AIControllers["enemy"].AggressionLevel != 1 {
+		t.Errorf("Expected an aggressive enemy (%v)", err)
+	}
+
+	if err := sim.Teleport("enemy", ship.Vector3{Y: 50}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Teleport("beacon", ship.Vector3{Y: -50}); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := sim.Position("enemy")
+	b, _ := sim.Position("beacon")
+	if a.Sub(b).Length() != 100 {
+		t.Errorf("Expected the ship and object 100 apart, got %v and %v", a, b)
+	}
+	if _, ok := sim.Position("nothing"); ok {
+		t.Error("Expected no position for an unknown ID")
+	}
+}
@@ -0,0 +1,108 @@
+package simulation
+
+import (
+	"celestial/internal/events"
+	"celestial/internal/ship"
+	"celestial/internal/spatial"
+)
+
+const spatialCellSize = 500.0
+
+// rebuildIndex refreshes the broad-phase grid from the current world. It runs
+// once per tick after ships have moved. Callers must hold s.mu.
+func (s *Simulator) rebuildIndex() {
+	s.index.Clear()
+	s.indexMaxSpeed = 0
+
+	for id, sh := range s.Ships {
+		s.index.Insert(id, spatial.KindShip, sh.Position, s.hitRadius(sh))
+		if speed := sh.Velocity.Length(); speed > s.indexMaxSpeed {
+			s.indexMaxSpeed = speed
+		}
+	}
+	for id, obj := range s.Objects {
+		s.index.Insert(id, spatial.KindObject, obj.Position, obj.Radius)
+	}
+	for id, proj := range s.Projectiles {
+		s.index.Insert(id, spatial.KindProjectile, proj.Position, 0)
+	}
+}
+
+// ShipsInRange returns the ships whose hulls come within radius of center.
+// Scripts call it between ticks, after spawns and teleports the index has
+// not seen yet, so it scans the ships rather than the index.
+func (s *Simulator) ShipsInRange(center ship.Vector3, radius float64) []*ship.Ship {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ships []*ship.Ship
+	for _, sh := range s.Ships {
+		if sh.Position.Sub(center).Length() <= radius+s.hitRadius(sh) {
+			ships = append(ships, sh)
+		}
+	}
+	return ships
+}
+
+// NearestShip returns the closest ship to center accepted by filter. A
+// maxRange of zero means unlimited.
+func (s *Simulator) NearestShip(center ship.Vector3, maxRange float64, filter func(sh *ship.Ship) bool) *ship.Ship {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.nearestShip(center, maxRange, filter)
+}
+
+func (s *Simulator) nearestShip(center ship.Vector3, maxRange float64, filter func(sh *ship.Ship) bool) *ship.Ship {
+	e, ok := s.index.Nearest(center, maxRange, func(e *spatial.Entry) bool {
+		if e.Kind != spatial.KindShip {
+			return false
+		}
+		sh, ok := s.Ships[e.ID]
+		return ok && (filter == nil || filter(sh))
+	})
+	if !ok {
+		return nil
+	}
+	return s.Ships[e.ID]
+}
+
+// LineOfSight reports whether no collidable object, such as an asteroid or a
+// station, sits between two ships.
+func (s *Simulator) LineOfSight(fromID, toID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, ok := s.Ships[fromID]
+	if !ok {
+		return false
+	}
+	to, ok := s.Ships[toID]
+	if !ok {
+		return false
+	}
+
+	delta := to.Position.Sub(from.Position)
+	_, _, blocked := s.index.Raycast(from.Position, delta, delta.Length(), func(e *spatial.Entry) bool {
+		return e.Kind == spatial.KindObject && e.Radius > 0
+	})
+	return !blocked
+}
+
+// aiWorld gives AI controllers read access to the world from inside the tick,
+// while the simulator lock is already held, and lets them publish events.
+type aiWorld struct {
+	s *Simulator
+}
+
+func (w aiWorld) Ship(id string) *ship.Ship {
+	return w.s.Ships[id]
+}
+
+func (w aiWorld) NearestShip(center ship.Vector3, maxRange float64, filter func(sh *ship.Ship) bool) *ship.Ship {
+	return w.s.nearestShip(center, maxRange, filter)
+}
+
+func (w aiWorld) Publish(eventType string, data map[string]interface{}) {
+	w.s.bus.Publish(eventType, events.SourceAI, data)
+}
@@ -0,0 +1,254 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const SnapshotFormatVersion = 1
+
+type SnapshotStore struct {
+	mu    sync.Mutex
+	dir   string
+	paths map[int]string
+}
+
+type snapshotFile struct {
+	Version  int       `json:"version"`
+	Snapshot *Snapshot `json:"snapshot"`
+}
+
+func NewSnapshotStore(dir string) (*SnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+
+	return &SnapshotStore{dir: dir, paths: make(map[int]string)}, nil
+}
+
+func (st *SnapshotStore) Dir() string {
+	return st.dir
+}
+
+func (st *SnapshotStore) Save(snapshot *Snapshot) (string, error) {
+	data, err := json.Marshal(snapshotFile{
+		Version:  SnapshotFormatVersion,
+		Snapshot: snapshot,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	name := fmt.Sprintf("snapshot_%s_%012d.json", time.Now().UTC().Format("20060102-150405.000"), snapshot.TickCount)
+	path := filepath.Join(st.dir, name)
+
+	meta, err := json.Marshal(snapshot.Info())
+	if err != nil {
+		return "", fmt.Errorf("encoding snapshot metadata: %w", err)
+	}
+	if err := writeFileAtomic(metaPath(path), meta); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		os.Remove(metaPath(path))
+		return "", err
+	}
+
+	st.mu.Lock()
+	st.paths[snapshot.ID] = path
+	st.mu.Unlock()
+
+	log.Printf("Saved snapshot to %s", path)
+	return path, nil
+}
+
+// writeFileAtomic writes to a temp file and renames it into place so a power
+// cut never leaves a half-written snapshot behind as the latest autosave.
+func writeFileAtomic(path string, data []byte) error {
+	dir, name := filepath.Split(path)
+	tmp, err := os.CreateTemp(dir, name+".tmp*")
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("syncing snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("closing snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("finalizing snapshot: %w", err)
+	}
+	return nil
+}
+
+// UpdateInfo rewrites the metadata of an already persisted snapshot, e.g.
+// after the GM pins or renames it.
+func (st *SnapshotStore) UpdateInfo(snapshot *Snapshot) error {
+	st.mu.Lock()
+	path, ok := st.paths[snapshot.ID]
+	st.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	meta, err := json.Marshal(snapshot.Info())
+	if err != nil {
+		return fmt.Errorf("encoding snapshot metadata: %w", err)
+	}
+	return writeFileAtomic(metaPath(path), meta)
+}
+
+func metaPath(path string) string {
+	return strings.TrimSuffix(path, ".json") + ".meta.json"
+}
+
+func (st *SnapshotStore) Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", filepath.Base(path), err)
+	}
+
+	if file.Version != SnapshotFormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d in %s", file.Version, filepath.Base(path))
+	}
+	if file.Snapshot == nil {
+		return nil, fmt.Errorf("snapshot %s is empty", filepath.Base(path))
+	}
+
+	return file.Snapshot, nil
+}
+
+// LoadFile loads a snapshot by the file name ListInfo reports for it.
+func (st *SnapshotStore) LoadFile(name string) (*Snapshot, error) {
+	if name != filepath.Base(name) {
+		return nil, fmt.Errorf("invalid snapshot file name: %s", name)
+	}
+	return st.Load(filepath.Join(st.dir, name))
+}
+
+func (st *SnapshotStore) List() ([]string, error) {
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "snapshot_") || filepath.Ext(name) != ".json" || strings.HasSuffix(name, ".meta.json") {
+			continue
+		}
+		paths = append(paths, filepath.Join(st.dir, name))
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (st *SnapshotStore) Latest() (*Snapshot, error) {
+	paths, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(paths) - 1; i >= 0; i-- {
+		snapshot, err := st.Load(paths[i])
+		if err != nil {
+			log.Printf("Skipping unreadable snapshot: %v", err)
+			continue
+		}
+		return snapshot, nil
+	}
+
+	return nil, fmt.Errorf("no snapshots found in %s", st.dir)
+}
+
+// ListInfo returns the metadata of every persisted snapshot without loading
+// the world state itself.
+func (st *SnapshotStore) ListInfo() ([]SnapshotInfo, error) {
+	paths, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]SnapshotInfo, 0, len(paths))
+	for i, path := range paths {
+		info, err := st.loadInfo(path)
+		if err != nil {
+			log.Printf("Skipping snapshot metadata: %v", err)
+			continue
+		}
+		info.Index = i
+		info.File = filepath.Base(path)
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (st *SnapshotStore) loadInfo(path string) (SnapshotInfo, error) {
+	var info SnapshotInfo
+	data, err := os.ReadFile(metaPath(path))
+	if err != nil {
+		return info, fmt.Errorf("reading snapshot metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("parsing snapshot metadata %s: %w", filepath.Base(path), err)
+	}
+	return info, nil
+}
+
+// Prune applies a retention policy to the snapshots on disk: unpinned
+// snapshots created more than MaxAge seconds before now are deleted, then the
+// oldest unpinned ones beyond MaxCount. Age goes by wall clock, since files
+// outlive the session whose simulation time they were stamped with.
+func (st *SnapshotStore) Prune(retention SnapshotRetention, now time.Time) error {
+	paths, err := st.List()
+	if err != nil {
+		return err
+	}
+
+	var expired, unpinned []string
+	for _, path := range paths {
+		info, err := st.loadInfo(path)
+		switch {
+		case err == nil && info.Pinned:
+		case err == nil && retention.MaxAge > 0 && now.Sub(info.CreatedAt).Seconds() > retention.MaxAge:
+			expired = append(expired, path)
+		default:
+			unpinned = append(unpinned, path)
+		}
+	}
+	if retention.MaxCount > 0 && len(unpinned) > retention.MaxCount {
+		expired = append(expired, unpinned[:len(unpinned)-retention.MaxCount]...)
+	}
+
+	for _, path := range expired {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("removing snapshot: %w", err)
+		}
+		os.Remove(metaPath(path))
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package simulation
+
+import (
+	"celestial/internal/events"
+	"celestial/internal/ship"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+const (
+	TriggerSphere = "sphere"
+	TriggerBox    = "box"
+
+	TriggerWaypoint = "waypoint"
+	TriggerArea     = "area"
+
+	defaultWaypointRadius = 500.0
+)
+
+// Trigger is a volume that fires events as ships enter it, leave it or stay
+// in it for Dwell seconds. A sphere has a Radius; a box has half-size
+// Extents along each axis. A trigger attached to an object follows it and
+// is removed with it. Ships and Factions name who sets it off, and player
+// ships do when neither is given. A trigger that does not Repeat fires each
+// of its events once per ship; Fired records them by event type and ship
+// ID. Inside holds how long each ship has been inside.
+type Trigger struct {
+	ID       string
+	Kind     string
+	Shape    string
+	Position ship.Vector3
+	Radius   float64
+	Extents  ship.Vector3
+	ObjectID string
+	Ships    []string
+	Factions []string
+	Dwell    float64
+	Repeat   bool
+	Fired    map[string]bool
+	Inside   map[string]float64
+}
+
+func (t *Trigger) clone() *Trigger {
+	c := *t
+	c.Ships = append([]string(nil), t.Ships...)
+	c.Factions = append([]string(nil), t.Factions...)
+	c.Fired = make(map[string]bool, len(t.Fired))
+	for k, v := range t.Fired {
+		c.Fired[k] = v
+	}
+	c.Inside = make(map[string]float64, len(t.Inside))
+	for k, v := range t.Inside {
+		c.Inside[k] = v
+	}
+	return &c
+}
+
+func (t *Trigger) contains(p ship.Vector3) bool {
+	d := p.Sub(t.Position)
+	if t.Shape == TriggerBox {
+		return math.Abs(d.X) <= t.Extents.X && math.Abs(d.Y) <= t.Extents.Y && math.Abs(d.Z) <= t.Extents.Z
+	}
+	return d.Length() <= t.Radius
+}
+
+func (t *Trigger) matches(sh *ship.Ship) bool {
+	if len(t.Ships) == 0 && len(t.Factions) == 0 {
+		return sh.IsPlayer
+	}
+	for _, id := range t.Ships {
+		if id == sh.ID {
+			return true
+		}
+	}
+	for _, faction := range t.Factions {
+		if faction == sh.Faction {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTrigger adds a trigger volume, replacing any with the same ID.
+func (s *Simulator) AddTrigger(t *Trigger) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addTrigger(t)
+}
+
+func (s *Simulator) addTrigger(t *Trigger) error {
+	if t.ID == "" {
+		return fmt.Errorf("trigger needs an ID")
+	}
+	switch t.Shape {
+	case "", TriggerSphere:
+		t.Shape = TriggerSphere
+		if t.Radius <= 0 {
+			return fmt.Errorf("trigger %s needs a radius", t.ID)
+		}
+	case TriggerBox:
+		if t.Extents.X <= 0 || t.Extents.Y <= 0 || t.Extents.Z <= 0 {
+			return fmt.Errorf("trigger %s needs positive extents", t.ID)
+		}
+	default:
+		return fmt.Errorf("unknown trigger shape: %s", t.Shape)
+	}
+	if t.ObjectID != "" {
+		obj, ok := s.Objects[t.ObjectID]
+		if !ok {
+			return fmt.Errorf("object not found: %s", t.ObjectID)
+		}
+		t.Position = obj.Position
+	}
+	if t.Kind != TriggerWaypoint {
+		t.Kind = TriggerArea
+	}
+	t.Fired = make(map[string]bool)
+	t.Inside = make(map[string]float64)
+
+	s.Triggers[t.ID] = t
+	log.Printf("Added %s trigger %s", t.Shape, t.ID)
+	return nil
+}
+
+// RemoveTrigger removes a trigger volume.
+func (s *Simulator) RemoveTrigger(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.Triggers[id]; !ok {
+		return fmt.Errorf("trigger not found: %s", id)
+	}
+	delete(s.Triggers, id)
+	return nil
+}
+
+// GetTriggers returns copies of the trigger volumes.
+func (s *Simulator) GetTriggers() map[string]*Trigger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyTriggers(s.Triggers)
+}
+
+func copyTriggers(src map[string]*Trigger) map[string]*Trigger {
+	triggers := make(map[string]*Trigger, len(src))
+	for k, v := range src {
+		triggers[k] = v.clone()
+	}
+	return triggers
+}
+
+// updateTriggers moves attached triggers with their objects and fires
+// events for the ships that enter, leave or linger in each volume.
+func (s *Simulator) updateTriggers() {
+	ids := make([]string, 0, len(s.Triggers))
+	for id := range s.Triggers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	shipIDs := make([]string, 0, len(s.Ships))
+	for id := range s.Ships {
+		shipIDs = append(shipIDs, id)
+	}
+	sort.Strings(shipIDs)
+
+	for _, id := range ids {
+		t := s.Triggers[id]
+		if t.ObjectID != "" {
+			obj, ok := s.Objects[t.ObjectID]
+			if !ok {
+				delete(s.Triggers, id)
+				continue
+			}
+			t.Position = obj.Position
+		}
+
+		for _, shipID := range shipIDs {
+			sh := s.Ships[shipID]
+			inside, wasInside := t.Inside[shipID]
+			if !t.matches(sh) || !t.contains(sh.Position) {
+				if wasInside {
+					delete(t.Inside, shipID)
+					s.fireTrigger(t, events.TriggerExit, sh)
+				}
+				continue
+			}
+
+			if !wasInside {
+				t.Inside[shipID] = 0
+				s.fireTrigger(t, events.TriggerEnter, sh)
+				continue
+			}
+			t.Inside[shipID] = inside + s.dt
+			if t.Dwell > 0 && inside < t.Dwell && inside+s.dt >= t.Dwell {
+				s.fireTrigger(t, events.TriggerDwell, sh)
+			}
+		}
+		for shipID := range t.Inside {
+			if _, ok := s.Ships[shipID]; !ok {
+				delete(t.Inside, shipID)
+			}
+		}
+	}
+}
+
+// fireTrigger publishes a trigger event for a ship, unless a one-shot
+// trigger has fired it for that ship before. Entering a trigger also
+// publishes that a waypoint or area was reached.
+func (s *Simulator) fireTrigger(t *Trigger, eventType string, sh *ship.Ship) {
+	key := eventType + "/" + sh.ID
+	if !t.Repeat && t.Fired[key] {
+		return
+	}
+	t.Fired[key] = true
+
+	s.emit(eventType, map[string]interface{}{
+		"trigger_id": t.ID,
+		"kind":       t.Kind,
+		"ship_id":    sh.ID,
+		"faction":    sh.Faction,
+	})
+	if eventType != events.TriggerEnter {
+		return
+	}
+	reached := events.AreaReached
+	if t.Kind == TriggerWaypoint {
+		reached = events.WaypointReached
+	}
+	s.emit(reached, map[string]interface{}{
+		t.Kind:    t.ID,
+		"ship_id": sh.ID,
+		"faction": sh.Faction,
+	})
+}
@@ -0,0 +1,334 @@
+package spatial
+
+import (
+	"celestial/internal/ship"
+	"math"
+)
+
+type Kind int
+
+const (
+	KindShip Kind = iota
+	KindObject
+	KindProjectile
+)
+
+type Entry struct {
+	ID       string
+	Kind     Kind
+	Position ship.Vector3
+	Radius   float64
+}
+
+type Filter func(e *Entry) bool
+
+type cell struct {
+	X, Y, Z int
+}
+
+// Grid is a uniform spatial hash. Entries larger than a cell are stored in
+// every cell their bounding box touches, so queries deduplicate by index.
+type Grid struct {
+	cellSize float64
+	cells    map[cell][]int
+	entries  []Entry
+	min, max cell
+	stamp    []uint32
+	query    uint32
+}
+
+func NewGrid(cellSize float64) *Grid {
+	if cellSize <= 0 {
+		cellSize = 500
+	}
+	return &Grid{
+		cellSize: cellSize,
+		cells:    make(map[cell][]int),
+	}
+}
+
+func (g *Grid) CellSize() float64 {
+	return g.cellSize
+}
+
+func (g *Grid) Len() int {
+	return len(g.entries)
+}
+
+func (g *Grid) Clear() {
+	for k, ids := range g.cells {
+		g.cells[k] = ids[:0]
+	}
+	g.entries = g.entries[:0]
+	g.stamp = g.stamp[:0]
+}
+
+func (g *Grid) Insert(id string, kind Kind, position ship.Vector3, radius float64) {
+	idx := len(g.entries)
+	g.entries = append(g.entries, Entry{ID: id, Kind: kind, Position: position, Radius: radius})
+	g.stamp = append(g.stamp, 0)
+
+	lo := g.cellOf(position.Sub(ship.Vector3{X: radius, Y: radius, Z: radius}))
+	hi := g.cellOf(position.Add(ship.Vector3{X: radius, Y: radius, Z: radius}))
+	if idx == 0 {
+		g.min, g.max = lo, hi
+	} else {
+		g.min = cell{min(g.min.X, lo.X), min(g.min.Y, lo.Y), min(g.min.Z, lo.Z)}
+		g.max = cell{max(g.max.X, hi.X), max(g.max.Y, hi.Y), max(g.max.Z, hi.Z)}
+	}
+
+	for x := lo.X; x <= hi.X; x++ {
+		for y := lo.Y; y <= hi.Y; y++ {
+			for z := lo.Z; z <= hi.Z; z++ {
+				k := cell{x, y, z}
+				g.cells[k] = append(g.cells[k], idx)
+			}
+		}
+	}
+}
+
+func (g *Grid) cellOf(p ship.Vector3) cell {
+	return cell{
+		X: int(math.Floor(p.X / g.cellSize)),
+		Y: int(math.Floor(p.Y / g.cellSize)),
+		Z: int(math.Floor(p.Z / g.cellSize)),
+	}
+}
+
+func (g *Grid) nextQuery() uint32 {
+	g.query++
+	if g.query == 0 {
+		for i := range g.stamp {
+			g.stamp[i] = 0
+		}
+		g.query = 1
+	}
+	return g.query
+}
+
+// visit calls fn once per entry stored in cells overlapping the box.
+func (g *Grid) visit(lo, hi cell, q uint32, fn func(idx int)) {
+	lo = cell{max(lo.X, g.min.X), max(lo.Y, g.min.Y), max(lo.Z, g.min.Z)}
+	hi = cell{min(hi.X, g.max.X), min(hi.Y, g.max.Y), min(hi.Z, g.max.Z)}
+	for x := lo.X; x <= hi.X; x++ {
+		for y := lo.Y; y <= hi.Y; y++ {
+			for z := lo.Z; z <= hi.Z; z++ {
+				for _, idx := range g.cells[cell{x, y, z}] {
+					if g.stamp[idx] == q {
+						continue
+					}
+					g.stamp[idx] = q
+					fn(idx)
+				}
+			}
+		}
+	}
+}
+
+// QueryRange returns every entry whose bounding sphere overlaps the sphere
+// at center with the given radius.
+func (g *Grid) QueryRange(center ship.Vector3, radius float64, filter Filter) []Entry {
+	var out []Entry
+	if len(g.entries) == 0 {
+		return out
+	}
+
+	q := g.nextQuery()
+	r := ship.Vector3{X: radius, Y: radius, Z: radius}
+	g.visit(g.cellOf(center.Sub(r)), g.cellOf(center.Add(r)), q, func(idx int) {
+		e := &g.entries[idx]
+		if e.Position.Sub(center).Length() > radius+e.Radius {
+			return
+		}
+		if filter != nil && !filter(e) {
+			return
+		}
+		out = append(out, *e)
+	})
+	return out
+}
+
+// Nearest returns the entry whose centre is closest to center, searching
+// outward shell by shell. A maxRange of zero searches the whole grid.
+func (g *Grid) Nearest(center ship.Vector3, maxRange float64, filter Filter) (Entry, bool) {
+	if len(g.entries) == 0 {
+		return Entry{}, false
+	}
+
+	c := g.cellOf(center)
+	maxShell := max(
+		abs(c.X-g.min.X), abs(c.X-g.max.X),
+		abs(c.Y-g.min.Y), abs(c.Y-g.max.Y),
+		abs(c.Z-g.min.Z), abs(c.Z-g.max.Z),
+	)
+	if maxRange > 0 {
+		maxShell = min(maxShell, int(math.Ceil(maxRange/g.cellSize)))
+	}
+
+	q := g.nextQuery()
+	best := -1
+	bestDist := math.Inf(1)
+	if maxRange > 0 {
+		bestDist = maxRange
+	}
+
+	for shell := 0; shell <= maxShell; shell++ {
+		lo := cell{c.X - shell, c.Y - shell, c.Z - shell}
+		hi := cell{c.X + shell, c.Y + shell, c.Z + shell}
+		g.visit(lo, hi, q, func(idx int) {
+			e := &g.entries[idx]
+			dist := e.Position.Sub(center).Length()
+			if dist > bestDist || (dist == bestDist && best >= 0) {
+				return
+			}
+			if filter != nil && !filter(e) {
+				return
+			}
+			best = idx
+			bestDist = dist
+		})
+
+		// Anything not yet visited lies at least shell cells away.
+		if best >= 0 && bestDist <= float64(shell)*g.cellSize {
+			break
+		}
+	}
+
+	if best < 0 {
+		return Entry{}, false
+	}
+	return g.entries[best], true
+}
+
+// Raycast returns the first entry whose bounding sphere the ray hits within
+// maxDist, and the distance along the ray to the hit.
+func (g *Grid) Raycast(origin, dir ship.Vector3, maxDist float64, filter Filter) (Entry, float64, bool) {
+	dir = dir.Normalize()
+	if len(g.entries) == 0 || dir.Length() == 0 {
+		return Entry{}, 0, false
+	}
+
+	q := g.nextQuery()
+	best := -1
+	bestT := maxDist
+
+	c := g.cellOf(origin)
+	step := [3]int{sign(dir.X), sign(dir.Y), sign(dir.Z)}
+	d := [3]float64{dir.X, dir.Y, dir.Z}
+	o := [3]float64{origin.X, origin.Y, origin.Z}
+	pos := [3]int{c.X, c.Y, c.Z}
+	var tMax, tDelta [3]float64
+	for i := 0; i < 3; i++ {
+		if step[i] == 0 {
+			tMax[i] = math.Inf(1)
+			tDelta[i] = math.Inf(1)
+			continue
+		}
+		boundary := float64(pos[i]) * g.cellSize
+		if step[i] > 0 {
+			boundary += g.cellSize
+		}
+		tMax[i] = (boundary - o[i]) / d[i]
+		tDelta[i] = g.cellSize / math.Abs(d[i])
+	}
+
+	t := 0.0
+	for t <= bestT {
+		k := cell{pos[0], pos[1], pos[2]}
+		if k.X < g.min.X && step[0] <= 0 || k.X > g.max.X && step[0] >= 0 ||
+			k.Y < g.min.Y && step[1] <= 0 || k.Y > g.max.Y && step[1] >= 0 ||
+			k.Z < g.min.Z && step[2] <= 0 || k.Z > g.max.Z && step[2] >= 0 {
+			break
+		}
+
+		for _, idx := range g.cells[k] {
+			if g.stamp[idx] == q {
+				continue
+			}
+			g.stamp[idx] = q
+			e := &g.entries[idx]
+			hit, ok := raySphere(origin, dir, e.Position, e.Radius)
+			if !ok || hit > bestT {
+				continue
+			}
+			if filter != nil && !filter(e) {
+				continue
+			}
+			best = idx
+			bestT = hit
+		}
+
+		axis := 0
+		if tMax[1] < tMax[axis] {
+			axis = 1
+		}
+		if tMax[2] < tMax[axis] {
+			axis = 2
+		}
+		t = tMax[axis]
+		tMax[axis] += tDelta[axis]
+		pos[axis] += step[axis]
+	}
+
+	if best < 0 {
+		return Entry{}, 0, false
+	}
+	return g.entries[best], bestT, true
+}
+
+// Pairs calls fn once for every pair of entries whose bounding spheres
+// overlap.
+func (g *Grid) Pairs(filter Filter, fn func(a, b *Entry)) {
+	for i := range g.entries {
+		a := &g.entries[i]
+		if filter != nil && !filter(a) {
+			continue
+		}
+		q := g.nextQuery()
+		g.stamp[i] = q
+		r := ship.Vector3{X: a.Radius, Y: a.Radius, Z: a.Radius}
+		g.visit(g.cellOf(a.Position.Sub(r)), g.cellOf(a.Position.Add(r)), q, func(j int) {
+			if j < i {
+				return
+			}
+			b := &g.entries[j]
+			if filter != nil && !filter(b) {
+				return
+			}
+			if a.Position.Sub(b.Position).Length() < a.Radius+b.Radius {
+				fn(a, b)
+			}
+		})
+	}
+}
+
+func raySphere(origin, dir, center ship.Vector3, radius float64) (float64, bool) {
+	oc := origin.Sub(center)
+	b := oc.Dot(dir)
+	c := oc.Dot(oc) - radius*radius
+	if c <= 0 {
+		return 0, true
+	}
+	disc := b*b - c
+	if disc < 0 || b > 0 {
+		return 0, false
+	}
+	return -b - math.Sqrt(disc), true
+}
+
+func sign(v float64) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
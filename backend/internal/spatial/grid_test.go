@@ -0,0 +1,146 @@
+package spatial
+
+import (
+	"celestial/internal/ship"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomGrid(r *rand.Rand, n int) *Grid {
+	g := NewGrid(250)
+	for i := 0; i < n; i++ {
+		pos := ship.Vector3{
+			X: r.Float64()*4000 - 2000,
+			Y: r.Float64()*1000 - 500,
+			Z: r.Float64()*4000 - 2000,
+		}
+		radius := 10 + r.Float64()*40
+		if i%10 == 0 {
+			radius = 400
+		}
+		g.Insert(fmt.Sprintf("e%d", i), KindShip, pos, radius)
+	}
+	return g
+}
+
+func ids(entries []Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.ID
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestQueryRangeMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g := randomGrid(r, 300)
+
+	for i := 0; i < 50; i++ {
+		center := ship.Vector3{X: r.Float64()*4000 - 2000, Z: r.Float64()*4000 - 2000}
+		radius := r.Float64() * 800
+
+		var want []Entry
+		for _, e := range g.entries {
+			if e.Position.Sub(center).Length() <= radius+e.Radius {
+				want = append(want, e)
+			}
+		}
+
+		got := ids(g.QueryRange(center, radius, nil))
+		expected := ids(want)
+		if fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Fatalf("Query %d: expected %v, got %v", i, expected, got)
+		}
+	}
+}
+
+func TestNearestMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	g := randomGrid(r, 200)
+	even := func(e *Entry) bool { return e.ID[len(e.ID)-1]%2 == 0 }
+
+	for i := 0; i < 50; i++ {
+		center := ship.Vector3{X: r.Float64()*6000 - 3000, Y: 300, Z: r.Float64()*6000 - 3000}
+
+		bestDist := math.Inf(1)
+		for _, e := range g.entries {
+			if d := e.Position.Sub(center).Length(); even(&e) && d < bestDist {
+				bestDist = d
+			}
+		}
+
+		got, ok := g.Nearest(center, 0, even)
+		if !ok || math.Abs(got.Position.Sub(center).Length()-bestDist) > 1e-9 {
+			t.Fatalf("Query %d: expected distance %f, got %+v", i, bestDist, got)
+		}
+	}
+
+	if _, ok := g.Nearest(ship.Vector3{X: 1e6}, 100, nil); ok {
+		t.Error("Expected no entry within range of a far-away point")
+	}
+}
+
+func TestRaycast(t *testing.T) {
+	g := NewGrid(100)
+	g.Insert("near", KindObject, ship.Vector3{Z: 500}, 20)
+	g.Insert("far", KindObject, ship.Vector3{Z: 900}, 20)
+	g.Insert("big", KindShip, ship.Vector3{X: 300, Z: 700}, 250)
+	g.Insert("behind", KindShip, ship.Vector3{Z: -300}, 20)
+
+	e, dist, ok := g.Raycast(ship.Vector3{}, ship.Vector3{Z: 1}, 2000, nil)
+	if !ok || e.ID != "near" || math.Abs(dist-480) > 1e-9 {
+		t.Errorf("Expected to hit near at 480, got %s at %f", e.ID, dist)
+	}
+
+	objectsOnly := func(e *Entry) bool { return e.Kind == KindObject && e.ID != "near" }
+	e, _, ok = g.Raycast(ship.Vector3{}, ship.Vector3{Z: 1}, 2000, objectsOnly)
+	if !ok || e.ID != "far" {
+		t.Errorf("Expected filtered ray to hit far, got %s", e.ID)
+	}
+
+	if _, _, ok := g.Raycast(ship.Vector3{}, ship.Vector3{Z: 1}, 400, nil); ok {
+		t.Error("Expected no hit within 400 units")
+	}
+
+	e, _, ok = g.Raycast(ship.Vector3{X: 300, Z: -1000}, ship.Vector3{Z: 1}, 5000, nil)
+	if !ok || e.ID != "big" {
+		t.Errorf("Expected to hit the large entry spanning several cells, got %s", e.ID)
+	}
+}
+
+func TestPairsMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	g := randomGrid(r, 200)
+
+	want := make(map[string]bool)
+	for i := range g.entries {
+		for j := i + 1; j < len(g.entries); j++ {
+			a, b := g.entries[i], g.entries[j]
+			if a.Position.Sub(b.Position).Length() < a.Radius+b.Radius {
+				want[a.ID+"|"+b.ID] = true
+			}
+		}
+	}
+
+	got := make(map[string]bool)
+	g.Pairs(nil, func(a, b *Entry) {
+		key := a.ID + "|" + b.ID
+		if got[key] {
+			t.Fatalf("Pair %s reported twice", key)
+		}
+		got[key] = true
+	})
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d pairs, got %d", len(want), len(got))
+	}
+	for key := range want {
+		if !got[key] {
+			t.Errorf("Missing pair %s", key)
+		}
+	}
+}
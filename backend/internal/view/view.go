@@ -0,0 +1,370 @@
+package view
+
+import (
+	"celestial/internal/ship"
+	"celestial/internal/simulation"
+	"sort"
+)
+
+// Ship renders a ship in the schema sent to WebSocket clients. Recording,
+// replay and the mission API reuse it so every consumer sees the same shape.
+func Ship(sh *ship.Ship) map[string]interface{} {
+	return map[string]interface{}{
+		"id":       sh.ID,
+		"name":     sh.Name,
+		"class_id": sh.ClassID,
+		"faction":  sh.Faction,
+		"position": map[string]float64{
+			"x": sh.Position.X,
+			"y": sh.Position.Y,
+			"z": sh.Position.Z,
+		},
+		"velocity": map[string]float64{
+			"x": sh.Velocity.X,
+			"y": sh.Velocity.Y,
+			"z": sh.Velocity.Z,
+		},
+		"rotation": map[string]float64{
+			"w": sh.Rotation.W,
+			"x": sh.Rotation.X,
+			"y": sh.Rotation.Y,
+			"z": sh.Rotation.Z,
+		},
+		"helm": map[string]float64{
+			"throttle":        sh.Throttle,
+			"strafe_lateral":  sh.StrafeLateral,
+			"strafe_vertical": sh.StrafeVertical,
+		},
+		"flight":  Flight(sh),
+		"docking": Docking(sh),
+		"systems": Systems(sh),
+	}
+}
+
+func Docking(sh *ship.Ship) map[string]interface{} {
+	dock := sh.DockingState()
+	return map[string]interface{}{
+		"status":          dock.Status,
+		"host_id":         dock.HostID,
+		"port_id":         dock.PortID,
+		"distance":        dock.Distance,
+		"closing_speed":   dock.ClosingSpeed,
+		"alignment_error": dock.AlignmentError,
+		"in_corridor":     dock.InCorridor,
+		"clamp_time":      dock.Timer,
+	}
+}
+
+// Flight renders the flight computer and its progress.
+func Flight(sh *ship.Ship) map[string]interface{} {
+	flight := sh.FlightState()
+	return map[string]interface{}{
+		"mode":      flight.Mode,
+		"target_id": flight.TargetID,
+		"point": map[string]float64{
+			"x": flight.Point.X,
+			"y": flight.Point.Y,
+			"z": flight.Point.Z,
+		},
+		"heading":       sh.Heading(),
+		"distance":      flight.Status.Distance,
+		"eta":           flight.Status.ETA,
+		"heading_error": flight.Status.HeadingError,
+		"arrived":       flight.Status.Arrived,
+	}
+}
+
+// Triggers renders the trigger volumes for the GM and the relay map.
+func Triggers(triggers map[string]*simulation.Trigger) map[string]interface{} {
+	data := make(map[string]interface{}, len(triggers))
+	for id, t := range triggers {
+		inside := make([]string, 0, len(t.Inside))
+		for shipID := range t.Inside {
+			inside = append(inside, shipID)
+		}
+		sort.Strings(inside)
+		data[id] = map[string]interface{}{
+			"id":    id,
+			"kind":  t.Kind,
+			"shape": t.Shape,
+			"position": map[string]float64{
+				"x": t.Position.X,
+				"y": t.Position.Y,
+				"z": t.Position.Z,
+			},
+			"radius": t.Radius,
+			"extents": map[string]float64{
+				"x": t.Extents.X,
+				"y": t.Extents.Y,
+				"z": t.Extents.Z,
+			},
+			"object_id": t.ObjectID,
+			"ships":     t.Ships,
+			"factions":  t.Factions,
+			"dwell":     t.Dwell,
+			"repeat":    t.Repeat,
+			"inside":    inside,
+		}
+	}
+	return data
+}
+
+// Projectiles renders the shots and torpedoes in flight.
+func Projectiles(projectiles map[string]*simulation.Projectile) map[string]interface{} {
+	data := make(map[string]interface{}, len(projectiles))
+	for id, p := range projectiles {
+		data[id] = map[string]interface{}{
+			"id":        id,
+			"type":      p.Type,
+			"position":  vector(p.Position),
+			"velocity":  vector(p.Velocity),
+			"source_id": p.SourceID,
+			"target_id": p.TargetID,
+			"guided":    p.Guided,
+			"health":    p.Health,
+		}
+	}
+	return data
+}
+
+// Objects renders the world objects: asteroids, debris, stations,
+// waypoints and wrecks.
+func Objects(objects map[string]*simulation.Object) map[string]interface{} {
+	data := make(map[string]interface{}, len(objects))
+	for id, obj := range objects {
+		data[id] = map[string]interface{}{
+			"id":       id,
+			"type":     obj.Type,
+			"position": vector(obj.Position),
+			"velocity": vector(obj.Velocity),
+			"rotation": map[string]float64{
+				"w": obj.Rotation.W,
+				"x": obj.Rotation.X,
+				"y": obj.Rotation.Y,
+				"z": obj.Rotation.Z,
+			},
+			"radius": obj.Radius,
+			"mass":   obj.Mass,
+			"data":   obj.Data,
+		}
+	}
+	return data
+}
+
+func vector(v ship.Vector3) map[string]float64 {
+	return map[string]float64{"x": v.X, "y": v.Y, "z": v.Z}
+}
+
+func Ships(ships map[string]*ship.Ship) map[string]interface{} {
+	shipData := make(map[string]interface{}, len(ships))
+	for id, sh := range ships {
+		shipData[id] = Ship(sh)
+	}
+	return shipData
+}
+
+func Systems(sh *ship.Ship) map[string]interface{} {
+	engines := make(map[string]interface{})
+	for id, eng := range sh.Engines {
+		engines[id] = map[string]interface{}{
+			"health":     eng.Health,
+			"enabled":    eng.Enabled,
+			"power":      eng.PowerLevel,
+			"heat":       eng.Heat,
+			"overheated": eng.Overheated,
+			"on_fire":    eng.OnFire,
+		}
+	}
+
+	weapons := make(map[string]interface{})
+	for id, wpn := range sh.Weapons {
+		weapons[id] = map[string]interface{}{
+			"health":     wpn.Health,
+			"enabled":    wpn.Enabled,
+			"cooldown":   wpn.Cooldown,
+			"armed":      wpn.Armed,
+			"loaded":     wpn.Loaded,
+			"locked":     wpn.Locked,
+			"ammo":       wpn.AmmoCount,
+			"power":      wpn.PowerLevel,
+			"heat":       wpn.Heat,
+			"overheated": wpn.Overheated,
+			"on_fire":    wpn.OnFire,
+		}
+	}
+
+	shields := make(map[string]interface{})
+	for id, em := range sh.Shields.Emitters {
+		shields[id] = map[string]interface{}{
+			"strength": em.Strength,
+			"max":      em.MaxStrength,
+			"health":   em.Health,
+			"facing":   em.Facing,
+			"on_fire":  em.OnFire,
+		}
+	}
+
+	subsystems := make(map[string]interface{})
+	for id, sub := range sh.Subsystems {
+		subsystems[id] = map[string]interface{}{
+			"type":       sub.Type,
+			"health":     sub.Health,
+			"enabled":    sub.Enabled,
+			"power":      sub.PowerLevel,
+			"heat":       sub.Heat,
+			"overheated": sub.Overheated,
+			"on_fire":    sub.OnFire,
+		}
+	}
+
+	hull := make(map[string]interface{})
+	for id, sec := range sh.Hull.Sections {
+		hull[id] = map[string]interface{}{
+			"armor":    sec.Armor,
+			"health":   sec.Health,
+			"breached": sec.Breached,
+			"on_fire":  sec.OnFire,
+		}
+	}
+
+	crew := make(map[string]int)
+	for _, member := range sh.Crew {
+		if member.Health > 0 {
+			crew[member.Compartment]++
+		}
+	}
+
+	compartments := make(map[string]interface{})
+	for id, comp := range sh.LifeSupport.Compartments {
+		compartments[id] = map[string]interface{}{
+			"section":     comp.Section,
+			"volume":      comp.Volume,
+			"adjacent":    append([]string(nil), comp.Adjacent...),
+			"pressure":    comp.Pressure,
+			"oxygen":      comp.Oxygen,
+			"co2":         comp.CarbonDioxide,
+			"temperature": comp.Temperature,
+			"breached":    comp.Breached,
+			"vented":      comp.Vented,
+			"on_fire":     comp.OnFire,
+			"fire":        comp.Fire,
+			"fire_level":  ship.FireLevel(comp.Fire),
+			"fuel":        comp.Fuel,
+			"suppression": comp.Suppression,
+			"suppressant": comp.Suppressant,
+			"crew":        crew[id],
+		}
+	}
+
+	doors := make(map[string]interface{})
+	for id, door := range sh.LifeSupport.Doors {
+		doors[id] = map[string]interface{}{
+			"connects": []string{door.A, door.B},
+			"open":     door.Open,
+		}
+	}
+
+	charges := make(map[string]int, len(sh.Suppression.Charges))
+	for agent, n := range sh.Suppression.Charges {
+		charges[agent] = n
+	}
+
+	roster := make(map[string]interface{}, len(sh.Crew))
+	staffing := make(map[string]float64)
+	for id, member := range sh.Crew {
+		roster[id] = map[string]interface{}{
+			"name":        member.Name,
+			"role":        member.Role,
+			"health":      member.Health,
+			"status":      member.Status,
+			"post":        member.Post,
+			"compartment": member.Compartment,
+		}
+		if _, ok := staffing[member.Role]; !ok {
+			staffing[member.Role] = sh.Effectiveness(member.Role)
+		}
+	}
+
+	teams := make(map[string]interface{}, len(sh.Teams))
+	for id, team := range sh.Teams {
+		teams[id] = map[string]interface{}{
+			"compartment": team.Compartment,
+			"target":      team.Target,
+			"system_type": team.SystemType,
+			"system_id":   team.SystemID,
+			"path":        append([]string(nil), team.Path...),
+			"status":      team.Status,
+			"health":      team.Health,
+			"eta":         team.ETA,
+		}
+	}
+
+	breakers := make(map[string]interface{})
+	for id, brk := range sh.Power.Breakers {
+		breakers[id] = map[string]interface{}{
+			"systems": brk.Systems,
+			"rating":  brk.Rating,
+			"enabled": brk.Enabled,
+			"tripped": brk.Tripped,
+			"load":    brk.Load,
+		}
+	}
+
+	batteries := make(map[string]interface{})
+	for id, bat := range sh.Power.Batteries {
+		batteries[id] = map[string]interface{}{
+			"capacity": bat.Capacity,
+			"charge":   bat.Charge,
+		}
+	}
+
+	allocation := make(map[string]float64, len(sh.Power.Allocation))
+	for group, level := range sh.Power.Allocation {
+		allocation[group] = level
+	}
+
+	coolant := make(map[string]int, len(sh.Coolant.Assigned))
+	for group, units := range sh.Coolant.Assigned {
+		coolant[group] = units
+	}
+
+	return map[string]interface{}{
+		"engines":    engines,
+		"weapons":    weapons,
+		"shields":    shields,
+		"subsystems": subsystems,
+		"hull":       hull,
+		"life_support": map[string]interface{}{
+			"compartments": compartments,
+			"doors":        doors,
+		},
+		"suppression":  charges,
+		"damage_teams": teams,
+		"crew":         roster,
+		"staffing":     staffing,
+		"shield_status": map[string]interface{}{
+			"raised":     sh.Shields.Enabled,
+			"frequency":  sh.Shields.Frequency,
+			"heat":       sh.Shields.Heat,
+			"overheated": sh.Shields.Overheated,
+		},
+		"coolant": map[string]interface{}{
+			"capacity":  sh.Coolant.Capacity,
+			"available": sh.Coolant.Available(),
+			"assigned":  coolant,
+		},
+		"power": map[string]interface{}{
+			"current":     sh.Power.CurrentCapacity,
+			"max":         sh.Power.MaxCapacity,
+			"generation":  sh.Power.Generation,
+			"consumption": sh.Power.Consumption,
+			"demand":      sh.Power.Demand,
+			"supply":      sh.Power.Supply,
+			"allocation":  allocation,
+			"preset":      sh.Power.Preset,
+			"shields":     sh.Shields.PowerLevel,
+			"breakers":    breakers,
+			"batteries":   batteries,
+		},
+	}
+}
@@ -31,11 +31,11 @@ function on_event(event_name, params)
     if event_name == "waypoint_reached" then
         local waypoint = params.waypoint
         
-        if waypoint == "alpha" then
+        if waypoint == "waypoint_alpha" then
             log("Waypoint Alpha reached")
             complete_objective("patrol_1")
             spawn_enemy_patrol()
-        elseif waypoint == "beta" then
+        elseif waypoint == "waypoint_beta" then
             log("Waypoint Beta reached")
             complete_objective("patrol_2")
             spawn_heavy_enemy()
@@ -19,6 +19,8 @@ function on_start()
     spawn_ship(player_ship, "player_cruiser", "USS Celestial", true, {x=0, y=0, z=0})
     
     spawn_ship(merchant_ship, "enemy_frigate", "Merchant Vessel Aurora", false, {x=10000, y=500, z=-5000})
+    set_faction(merchant_ship, "civilian")
+    add_trigger("merchant_location", {position={x=10000, y=500, z=-5000}, radius=2000, ships={player_ship}})
     
     set_objective("respond", "Respond to distress call")
     set_objective("defend", "Defend the merchant vessel")
@@ -108,6 +110,7 @@ function start_escort()
     escort_active = true
     
     spawn_object("safe_zone", "waypoint", {x=-8000, y=0, z=3000})
+    add_trigger("safe_zone", {object="safe_zone", radius=1000, ships={merchant_ship}})
     log("Escort merchant vessel to safe zone coordinates")
 end
 